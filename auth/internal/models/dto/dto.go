@@ -1,12 +0,0 @@
-package dto
-
-type UserRegistrationCredentials struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
-	Username string `json:"username" binding:"required,min=5"`
-}
-
-type UserLoginCredentials struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
-}
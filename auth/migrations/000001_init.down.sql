@@ -1,4 +0,0 @@
-DROP TABLE customers;
-
-DROP INDEX IF EXISTS seller_username_hash_idx;
-DROP TABLE sellers;
\ No newline at end of file
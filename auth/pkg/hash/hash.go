@@ -1,20 +0,0 @@
-package hash
-
-import "golang.org/x/crypto/bcrypt"
-
-func ComparePasswords(hashedPwd string, plainPwd string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPwd), []byte(plainPwd))
-	if err != nil {
-		return false
-	}
-	return true
-}
-
-func HashPassword(password string) (string, error) {
-	passhash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-
-	return string(passhash), nil
-}
@@ -0,0 +1,298 @@
+// identitymigrate переносит пользователей старого сервиса auth (uuid, отдельные аккаунты покупателя
+// и продавца) в общую таблицу users этого сервиса (целочисленные id, uuid как публичный идентификатор).
+//
+// Аккаунты сопоставляются по почте без учета регистра: совпавшие объединяются в одного пользователя,
+// роль продавца сохраняется, пустые поля профиля заполняются из старого аккаунта, пароль остается
+// у аккаунта, который был перенесен (или существовал) первым. Продавцы переносятся раньше покупателей,
+// поэтому uuid продавца становится uuid пользователя и seller_id в товарах остается прежним.
+// Все старые uuid записываются в legacy_user_ids, повторный запуск пропускает уже перенесенных.
+// Если логин занят пользователем с другой почтой, к логину добавляется суффикс _legacy.
+// Анкеты перенесенных продавцов создаются одобренными: проверку они уже прошли в старом сервисе.
+// С пользователем этого сервиса с неподтвержденной почтой старый аккаунт не объединяется (иначе
+// чужая регистрация на почту продавца получила бы его аккаунт): такие конфликты только печатаются,
+// их нужно разобрать вручную и запустить перенос повторно.
+//
+// Схема целевой базы обновляется так же, как при старте сервиса (CONFIG_PATH, каталог migrations).
+//
+//	LEGACY_AUTH_DSN  строка подключения к базе сервиса auth
+//	-dry-run         выполнить перенос в транзакции и откатить ее, напечатав только итог
+package main
+
+import (
+	"auth_service/internal/config"
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+const usernameSuffix = "_legacy"
+
+type legacyUser struct {
+	ID        string       `db:"id"`
+	FullName  string       `db:"fullname"`
+	Username  string       `db:"username"`
+	PassHash  string       `db:"passhash"`
+	Email     string       `db:"email"`
+	Phone     string       `db:"phone"`
+	City      string       `db:"city"`
+	Address   string       `db:"address"`
+	Role      string       `db:"role"`
+	CreatedAt sql.NullTime `db:"created_at"`
+}
+
+type stats struct {
+	total, skipped, created, merged, renamed, conflicts int
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "roll back the migration after reporting what would change")
+	flag.Parse()
+
+	legacyDSN := os.Getenv("LEGACY_AUTH_DSN")
+	if legacyDSN == "" {
+		slog.Error("LEGACY_AUTH_DSN variable is not set")
+		os.Exit(1)
+	}
+
+	if err := run(config.New(), legacyDSN, *dryRun); err != nil {
+		slog.Error("identity migration failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
+
+func run(cfg *config.Config, legacyDSN string, dryRun bool) (err error) {
+	legacyDB, err := sqlx.Connect("postgres", legacyDSN)
+	if err != nil {
+		return fmt.Errorf("unable to connect to legacy db: %w", err)
+	}
+	defer legacyDB.Close()
+
+	var legacyUsers []legacyUser
+	err = legacyDB.Select(&legacyUsers, `
+    SELECT id, fullname, username, passhash, email, phone, city, address, role, created_at
+    FROM users
+    ORDER BY role = 'seller' DESC, created_at, id`)
+	if err != nil {
+		return fmt.Errorf("unable to read legacy users: %w", err)
+	}
+
+	stor, err := storage.New(cfg, zap.NewNop())
+	if err != nil {
+		return err
+	}
+	defer stor.DB.Close()
+
+	tx, err := stor.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil || dryRun {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	var st stats
+	for _, legacy := range legacyUsers {
+		st.total++
+		if err := migrateUser(tx, legacy, &st); err != nil {
+			return fmt.Errorf("legacy user %s: %w", legacy.ID, err)
+		}
+	}
+
+	slog.Info("identity migration finished",
+		slog.Bool("dry_run", dryRun),
+		slog.Int("total", st.total),
+		slog.Int("skipped", st.skipped),
+		slog.Int("created", st.created),
+		slog.Int("merged", st.merged),
+		slog.Int("renamed", st.renamed),
+		slog.Int("conflicts", st.conflicts),
+	)
+
+	return nil
+}
+
+func migrateUser(tx *sqlx.Tx, legacy legacyUser, st *stats) error {
+	var migrated bool
+	err := tx.Get(&migrated, "SELECT EXISTS(SELECT 1 FROM legacy_user_ids WHERE legacy_id = $1)", legacy.ID)
+	if err != nil {
+		return err
+	}
+	if migrated {
+		st.skipped++
+		return nil
+	}
+
+	role := models.RoleCustomer
+	if legacy.Role == models.RoleSeller {
+		role = models.RoleSeller
+	}
+
+	var user models.User
+	err = tx.Get(&user, "SELECT * FROM users WHERE lower(email) = lower($1) ORDER BY id LIMIT 1", legacy.Email)
+	switch {
+	case err == nil:
+		trusted, err := canMerge(tx, user)
+		if err != nil {
+			return err
+		}
+		if !trusted {
+			st.conflicts++
+			slog.Warn("legacy account matches a user with unverified email",
+				slog.String("legacy_id", legacy.ID),
+				slog.Int64("user_id", user.ID),
+			)
+			return nil
+		}
+
+		if err := mergeUser(tx, user, legacy, role); err != nil {
+			return err
+		}
+		st.merged++
+		slog.Info("merged legacy account",
+			slog.String("legacy_id", legacy.ID),
+			slog.String("legacy_role", legacy.Role),
+			slog.Int64("user_id", user.ID),
+		)
+	case errors.Is(err, sql.ErrNoRows):
+		user, err = createUser(tx, legacy, role, st)
+		if err != nil {
+			return err
+		}
+		st.created++
+	default:
+		return err
+	}
+
+	// продавцы старого сервиса уже торгуют, поэтому их анкеты сразу считаются одобренными
+	if role == models.RoleSeller {
+		_, err = tx.Exec(`
+    INSERT INTO seller_profiles (user_id, status, reviewed_at)
+    VALUES ($1, $2, CURRENT_TIMESTAMP)
+    ON CONFLICT (user_id) DO NOTHING`,
+			user.ID, models.SellerStatusApproved,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO legacy_user_ids (legacy_id, user_id, legacy_role) VALUES ($1, $2, $3)",
+		legacy.ID, user.ID, legacy.Role,
+	)
+
+	return err
+}
+
+// canMerge - почта пользователя подтверждена или он сам перенесен из сервиса auth
+func canMerge(tx *sqlx.Tx, user models.User) (bool, error) {
+	if user.EmailVerified {
+		return true, nil
+	}
+
+	var fromLegacy bool
+	err := tx.Get(&fromLegacy, "SELECT EXISTS(SELECT 1 FROM legacy_user_ids WHERE user_id = $1)", user.ID)
+
+	return fromLegacy, err
+}
+
+// mergeUser дополняет существующего пользователя данными старого аккаунта. Первый перенесенный
+// старый uuid становится uuid пользователя, остальные находятся через legacy_user_ids.
+func mergeUser(tx *sqlx.Tx, user models.User, legacy legacyUser, role string) error {
+	if role == models.RoleSeller && user.Role == models.RoleCustomer {
+		user.Role = models.RoleSeller
+	}
+
+	var hasLegacy bool
+	err := tx.Get(&hasLegacy, "SELECT EXISTS(SELECT 1 FROM legacy_user_ids WHERE user_id = $1)", user.ID)
+	if err != nil {
+		return err
+	}
+	if !hasLegacy {
+		user.UUID = legacy.ID
+	}
+
+	_, err = tx.Exec(`
+    UPDATE users SET
+        uuid = $2,
+        role = $3,
+        full_name = COALESCE(NULLIF(full_name, ''), $4),
+        phone = COALESCE(NULLIF(phone, ''), $5),
+        address = COALESCE(NULLIF(address, ''), $6),
+        city = COALESCE(NULLIF(city, ''), $7),
+        updated_at = CURRENT_TIMESTAMP
+    WHERE id = $1`,
+		user.ID, user.UUID, user.Role, legacy.FullName, legacy.Phone, legacy.Address, legacy.City,
+	)
+
+	return err
+}
+
+func createUser(tx *sqlx.Tx, legacy legacyUser, role string, st *stats) (models.User, error) {
+	username, err := freeUsername(tx, legacy.Username)
+	if err != nil {
+		return models.User{}, err
+	}
+	if username != legacy.Username {
+		st.renamed++
+		slog.Warn("legacy username is taken",
+			slog.String("legacy_id", legacy.ID),
+			slog.String("username", legacy.Username),
+			slog.String("new_username", username),
+		)
+	}
+
+	createdAt := time.Now()
+	if legacy.CreatedAt.Valid {
+		createdAt = legacy.CreatedAt.Time
+	}
+
+	var user models.User
+	err = tx.Get(&user, `
+    INSERT INTO users (uuid, email, username, passhash, role, full_name, phone, address, city, created_acc)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    RETURNING *`,
+		legacy.ID, legacy.Email, username, legacy.PassHash, role,
+		legacy.FullName, legacy.Phone, legacy.Address, legacy.City, createdAt,
+	)
+
+	return user, err
+}
+
+func freeUsername(tx *sqlx.Tx, username string) (string, error) {
+	candidate := username
+	for i := 1; ; i++ {
+		var taken bool
+		err := tx.Get(&taken, "SELECT EXISTS(SELECT 1 FROM users WHERE lower(username) = lower($1))", candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+
+		candidate = username + usernameSuffix
+		if i > 1 {
+			candidate += fmt.Sprint(i)
+		}
+	}
+}
@@ -2,13 +2,22 @@ package main
 
 import (
 	"auth_service/internal/config"
+	"auth_service/internal/jwt"
+	"auth_service/internal/loginguard"
+	"auth_service/internal/oidc"
+	"auth_service/internal/privacy"
 	"auth_service/internal/repositiry/storage"
+	"auth_service/internal/social"
 	"auth_service/internal/transport/grpc"
+	"auth_service/internal/transport/rest"
 	"auth_service/pkg/logger"
-	"fmt"
+	"auth_service/pkg/metrics"
+	"auth_service/pkg/storage/inmem"
+	"context"
 	"log/slog"
 	"os"
 	"os/signal"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -20,6 +29,11 @@ import (
 func main() {
 	cfg := config.New()
 
+	if err := jwt.LoadSecrets(); err != nil {
+		slog.Error("failed to load token secrets", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	env := os.Getenv("ENV")
 	if env == "" {
 		slog.Error("ENV variable is not set")
@@ -27,7 +41,8 @@ func main() {
 	}
 	mainLogger, err := logger.InitLogger(env)
 	if err != nil {
-		slog.Error("failed to initialize logger", err)
+		slog.Error("failed to initialize logger", slog.String("error", err.Error()))
+		os.Exit(1)
 	}
 
 	stor, err := storage.New(cfg, mainLogger)
@@ -35,11 +50,35 @@ func main() {
 		mainLogger.Fatal("failed to create storage", zap.Error(err))
 	}
 
-	fmt.Println(cfg)
+	go metrics.MustServe(cfg.ServerCfg.MetricsPort, mainLogger)
+
+	refreshStor := inmem.NewRedisStorage()
+	sessions := inmem.NewSessionStorage()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	privacyOrchestrator := privacy.New(cfg.PrivacyCfg, stor, privacy.NewAuthParticipant(stor, refreshStor), mainLogger)
+	go privacyOrchestrator.Run(ctx)
 
-	grpcServer := grpc.New(cfg.ServerCfg, stor, mainLogger)
+	// один guard на grpc и oidc: лимиты попыток входа общие для обоих способов входа
+	guard := loginguard.New(cfg.ServerCfg.LoginGuard, inmem.NewLoginAttemptsStorage(), metrics.NewLoginMetrics(), mainLogger)
+
+	socialLogin := social.New(cfg.SocialCfg, inmem.NewSocialStateStorage(), mainLogger)
+
+	grpcServer := grpc.New(cfg.ServerCfg, stor, refreshStor, sessions, guard, privacyOrchestrator, socialLogin, mainLogger)
 	go grpcServer.MustStart()
 
+	// http api покупателей и продавцов, раньше его обслуживал отдельный сервис auth
+	httpServer := rest.New(cfg.HTTPCfg, cfg.ServerCfg.Verification, cfg.ServerCfg.TwoFactor, stor, sessions, guard, mainLogger)
+	go httpServer.MustStart()
+
+	var oidcProvider *oidc.Provider
+	if cfg.OIDCCfg.Enabled {
+		oidcProvider = oidc.New(cfg.OIDCCfg, cfg.ServerCfg.Verification, stor, guard, mainLogger)
+		go oidcProvider.MustStart()
+	}
+
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, os.Interrupt)
 
@@ -48,4 +87,11 @@ func main() {
 	mainLogger.Error("shutting down server", zap.String("signal", sign.String()))
 	grpcServer.GracefulStop()
 
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	httpServer.Shutdown(shutdownCtx)
+	if oidcProvider != nil {
+		oidcProvider.Shutdown(shutdownCtx)
+	}
+
 }
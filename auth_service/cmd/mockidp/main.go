@@ -0,0 +1,256 @@
+// mockidp - минимальный OpenID Connect провайдер для локальной проверки входа через внешних провайдеров.
+// Пароля нет: на странице входа можно указать любого пользователя (sub, почту, подтверждена ли она).
+// Ключ подписи и выданные коды живут только в памяти процесса.
+//
+//	MOCK_IDP_ADDR          адрес http сервера, по умолчанию :9000
+//	MOCK_IDP_ISSUER        issuer, по умолчанию http://localhost:9000
+//	MOCK_IDP_CLIENT_ID     client_id магазина, по умолчанию online-store
+//	MOCK_IDP_CLIENT_SECRET client_secret магазина, по умолчанию mock-secret
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	keyID   = "mock-key"
+	codeTTL = time.Minute
+)
+
+var authorizePage = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Mock IdP</title></head>
+<body>
+<h1>Mock identity provider</h1>
+<form method="POST" action="/authorize">
+  {{range $key, $values := .}}{{range $values}}<input type="hidden" name="{{$key}}" value="{{.}}">{{end}}{{end}}
+  <p><label>Subject <input name="sub" value="mock-user-1" required></label></p>
+  <p><label>Email <input name="email" value="mock.user@example.com"></label></p>
+  <p><label><input type="checkbox" name="email_verified" value="true" checked> Email verified</label></p>
+  <p><label>Username <input name="preferred_username" value="mock_user"></label></p>
+  <p><label>Name <input name="name" value="Mock User"></label></p>
+  <p><button type="submit">Sign in</button></p>
+</form>
+</body>
+</html>
+`))
+
+type authCode struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	claims        jwt.MapClaims
+	expiresAt     time.Time
+}
+
+type mockIdP struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authCode
+}
+
+func main() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		slog.Error("failed to generate key", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	idp := &mockIdP{
+		issuer:       getenv("MOCK_IDP_ISSUER", "http://localhost:9000"),
+		clientID:     getenv("MOCK_IDP_CLIENT_ID", "online-store"),
+		clientSecret: getenv("MOCK_IDP_CLIENT_SECRET", "mock-secret"),
+		key:          key,
+		codes:        make(map[string]authCode),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("GET /jwks", idp.jwks)
+	mux.HandleFunc("GET /authorize", idp.authorizeForm)
+	mux.HandleFunc("POST /authorize", idp.authorize)
+	mux.HandleFunc("POST /token", idp.token)
+
+	addr := getenv("MOCK_IDP_ADDR", ":9000")
+	slog.Info("mock idp start", slog.String("addr", addr), slog.String("issuer", idp.issuer))
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		slog.Error("mock idp stopped", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
+
+func (m *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                m.issuer,
+		"authorization_endpoint":                m.issuer + "/authorize",
+		"token_endpoint":                        m.issuer + "/token",
+		"jwks_uri":                              m.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (m *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (m *mockIdP) authorizeForm(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("client_id") != m.clientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = authorizePage.Execute(w, r.URL.Query())
+}
+
+func (m *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("client_id") != m.clientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+
+	target, err := url.Parse(r.PostFormValue("redirect_uri"))
+	if err != nil || !target.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+
+	m.mu.Lock()
+	m.codes[code] = authCode{
+		clientID:      m.clientID,
+		redirectURI:   r.PostFormValue("redirect_uri"),
+		codeChallenge: r.PostFormValue("code_challenge"),
+		nonce:         r.PostFormValue("nonce"),
+		claims: jwt.MapClaims{
+			"sub":                r.PostFormValue("sub"),
+			"email":              r.PostFormValue("email"),
+			"email_verified":     r.PostFormValue("email_verified") == "true",
+			"preferred_username": r.PostFormValue("preferred_username"),
+			"name":               r.PostFormValue("name"),
+		},
+		expiresAt: time.Now().Add(codeTTL),
+	}
+	m.mu.Unlock()
+
+	query := target.Query()
+	query.Set("code", code)
+	query.Set("state", r.PostFormValue("state"))
+	target.RawQuery = query.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func (m *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientID != m.clientID || subtle.ConstantTimeCompare([]byte(secret), []byte(m.clientSecret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	m.mu.Lock()
+	code, ok := m.codes[r.PostFormValue("code")]
+	delete(m.codes, r.PostFormValue("code"))
+	m.mu.Unlock()
+
+	if !ok || time.Now().After(code.expiresAt) || code.redirectURI != r.PostFormValue("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	if code.codeChallenge != "" {
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != code.codeChallenge {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "pkce verification failed"})
+			return
+		}
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": m.issuer,
+		"aud": code.clientID,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	if code.nonce != "" {
+		claims["nonce"] = code.nonce
+	}
+	for key, value := range code.claims {
+		claims[key] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	buf := make([]byte, 24)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -11,12 +11,20 @@ import (
 type Config struct {
 	ServerCfg  ServerConfig  `yaml:"server"`
 	StorageCfg StorageConfig `yaml:"storage"`
+	PrivacyCfg PrivacyConfig `yaml:"privacy"`
+	OIDCCfg    OIDCConfig    `yaml:"oidc"`
+	SocialCfg  SocialConfig  `yaml:"social"`
+	HTTPCfg    HTTPConfig    `yaml:"http"`
 }
 
 type ServerConfig struct {
-	Env        string        `yaml:"env" env-default:"dev"`
-	TokenTTL   time.Duration `yaml:"token_ttl"`
-	GRPCConfig `yaml:"grpc"`
+	Env          string             `yaml:"env" env-default:"dev"`
+	TokenTTL     time.Duration      `yaml:"token_ttl"`
+	MetricsPort  string             `yaml:"metrics_port" env-default:"9100"`
+	LoginGuard   LoginGuardConfig   `yaml:"login_guard"`
+	Verification VerificationConfig `yaml:"verification"`
+	TwoFactor    TwoFactorConfig    `yaml:"two_factor"`
+	GRPCConfig   `yaml:"grpc"`
 }
 
 type GRPCConfig struct {
@@ -24,6 +32,91 @@ type GRPCConfig struct {
 	GRPCTimeout time.Duration `yaml:"grpc_timeout"`
 }
 
+// LoginGuardConfig задает ограничения на попытки входа.
+// Окна скользящие: UserMaxAttempts и IPMaxAttempts ограничивают все попытки за последние Window,
+// FreeAttempts и LockoutThreshold считают только неудачные.
+type LoginGuardConfig struct {
+	Window           time.Duration `yaml:"window" env-default:"15m"`
+	UserMaxAttempts  int64         `yaml:"user_max_attempts" env-default:"10"`
+	IPMaxAttempts    int64         `yaml:"ip_max_attempts" env-default:"50"`
+	FreeAttempts     int64         `yaml:"free_attempts" env-default:"3"`
+	BaseDelay        time.Duration `yaml:"base_delay" env-default:"1s"`
+	MaxDelay         time.Duration `yaml:"max_delay" env-default:"1m"`
+	LockoutThreshold int64         `yaml:"lockout_threshold" env-default:"5"`
+	LockoutDuration  time.Duration `yaml:"lockout_duration" env-default:"30m"`
+}
+
+// VerificationConfig управляет подтверждением почты и сбросом пароля.
+// Письма отправляет notification_service, получая события из канала EventsChannel.
+type VerificationConfig struct {
+	RequireVerifiedEmail bool          `yaml:"require_verified_email" env-default:"false"`
+	EmailTokenTTL        time.Duration `yaml:"email_token_ttl" env-default:"24h"`
+	ResetTokenTTL        time.Duration `yaml:"reset_token_ttl" env-default:"1h"`
+	EventsChannel        string        `yaml:"events_channel" env-default:"auth_events"`
+}
+
+// TwoFactorConfig настраивает TOTP. При EnforceForAdmins администратор без включенной 2FA
+// не получает прав администратора, пока не подключит ее.
+type TwoFactorConfig struct {
+	Issuer           string        `yaml:"issuer" env-default:"Online Store"`
+	ChallengeTTL     time.Duration `yaml:"challenge_ttl" env-default:"5m"`
+	RecoveryCodes    int           `yaml:"recovery_codes" env-default:"10"`
+	EnforceForAdmins bool          `yaml:"enforce_for_admins" env-default:"false"`
+}
+
+// PrivacyConfig - сервисы, которые участвуют в выгрузке и удалении данных пользователя.
+// ExportRetention - сколько хранится готовая выгрузка, потом ее данные удаляются.
+type PrivacyConfig struct {
+	PollInterval    time.Duration       `yaml:"poll_interval" env-default:"10s"`
+	MaxAttempts     int                 `yaml:"max_attempts" env-default:"5"`
+	RequestTimeout  time.Duration       `yaml:"request_timeout" env-default:"30s"`
+	ExportRetention time.Duration       `yaml:"export_retention" env-default:"168h"`
+	Participants    []ParticipantConfig `yaml:"participants"`
+}
+
+type ParticipantConfig struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// OIDCConfig включает режим OpenID Connect провайдера: партнерские приложения входят
+// через authorization code + PKCE. Issuer - внешний адрес http сервера провайдера,
+// SigningKeyPath - RSA ключ в PEM для подписи id_token, без него ключ создается при старте.
+type OIDCConfig struct {
+	Enabled        bool          `yaml:"enabled" env-default:"false"`
+	HTTPPort       string        `yaml:"http_port" env-default:"8088"`
+	Issuer         string        `yaml:"issuer" env-default:"http://localhost:8088"`
+	CodeTTL        time.Duration `yaml:"code_ttl" env-default:"1m"`
+	IDTokenTTL     time.Duration `yaml:"id_token_ttl" env-default:"1h"`
+	SigningKeyPath string        `yaml:"signing_key_path" env:"OIDC_SIGNING_KEY_PATH"`
+}
+
+// SocialConfig - внешние OIDC провайдеры для входа (social login). Аккаунт провайдера привязывается
+// к существующему пользователю по подтвержденной почте, иначе создается новый пользователь.
+type SocialConfig struct {
+	StateTTL  time.Duration          `yaml:"state_ttl" env-default:"10m"`
+	Providers []SocialProviderConfig `yaml:"providers"`
+}
+
+// SocialProviderConfig описывает одного провайдера. Если client_secret не задан в файле,
+// он берется из переменной окружения SOCIAL_<NAME>_CLIENT_SECRET.
+type SocialProviderConfig struct {
+	Name         string   `yaml:"name"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+// HTTPConfig - http api входа покупателей и продавцов (бывший сервис auth).
+// Refresh токен отдается в httpOnly cookie на домене Domain.
+type HTTPConfig struct {
+	Port         string `yaml:"port" env-default:"8082"`
+	Domain       string `yaml:"domain" env-default:"localhost"`
+	SecureCookie bool   `yaml:"secure_cookie" env-default:"false"`
+}
+
 type StorageConfig struct {
 	Host     string `yaml:"host"`
 	Port     string `yaml:"port"`
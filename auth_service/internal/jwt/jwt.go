@@ -2,30 +2,96 @@ package jwt
 
 import (
 	"auth_service/internal/models"
+	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"log/slog"
 	"os"
 	"time"
 )
 
-func CreateAccessToken(user *models.User) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
+const challengePurpose = "2fa_challenge"
+
+var ErrInvalidToken = errors.New("invalid token")
 
-	accessTTLString := os.Getenv("ACCESS_TOKEN_TTL")
-	accessTTL, err := time.ParseDuration(accessTTLString)
+// ключи подписи токенов, загружаются один раз в LoadSecrets
+var (
+	accessSecret    string
+	refreshSecret   string
+	challengeSecret string
+)
+
+// LoadSecrets читает ключи подписи из окружения. Вызывается при старте сервиса: с пустым ключом
+// токены подписывались бы пустой строкой и принимались бы от кого угодно.
+func LoadSecrets() error {
+	secrets := []struct {
+		env string
+		dst *string
+	}{
+		{"ACCESS_TOKEN_SECRET", &accessSecret},
+		{"REFRESH_TOKEN_SECRET", &refreshSecret},
+		{"CHALLENGE_TOKEN_SECRET", &challengeSecret},
+	}
+
+	for _, secret := range secrets {
+		value := os.Getenv(secret.env)
+		if value == "" {
+			return fmt.Errorf("%s is not set", secret.env)
+		}
+		*secret.dst = value
+	}
+
+	return nil
+}
+
+// AccessClaims - содержимое access токена. Другие сервисы (delivery_service) читают из него user_id,
+// поэтому имена полей менять нельзя.
+type AccessClaims struct {
+	UserID   int64  `json:"user_id"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	UUID     string `json:"uuid"`
+	Purpose  string `json:"purpose,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// RefreshClaims - содержимое refresh токена. SessionID есть только у токенов http сессий,
+// ID (jti) у них меняется при каждом обновлении.
+type RefreshClaims struct {
+	UserID    int64  `json:"user_id"`
+	Email     string `json:"email"`
+	Username  string `json:"username"`
+	SessionID string `json:"sid,omitempty"`
+	Purpose   string `json:"purpose,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type ChallengeClaims struct {
+	UserID  int64  `json:"user_id"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+func CreateAccessToken(user *models.User) (string, error) {
+	accessTTL, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL"))
 	if err != nil {
 		slog.Warn("error parsing access ttl")
 		return "", err
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	claims["user_id"] = user.ID
-	claims["exp"] = time.Now().Add(accessTTL).Unix()
-	claims["email"] = user.Email
-	claims["username"] = user.Username
+	claims := AccessClaims{
+		UserID:   user.ID,
+		Email:    user.Email,
+		Username: user.Username,
+		Role:     user.Role,
+		UUID:     user.UUID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTTL)),
+		},
+	}
 
-	secretKey := os.Getenv("ACCESS_TOKEN_SECRET")
-	tokenString, err := token.SignedString([]byte(secretKey))
+	tokenString, err := sign(claims, accessSecret)
 	if err != nil {
 		slog.Warn("error creating token")
 		return "", err
@@ -36,46 +102,143 @@ func CreateAccessToken(user *models.User) (string, error) {
 }
 
 func CreateRefreshToken(user *models.User) (string, error) {
-	refreshTTLString := os.Getenv("REFRESH_TOKEN_TTL")
-	refreshTTL, err := time.ParseDuration(refreshTTLString)
+	return CreateSessionToken(user, "", "")
+}
+
+// CreateSessionToken создает refresh токен http сессии sessionId. tokenId - jti, по которому
+// хранилище сессий отличает текущий токен сессии от уже использованных.
+func CreateSessionToken(user *models.User, sessionId, tokenId string) (string, error) {
+	refreshTTL, err := RefreshTTL()
 	if err != nil {
 		slog.Warn("error parsing refresh ttl")
 		return "", err
 	}
 
-	claims := jwt.MapClaims{
-		"user_id":  user.ID,
-		"exp":      time.Now().Add(refreshTTL).Unix(),
-		"email":    user.Email,
-		"username": user.Username,
+	claims := RefreshClaims{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Username:  user.Username,
+		SessionID: sessionId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(refreshTTL)),
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return sign(claims, refreshSecret)
+}
 
-	secretKey := os.Getenv("REFRESH_TOKEN_SECRET")
-	refreshToken, err := token.SignedString([]byte(secretKey))
+// RefreshTTL - время жизни refresh токена и сессии
+func RefreshTTL() (time.Duration, error) {
+	return time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL"))
+}
+
+func IsValidRefreshToken(tokenString string) (bool, error) {
+	var claims RefreshClaims
+	if err := parse(tokenString, refreshSecret, &claims); err != nil {
+		slog.Warn("error parsing token")
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CreateChallengeToken выдается вместо пары токенов, если у пользователя включена 2FA.
+// Он подтверждает только то, что пароль верный, и обменивается на токены в VerifySecondFactor.
+func CreateChallengeToken(user *models.User, ttl time.Duration) (string, error) {
+	claims := ChallengeClaims{
+		UserID:  user.ID,
+		Purpose: challengePurpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+	}
+
+	return sign(claims, challengeSecret)
+}
+
+func ParseChallengeToken(tokenString string) (int64, error) {
+	var claims ChallengeClaims
+	if err := parse(tokenString, challengeSecret, &claims); err != nil {
+		return 0, err
+	}
+
+	if claims.Purpose != challengePurpose || claims.UserID == 0 {
+		return 0, fmt.Errorf("invalid challenge token")
+	}
+
+	return claims.UserID, nil
+}
+
+// ParseAccessToken проверяет access токен и возвращает id пользователя
+func ParseAccessToken(tokenString string) (int64, error) {
+	var claims AccessClaims
+	if err := parse(tokenString, accessSecret, &claims); err != nil {
+		return 0, err
+	}
+
+	if claims.Purpose != "" || claims.UserID == 0 {
+		return 0, ErrInvalidToken
+	}
+
+	return claims.UserID, nil
+}
+
+// ParseRefreshToken проверяет refresh токен и возвращает id пользователя
+func ParseRefreshToken(tokenString string) (int64, error) {
+	claims, err := parseRefreshClaims(tokenString)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	return refreshToken, nil
+	return claims.UserID, nil
 }
 
-func IsValidRefreshToken(tokenString string) (bool, error) {
-	claims := jwt.MapClaims{}
-	secretKey := os.Getenv("REFRESH_TOKEN_SECRET")
+// ParseSessionToken проверяет refresh токен http сессии: у него должны быть id сессии и jti
+func ParseSessionToken(tokenString string) (*RefreshClaims, error) {
+	claims, err := parseRefreshClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.SessionID == "" || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func parseRefreshClaims(tokenString string) (*RefreshClaims, error) {
+	var claims RefreshClaims
+	if err := parse(tokenString, refreshSecret, &claims); err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != "" || claims.UserID == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+func sign(claims jwt.Claims, secretKey string) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
+}
+
+func parse(tokenString, secretKey string, claims jwt.Claims) error {
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
 		return []byte(secretKey), nil
 	})
 	if err != nil {
-		slog.Warn("error parsing token")
-		return false, err
+		return err
 	}
 
 	if !token.Valid {
-		slog.Warn("invalid token")
-		return false, nil
+		return ErrInvalidToken
 	}
 
-	return true, nil
+	return nil
 }
@@ -0,0 +1,158 @@
+package loginguard
+
+import (
+	"auth_service/internal/config"
+	"auth_service/pkg/metrics"
+	"auth_service/pkg/storage/inmem"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	lockPrefix         = "login:lock:"
+	delayPrefix        = "login:delay:"
+	failuresPrefix     = "login:failures:"
+	userAttemptsPrefix = "login:attempts:user:"
+	ipAttemptsPrefix   = "login:attempts:ip:"
+)
+
+var (
+	ErrLocked          = errors.New("account is temporarily locked")
+	ErrTooManyAttempts = errors.New("too many login attempts")
+)
+
+// LimitError возвращается, когда попытка входа отклонена до проверки пароля
+type LimitError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v, retry after %s", e.Err, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// Guard защищает вход от перебора паролей: ограничивает частоту попыток по логину и IP,
+// увеличивает паузу после каждой неудачи и временно блокирует логин после LockoutThreshold неудач.
+type Guard struct {
+	cfg     config.LoginGuardConfig
+	stor    inmem.LoginAttemptsStorage
+	metrics *metrics.LoginMetrics
+	logger  *zap.Logger
+}
+
+func New(cfg config.LoginGuardConfig, stor inmem.LoginAttemptsStorage, m *metrics.LoginMetrics, logger *zap.Logger) *Guard {
+	return &Guard{
+		cfg:     cfg,
+		stor:    stor,
+		metrics: m,
+		logger:  logger,
+	}
+}
+
+// Allow проверяет блокировки и лимиты и учитывает попытку в окнах логина и IP
+func (g *Guard) Allow(ctx context.Context, username, ip string) error {
+	locked, err := g.stor.BlockedFor(ctx, lockPrefix+username)
+	if err != nil {
+		return err
+	}
+	if locked > 0 {
+		g.metrics.Rejected("locked")
+		return &LimitError{Err: ErrLocked, RetryAfter: locked}
+	}
+
+	delay, err := g.stor.BlockedFor(ctx, delayPrefix+username)
+	if err != nil {
+		return err
+	}
+	if delay > 0 {
+		g.metrics.Rejected("delay")
+		return &LimitError{Err: ErrTooManyAttempts, RetryAfter: delay}
+	}
+
+	userAttempts, err := g.stor.AddAttempt(ctx, userAttemptsPrefix+username, g.cfg.Window)
+	if err != nil {
+		return err
+	}
+	if userAttempts > g.cfg.UserMaxAttempts {
+		g.metrics.Rejected("user_rate")
+		return &LimitError{Err: ErrTooManyAttempts, RetryAfter: g.cfg.Window}
+	}
+
+	if ip == "" {
+		return nil
+	}
+
+	ipAttempts, err := g.stor.AddAttempt(ctx, ipAttemptsPrefix+ip, g.cfg.Window)
+	if err != nil {
+		return err
+	}
+	if ipAttempts > g.cfg.IPMaxAttempts {
+		g.metrics.Rejected("ip_rate")
+		return &LimitError{Err: ErrTooManyAttempts, RetryAfter: g.cfg.Window}
+	}
+
+	return nil
+}
+
+// Failure учитывает неудачную попытку и при необходимости ставит паузу или блокировку
+func (g *Guard) Failure(ctx context.Context, username, reason string) error {
+	g.metrics.Failure(reason)
+
+	failures, err := g.stor.AddAttempt(ctx, failuresPrefix+username, g.cfg.Window)
+	if err != nil {
+		return err
+	}
+
+	if failures >= g.cfg.LockoutThreshold {
+		g.logger.Warn("user locked out after failed logins",
+			zap.String("username", username),
+			zap.Int64("failures", failures))
+		g.metrics.Lockout()
+		return g.stor.Block(ctx, lockPrefix+username, g.cfg.LockoutDuration)
+	}
+
+	if failures <= g.cfg.FreeAttempts {
+		return nil
+	}
+
+	return g.stor.Block(ctx, delayPrefix+username, g.delay(failures-g.cfg.FreeAttempts))
+}
+
+// delay удваивает паузу с каждой неудачей сверх бесплатных, но не больше MaxDelay
+func (g *Guard) delay(extra int64) time.Duration {
+	d := g.cfg.BaseDelay
+	for i := int64(1); i < extra && d < g.cfg.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > g.cfg.MaxDelay {
+		d = g.cfg.MaxDelay
+	}
+
+	return d
+}
+
+// Success сбрасывает счетчик неудач после успешного входа
+func (g *Guard) Success(ctx context.Context, username string) error {
+	return g.stor.Remove(ctx, failuresPrefix+username, delayPrefix+username)
+}
+
+// Unlock снимает блокировку и очищает все окна попыток логина
+func (g *Guard) Unlock(ctx context.Context, username string) error {
+	err := g.stor.Remove(ctx, lockPrefix+username, delayPrefix+username, failuresPrefix+username, userAttemptsPrefix+username)
+	if err != nil {
+		return err
+	}
+
+	g.metrics.Unlock()
+	g.logger.Info("user unlocked", zap.String("username", username))
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package loginguard
+
+import (
+	"auth_service/internal/config"
+	"auth_service/pkg/metrics"
+	"auth_service/pkg/storage/inmem"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"go.uber.org/zap"
+)
+
+// метрики регистрируются в общем реестре prometheus, поэтому создаются один раз на все тесты
+var testLoginMetrics = metrics.NewLoginMetrics()
+
+var testGuardConfig = config.LoginGuardConfig{
+	Window:           15 * time.Minute,
+	UserMaxAttempts:  100,
+	IPMaxAttempts:    100,
+	FreeAttempts:     2,
+	BaseDelay:        time.Second,
+	MaxDelay:         4 * time.Second,
+	LockoutThreshold: 6,
+	LockoutDuration:  time.Minute,
+}
+
+func newTestGuard(t *testing.T, cfg config.LoginGuardConfig) (*Guard, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	t.Setenv("REDIS_HOST", mr.Host())
+	t.Setenv("REDIS_PORT", mr.Port())
+
+	return New(cfg, inmem.NewLoginAttemptsStorage(), testLoginMetrics, zap.NewNop()), mr
+}
+
+// checkAllow сравнивает отказ Allow с want (nil - попытка разрешена) и оставшееся время с retryAfter
+func checkAllow(t *testing.T, g *Guard, username, ip string, want error, retryAfter time.Duration) {
+	t.Helper()
+
+	err := g.Allow(context.Background(), username, ip)
+	if want == nil {
+		if err != nil {
+			t.Fatalf("Allow(%s) = %v, want nil", username, err)
+		}
+		return
+	}
+
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, want) {
+		t.Fatalf("Allow(%s) = %v, want %v", username, err, want)
+	}
+	if limitErr.RetryAfter <= 0 || limitErr.RetryAfter > retryAfter {
+		t.Errorf("retry after = %s, want up to %s", limitErr.RetryAfter, retryAfter)
+	}
+}
+
+func TestGuardFailureThresholds(t *testing.T) {
+	// после failures неудач подряд Allow отвечает want
+	tests := []struct {
+		failures   int
+		want       error
+		retryAfter time.Duration
+	}{
+		{1, nil, 0},
+		{2, nil, 0},
+		{3, ErrTooManyAttempts, time.Second},
+		{4, ErrTooManyAttempts, 2 * time.Second},
+		{5, ErrTooManyAttempts, 4 * time.Second},
+		{6, ErrLocked, time.Minute},
+	}
+
+	g, _ := newTestGuard(t, testGuardConfig)
+	ctx := context.Background()
+	failures := 0
+	for _, tt := range tests {
+		for failures < tt.failures {
+			if err := g.Failure(ctx, "alice", "wrong_password"); err != nil {
+				t.Fatal(err)
+			}
+			failures++
+		}
+		checkAllow(t, g, "alice", "", tt.want, tt.retryAfter)
+	}
+
+	// неудачи одного логина не задерживают другой
+	checkAllow(t, g, "bob", "", nil, 0)
+}
+
+func TestGuardDelay(t *testing.T) {
+	g := &Guard{cfg: testGuardConfig}
+	for extra, want := range map[int64]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		4:  4 * time.Second,
+		50: 4 * time.Second,
+	} {
+		if got := g.delay(extra); got != want {
+			t.Errorf("delay(%d) = %s, want %s", extra, got, want)
+		}
+	}
+}
+
+func TestGuardLockExpires(t *testing.T) {
+	g, mr := newTestGuard(t, testGuardConfig)
+	ctx := context.Background()
+	for i := int64(0); i < testGuardConfig.LockoutThreshold; i++ {
+		if err := g.Failure(ctx, "alice", "wrong_password"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkAllow(t, g, "alice", "", ErrLocked, time.Minute)
+
+	mr.FastForward(testGuardConfig.LockoutDuration - time.Second)
+	checkAllow(t, g, "alice", "", ErrLocked, time.Second)
+
+	mr.FastForward(time.Second)
+	checkAllow(t, g, "alice", "", nil, 0)
+}
+
+func TestGuardSuccessResetsFailures(t *testing.T) {
+	g, _ := newTestGuard(t, testGuardConfig)
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		if err := g.Failure(ctx, "alice", "wrong_password"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := g.Success(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	checkAllow(t, g, "alice", "", nil, 0)
+
+	// счет неудач начинается заново: бесплатные попытки снова без паузы
+	for i := int64(0); i < testGuardConfig.FreeAttempts; i++ {
+		if err := g.Failure(ctx, "alice", "wrong_password"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkAllow(t, g, "alice", "", nil, 0)
+}
+
+func TestGuardUnlock(t *testing.T) {
+	g, _ := newTestGuard(t, testGuardConfig)
+	ctx := context.Background()
+	for i := int64(0); i < testGuardConfig.LockoutThreshold; i++ {
+		if err := g.Failure(ctx, "alice", "wrong_password"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkAllow(t, g, "alice", "", ErrLocked, time.Minute)
+
+	if err := g.Unlock(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	checkAllow(t, g, "alice", "", nil, 0)
+	if err := g.Failure(ctx, "alice", "wrong_password"); err != nil {
+		t.Fatal(err)
+	}
+	checkAllow(t, g, "alice", "", nil, 0)
+}
+
+func TestGuardRateLimits(t *testing.T) {
+	cfg := testGuardConfig
+	cfg.UserMaxAttempts = 3
+	cfg.IPMaxAttempts = 4
+	g, mr := newTestGuard(t, cfg)
+
+	for i := 0; i < 3; i++ {
+		checkAllow(t, g, "alice", "10.0.0.1", nil, 0)
+	}
+	checkAllow(t, g, "alice", "10.0.0.2", ErrTooManyAttempts, cfg.Window)
+
+	// четвертая попытка с адреса прошла, пятая - нет, какой бы логин ни перебирали
+	checkAllow(t, g, "bob", "10.0.0.1", nil, 0)
+	checkAllow(t, g, "carol", "10.0.0.1", ErrTooManyAttempts, cfg.Window)
+	checkAllow(t, g, "carol", "10.0.0.3", nil, 0)
+	// без адреса (вызов без peer) считается только логин
+	checkAllow(t, g, "dave", "", nil, 0)
+
+	mr.FastForward(cfg.Window)
+	checkAllow(t, g, "alice", "10.0.0.1", nil, 0)
+}
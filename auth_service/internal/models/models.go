@@ -1,12 +1,184 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type User struct {
 	ID             int64     `db:"id"`
+	UUID           string    `db:"uuid"`
 	Email          string    `db:"email"`
 	Username       string    `db:"username"`
 	PassHash       string    `db:"passhash"`
 	TimeCreatedAcc time.Time `db:"created_acc"`
 	Role           string    `db:"role"`
+	EmailVerified  bool      `db:"email_verified"`
+	TOTPSecret     string    `db:"totp_secret"`
+	TOTPEnabled    bool      `db:"totp_enabled"`
+	TOTPLastStep   int64     `db:"totp_last_step"`
+	FullName       string    `db:"full_name"`
+	Phone          string    `db:"phone"`
+	Address        string    `db:"address"`
+	City           string    `db:"city"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+// Роли пользователей. Покупатель и продавец - бывшие аккаунты сервиса auth,
+// администратор раньше хранился в отдельной таблице admins.
+const (
+	RoleCustomer = "customer"
+	RoleSeller   = "seller"
+	RoleAdmin    = "admin"
+)
+
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+)
+
+const (
+	PrivacyKindExport  = "export"
+	PrivacyKindErasure = "erasure"
+
+	PrivacyStatusPending = "pending"
+	PrivacyStatusDone    = "done"
+	PrivacyStatusFailed  = "failed"
+)
+
+// PrivacyRequest - запрос на выгрузку или удаление всех данных пользователя во всех сервисах
+type PrivacyRequest struct {
+	ID          int64      `db:"id"`
+	UserID      int64      `db:"user_id"`
+	Kind        string     `db:"kind"`
+	Status      string     `db:"status"`
+	CreatedAt   time.Time  `db:"created_at"`
+	CompletedAt *time.Time `db:"completed_at"`
+}
+
+// PrivacyStep - прогресс запроса в одном сервисе
+type PrivacyStep struct {
+	RequestID int64     `db:"request_id"`
+	Service   string    `db:"service"`
+	Status    string    `db:"status"`
+	Attempts  int       `db:"attempts"`
+	LastError string    `db:"last_error"`
+	Data      []byte    `db:"data"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// OAuthClient - приложение партнера, которое входит через OIDC
+type OAuthClient struct {
+	ClientID         string         `db:"client_id"`
+	ClientSecretHash string         `db:"client_secret_hash"`
+	Name             string         `db:"name"`
+	RedirectURIs     pq.StringArray `db:"redirect_uris"`
+	IsPublic         bool           `db:"is_public"`
+	CreatedAt        time.Time      `db:"created_at"`
+}
+
+// OAuthCode - выданный, но еще не обмененный код авторизации
+type OAuthCode struct {
+	CodeHash      string     `db:"code_hash"`
+	ClientID      string     `db:"client_id"`
+	UserID        int64      `db:"user_id"`
+	RedirectURI   string     `db:"redirect_uri"`
+	Scope         string     `db:"scope"`
+	Nonce         string     `db:"nonce"`
+	CodeChallenge string     `db:"code_challenge"`
+	AuthTime      time.Time  `db:"auth_time"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+	UsedAt        *time.Time `db:"used_at"`
+}
+
+// OAuthRefreshToken - refresh токен OIDC клиента. Он привязан к клиенту и выданному scope
+// и одноразовый: при обмене заменяется новым.
+type OAuthRefreshToken struct {
+	TokenHash string    `db:"token_hash"`
+	ClientID  string    `db:"client_id"`
+	UserID    int64     `db:"user_id"`
+	Scope     string    `db:"scope"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// UserIdentity - привязка аккаунта к пользователю внешнего провайдера (social login)
+type UserIdentity struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	Provider  string    `db:"provider"`
+	Subject   string    `db:"subject"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+const (
+	SellerStatusDraft       = "draft"
+	SellerStatusSubmitted   = "submitted"
+	SellerStatusUnderReview = "under_review"
+	SellerStatusApproved    = "approved"
+	SellerStatusRejected    = "rejected"
+)
+
+// SellerProfile - анкета продавца с юридическими данными и статусом проверки
+type SellerProfile struct {
+	UserID             int64      `db:"user_id"`
+	Status             string     `db:"status"`
+	LegalName          string     `db:"legal_name"`
+	LegalForm          string     `db:"legal_form"`
+	TaxID              string     `db:"tax_id"`
+	RegistrationNumber string     `db:"registration_number"`
+	LegalAddress       string     `db:"legal_address"`
+	BankAccount        string     `db:"bank_account"`
+	BankBIC            string     `db:"bank_bic"`
+	RejectionReason    string     `db:"rejection_reason"`
+	ReviewedBy         *int64     `db:"reviewed_by"`
+	SubmittedAt        *time.Time `db:"submitted_at"`
+	ReviewedAt         *time.Time `db:"reviewed_at"`
+	CreatedAt          time.Time  `db:"created_at"`
+	UpdatedAt          time.Time  `db:"updated_at"`
+}
+
+// SellerApplication - анкета вместе с аккаунтом продавца, как ее видит администратор
+type SellerApplication struct {
+	SellerProfile
+	UUID     string `db:"uuid"`
+	Username string `db:"username"`
+	Email    string `db:"email"`
+}
+
+type SellerStatusChange struct {
+	ID         int64     `db:"id"`
+	UserID     int64     `db:"user_id"`
+	FromStatus string    `db:"from_status"`
+	ToStatus   string    `db:"to_status"`
+	ActorID    *int64    `db:"actor_id"`
+	Comment    string    `db:"comment"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+const (
+	SellerSortRelevance = "relevance"
+	SellerSortUsername  = "username"
+	SellerSortNewest    = "newest"
+	SellerSortOldest    = "oldest"
+)
+
+// SellerFilter - параметры каталога продавцов. Пустой Query - все продавцы.
+type SellerFilter struct {
+	Query  string
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// SellerDirectoryEntry - публичная карточка продавца в каталоге
+type SellerDirectoryEntry struct {
+	UUID               string    `db:"uuid"`
+	Username           string    `db:"username"`
+	FullName           string    `db:"full_name"`
+	City               string    `db:"city"`
+	VerificationStatus string    `db:"verification_status"`
+	TimeCreatedAcc     time.Time `db:"created_acc"`
 }
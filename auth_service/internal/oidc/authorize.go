@@ -0,0 +1,303 @@
+package oidc
+
+import (
+	"auth_service/internal/loginguard"
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"auth_service/internal/tokens"
+	"auth_service/internal/totp"
+	"context"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in</title></head>
+<body>
+<h1>Sign in to {{.ClientName}}</h1>
+{{if .Error}}<p style="color: red">{{.Error}}</p>{{end}}
+<form method="POST" action="/oauth2/authorize">
+  <input type="hidden" name="response_type" value="code">
+  <input type="hidden" name="client_id" value="{{.ClientID}}">
+  <input type="hidden" name="redirect_uri" value="{{.RedirectURI}}">
+  <input type="hidden" name="scope" value="{{.Scope}}">
+  <input type="hidden" name="state" value="{{.State}}">
+  <input type="hidden" name="nonce" value="{{.Nonce}}">
+  <input type="hidden" name="code_challenge" value="{{.CodeChallenge}}">
+  <input type="hidden" name="code_challenge_method" value="S256">
+  <p><label>Username <input name="username" value="{{.Username}}" autocomplete="username" required></label></p>
+  <p><label>Password <input name="password" type="password" autocomplete="current-password" required></label></p>
+  <p><label>Two-factor code (if enabled) <input name="otp" autocomplete="one-time-code"></label></p>
+  <p><button type="submit">Sign in</button></p>
+</form>
+</body>
+</html>
+`))
+
+var errorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in error</title></head>
+<body><h1>Unable to sign in</h1><p>{{.}}</p></body>
+</html>
+`))
+
+// authRequest - проверенные параметры запроса авторизации
+type authRequest struct {
+	ClientID      string
+	ClientName    string
+	RedirectURI   string
+	Scope         string
+	State         string
+	Nonce         string
+	CodeChallenge string
+	Username      string
+	Error         string
+}
+
+// authError - ошибка запроса авторизации. Пока redirect_uri не проверен, ее нельзя отправлять
+// на redirect_uri, и пользователь видит страницу с ошибкой.
+type authError struct {
+	code        string
+	description string
+	redirect    bool
+}
+
+func (p *Provider) parseAuthRequest(r *http.Request) (authRequest, *authError) {
+	client, err := p.stor.GetOAuthClient(r.FormValue("client_id"))
+	if err != nil {
+		if !errors.Is(err, storage.ErrClientNotFound) {
+			p.logger.Error("error getting oauth client", zap.Error(err))
+			return authRequest{}, &authError{code: "server_error", description: "unable to get client"}
+		}
+		return authRequest{}, &authError{code: "invalid_request", description: "unknown client"}
+	}
+
+	redirectURI := r.FormValue("redirect_uri")
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !slices.Contains(client.RedirectURIs, redirectURI) {
+		return authRequest{}, &authError{code: "invalid_request", description: "redirect_uri is not registered for this client"}
+	}
+
+	req := authRequest{
+		ClientID:      client.ClientID,
+		ClientName:    client.Name,
+		RedirectURI:   redirectURI,
+		State:         r.FormValue("state"),
+		Nonce:         r.FormValue("nonce"),
+		CodeChallenge: r.FormValue("code_challenge"),
+	}
+
+	if r.FormValue("response_type") != "code" {
+		return req, &authError{code: "unsupported_response_type", description: "only response_type=code is supported", redirect: true}
+	}
+
+	scopes := make([]string, 0, len(supportedScopes))
+	for _, scope := range strings.Fields(r.FormValue("scope")) {
+		if slices.Contains(supportedScopes, scope) && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if !slices.Contains(scopes, scopeOpenID) {
+		return req, &authError{code: "invalid_scope", description: "openid scope is required", redirect: true}
+	}
+	req.Scope = strings.Join(scopes, " ")
+
+	// PKCE обязателен для всех клиентов, метод plain не поддерживается
+	if req.CodeChallenge == "" || r.FormValue("code_challenge_method") != "S256" {
+		return req, &authError{code: "invalid_request", description: "code_challenge with code_challenge_method=S256 is required", redirect: true}
+	}
+
+	return req, nil
+}
+
+func (p *Provider) authorizeForm(w http.ResponseWriter, r *http.Request) {
+	req, authErr := p.parseAuthRequest(r)
+	if authErr != nil {
+		p.authFailed(w, r, req, authErr)
+		return
+	}
+
+	p.renderLogin(w, http.StatusOK, req)
+}
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	req, authErr := p.parseAuthRequest(r)
+	if authErr != nil {
+		p.authFailed(w, r, req, authErr)
+		return
+	}
+
+	req.Username = r.PostFormValue("username")
+
+	user, err := p.authenticate(r.Context(), req.Username, r.PostFormValue("password"), r.PostFormValue("otp"), remoteIP(r))
+	if err != nil {
+		req.Error = err.Error()
+		p.renderLogin(w, http.StatusUnauthorized, req)
+		return
+	}
+
+	code, codeHash, err := tokens.Generate()
+	if err != nil {
+		p.logger.Error("error generating authorization code", zap.Error(err))
+		p.authFailed(w, r, req, &authError{code: "server_error", description: "unable to issue code", redirect: true})
+		return
+	}
+
+	now := time.Now()
+	err = p.stor.SaveOAuthCode(models.OAuthCode{
+		CodeHash:      codeHash,
+		ClientID:      req.ClientID,
+		UserID:        user.ID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         req.Scope,
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      now,
+		ExpiresAt:     now.Add(p.cfg.CodeTTL),
+	})
+	if err != nil {
+		p.logger.Error("error saving authorization code", zap.Error(err))
+		p.authFailed(w, r, req, &authError{code: "server_error", description: "unable to issue code", redirect: true})
+		return
+	}
+
+	p.logger.Info("authorization code issued", zap.String("clientId", req.ClientID), zap.Int64("userId", user.ID))
+
+	redirect(w, r, req, url.Values{"code": {code}})
+}
+
+// authenticate повторяет проверки grpc Login: лимиты попыток, пароль, подтвержденная почта и 2FA
+func (p *Provider) authenticate(ctx context.Context, username, password, otp, ip string) (models.User, error) {
+	if err := p.guard.Allow(ctx, username, ip); err != nil {
+		var limitErr *loginguard.LimitError
+		if errors.As(err, &limitErr) {
+			return models.User{}, limitErr
+		}
+		p.logger.Error("error checking login limits", zap.Error(err))
+		return models.User{}, errors.New("unable to check login limits")
+	}
+
+	user, err := p.stor.GetUser(username)
+	if err != nil {
+		if !errors.Is(err, storage.ErrUserNotFound) {
+			p.logger.Error("error getting user", zap.Error(err))
+			return models.User{}, errors.New("unable to get user")
+		}
+		return models.User{}, p.loginFailed(ctx, username, "unknown_user")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PassHash), []byte(password)); err != nil {
+		return models.User{}, p.loginFailed(ctx, username, "wrong_password")
+	}
+
+	if user.TOTPEnabled {
+		ok, err := p.useSecondFactor(user, otp)
+		if err != nil {
+			p.logger.Error("error checking second factor", zap.Error(err))
+			return models.User{}, errors.New("unable to check two-factor code")
+		}
+		if !ok {
+			return models.User{}, p.loginFailed(ctx, username, "wrong_second_factor")
+		}
+	}
+
+	if err := p.guard.Success(ctx, username); err != nil {
+		p.logger.Warn("error resetting failed logins", zap.Error(err))
+	}
+
+	if p.verificationCfg.RequireVerifiedEmail && !user.EmailVerified {
+		return models.User{}, errors.New("email is not verified")
+	}
+
+	return user, nil
+}
+
+func (p *Provider) useSecondFactor(user models.User, code string) (bool, error) {
+	if code == "" {
+		return false, nil
+	}
+
+	if step, ok := totp.Validate(user.TOTPSecret, code, time.Now()); ok {
+		return p.stor.UseTOTPStep(user.ID, step)
+	}
+
+	return p.stor.UseRecoveryCode(user.ID, tokens.Hash(tokens.NormalizeRecoveryCode(code)))
+}
+
+func (p *Provider) loginFailed(ctx context.Context, username, reason string) error {
+	if err := p.guard.Failure(ctx, username, reason); err != nil {
+		p.logger.Error("error registering failed login", zap.Error(err))
+	}
+
+	return errors.New("invalid username, password or two-factor code")
+}
+
+func (p *Provider) renderLogin(w http.ResponseWriter, status int, req authRequest) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+	w.WriteHeader(status)
+	if err := loginPage.Execute(w, req); err != nil {
+		p.logger.Error("error rendering login page", zap.Error(err))
+	}
+}
+
+func (p *Provider) authFailed(w http.ResponseWriter, r *http.Request, req authRequest, authErr *authError) {
+	if authErr.redirect {
+		redirect(w, r, req, url.Values{
+			"error":             {authErr.code},
+			"error_description": {authErr.description},
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	if err := errorPage.Execute(w, authErr.description); err != nil {
+		p.logger.Error("error rendering error page", zap.Error(err))
+	}
+}
+
+// redirect возвращает пользователя в приложение клиента, сохраняя параметры его redirect_uri
+func redirect(w http.ResponseWriter, r *http.Request, req authRequest, params url.Values) {
+	target, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	query := target.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	target.RawQuery = query.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// remoteIP - адрес соединения. Заголовкам X-Real-IP здесь не доверяем: страница входа открыта
+// напрямую из браузера, и подделанный заголовок позволил бы обойти лимит попыток по IP.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,144 @@
+package oidc
+
+import (
+	"auth_service/internal/models"
+	"auth_service/internal/tokens"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type registrationRequest struct {
+	ClientName              string   `json:"client_name"`
+	RedirectURIs            []string `json:"redirect_uris"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+}
+
+type registrationResponse struct {
+	ClientID                string   `json:"client_id"`
+	ClientSecret            string   `json:"client_secret,omitempty"`
+	ClientName              string   `json:"client_name"`
+	RedirectURIs            []string `json:"redirect_uris"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+	ClientIDIssuedAt        int64    `json:"client_id_issued_at"`
+}
+
+// register регистрирует клиента (RFC 7591). Регистрация закрыта токеном из OIDC_REGISTRATION_TOKEN,
+// без него эндпоинт выключен. client_secret показывается один раз, в базе хранится его хеш.
+func (p *Provider) register(w http.ResponseWriter, r *http.Request) {
+	registrationToken := os.Getenv("OIDC_REGISTRATION_TOKEN")
+	if registrationToken == "" {
+		writeError(w, http.StatusForbidden, "access_denied", "client registration is disabled")
+		return
+	}
+
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(registrationToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		writeError(w, http.StatusUnauthorized, "invalid_token", "invalid registration token")
+		return
+	}
+
+	var req registrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_client_metadata", "invalid request body")
+		return
+	}
+
+	if req.ClientName == "" {
+		writeError(w, http.StatusBadRequest, "invalid_client_metadata", "client_name is required")
+		return
+	}
+	if len(req.RedirectURIs) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_redirect_uri", "redirect_uris are required")
+		return
+	}
+	for _, uri := range req.RedirectURIs {
+		if err := validateRedirectURI(uri); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_redirect_uri", err.Error())
+			return
+		}
+	}
+
+	if req.TokenEndpointAuthMethod == "" {
+		req.TokenEndpointAuthMethod = "client_secret_basic"
+	}
+	switch req.TokenEndpointAuthMethod {
+	case "client_secret_basic", "client_secret_post", "none":
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_client_metadata", "unsupported token_endpoint_auth_method")
+		return
+	}
+
+	clientId, _, err := tokens.Generate()
+	if err != nil {
+		p.logger.Error("error generating client id", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "server_error", "unable to register client")
+		return
+	}
+
+	client := models.OAuthClient{
+		ClientID:     clientId,
+		Name:         req.ClientName,
+		RedirectURIs: pq.StringArray(req.RedirectURIs),
+		IsPublic:     req.TokenEndpointAuthMethod == "none",
+	}
+
+	resp := registrationResponse{
+		ClientID:                clientId,
+		ClientName:              req.ClientName,
+		RedirectURIs:            req.RedirectURIs,
+		TokenEndpointAuthMethod: req.TokenEndpointAuthMethod,
+		ClientIDIssuedAt:        time.Now().Unix(),
+	}
+
+	if !client.IsPublic {
+		resp.ClientSecret, client.ClientSecretHash, err = tokens.Generate()
+		if err != nil {
+			p.logger.Error("error generating client secret", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "server_error", "unable to register client")
+			return
+		}
+	}
+
+	if err := p.stor.CreateOAuthClient(client); err != nil {
+		p.logger.Error("error saving oauth client", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "server_error", "unable to register client")
+		return
+	}
+
+	p.logger.Info("oauth client registered", zap.String("clientId", clientId), zap.String("name", req.ClientName))
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// validateRedirectURI разрешает только https, а http - только для локальной разработки
+func validateRedirectURI(uri string) error {
+	parsed, err := url.Parse(uri)
+	if err != nil || !parsed.IsAbs() || parsed.Host == "" {
+		return fmt.Errorf("redirect_uri %q is not an absolute url", uri)
+	}
+	if parsed.Fragment != "" {
+		return fmt.Errorf("redirect_uri %q must not contain a fragment", uri)
+	}
+
+	switch parsed.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := parsed.Hostname()
+		if host == "localhost" || host == "127.0.0.1" || host == "::1" {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("redirect_uri %q must use https", uri)
+}
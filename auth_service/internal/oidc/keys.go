@@ -0,0 +1,88 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// signingKey подписывает id_token по RS256. Партнеры проверяют подпись по открытому ключу из jwks.
+type signingKey struct {
+	private *rsa.PrivateKey
+	kid     string
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// loadSigningKey читает RSA ключ из PEM (PKCS#1 или PKCS#8). Если путь не задан, создается
+// временный ключ: выданные id_token перестанут проверяться после перезапуска.
+func loadSigningKey(path string) (*signingKey, bool, error) {
+	if path == "" {
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, false, fmt.Errorf("error generating signing key: %w", err)
+		}
+		return newSigningKey(private), true, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, false, errors.New("signing key is not in PEM format")
+	}
+
+	if private, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return newSigningKey(private), false, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, false, fmt.Errorf("error parsing signing key: %w", err)
+	}
+
+	private, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, false, errors.New("signing key is not an RSA key")
+	}
+
+	return newSigningKey(private), false, nil
+}
+
+// newSigningKey вычисляет kid из модуля ключа, чтобы он не менялся между перезапусками
+func newSigningKey(private *rsa.PrivateKey) *signingKey {
+	sum := sha256.Sum256(private.N.Bytes())
+
+	return &signingKey{
+		private: private,
+		kid:     base64.RawURLEncoding.EncodeToString(sum[:12]),
+	}
+}
+
+func (k *signingKey) jwk() jwk {
+	return jwk{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		Kid: k.kid,
+		N:   base64.RawURLEncoding.EncodeToString(k.private.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.private.E)).Bytes()),
+	}
+}
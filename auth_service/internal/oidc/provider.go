@@ -0,0 +1,133 @@
+package oidc
+
+import (
+	"auth_service/internal/config"
+	"auth_service/internal/loginguard"
+	"auth_service/internal/repositiry/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	scopeOpenID        = "openid"
+	scopeProfile       = "profile"
+	scopeEmail         = "email"
+	scopeOfflineAccess = "offline_access"
+)
+
+var supportedScopes = []string{scopeOpenID, scopeProfile, scopeEmail, scopeOfflineAccess}
+
+// Provider - http сервер OpenID Connect поверх тех же пользователей, лимитов входа и токенов,
+// что и grpc сервис. Партнерские приложения регистрируются как клиенты и получают
+// access токен магазина, refresh токен (со scope offline_access) и id_token.
+type Provider struct {
+	cfg             config.OIDCConfig
+	verificationCfg config.VerificationConfig
+	stor            *storage.Storage
+	guard           *loginguard.Guard
+	key             *signingKey
+	server          *http.Server
+	logger          *zap.Logger
+}
+
+func New(cfg config.OIDCConfig, verificationCfg config.VerificationConfig, stor *storage.Storage, guard *loginguard.Guard, logger *zap.Logger) *Provider {
+	key, ephemeral, err := loadSigningKey(cfg.SigningKeyPath)
+	if err != nil {
+		logger.Fatal("failed to load oidc signing key", zap.Error(err))
+	}
+	if ephemeral {
+		logger.Warn("oidc signing key is not configured, using a temporary key")
+	}
+
+	p := &Provider{
+		cfg:             cfg,
+		verificationCfg: verificationCfg,
+		stor:            stor,
+		guard:           guard,
+		key:             key,
+		logger:          logger,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /oauth2/jwks", p.jwks)
+	mux.HandleFunc("GET /oauth2/authorize", p.authorizeForm)
+	mux.HandleFunc("POST /oauth2/authorize", p.authorize)
+	mux.HandleFunc("POST /oauth2/token", p.token)
+	mux.HandleFunc("GET /oauth2/userinfo", p.userinfo)
+	mux.HandleFunc("POST /oauth2/userinfo", p.userinfo)
+	mux.HandleFunc("POST /oauth2/register", p.register)
+
+	p.server = &http.Server{
+		Addr:              fmt.Sprintf("0.0.0.0:%s", cfg.HTTPPort),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return p
+}
+
+func (p *Provider) MustStart() {
+	p.logger.Info("oidc provider start", zap.String("port", p.cfg.HTTPPort), zap.String("issuer", p.cfg.Issuer))
+	err := p.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		p.logger.Fatal("failed to start oidc provider", zap.Error(err))
+	}
+}
+
+func (p *Provider) Shutdown(ctx context.Context) {
+	p.logger.Info("oidc provider stopping")
+	if err := p.server.Shutdown(ctx); err != nil {
+		p.logger.Error("error stopping oidc provider", zap.Error(err))
+	}
+	p.logger.Info("oidc provider stopped")
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.cfg.Issuer,
+		"authorization_endpoint":                p.cfg.Issuer + "/oauth2/authorize",
+		"token_endpoint":                        p.cfg.Issuer + "/oauth2/token",
+		"userinfo_endpoint":                     p.cfg.Issuer + "/oauth2/userinfo",
+		"jwks_uri":                              p.cfg.Issuer + "/oauth2/jwks",
+		"registration_endpoint":                 p.cfg.Issuer + "/oauth2/register",
+		"scopes_supported":                      supportedScopes,
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported": []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"preferred_username", "name", "email", "email_verified",
+		},
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []jwk{p.key.jwk()},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError отвечает ошибкой в формате RFC 6749
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
@@ -0,0 +1,299 @@
+package oidc
+
+import (
+	"auth_service/internal/jwt"
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"auth_service/internal/tokens"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "unable to parse form")
+		return
+	}
+
+	client, ok := p.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		p.exchangeCode(w, r, client)
+	case "refresh_token":
+		p.refresh(w, r, client)
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code and refresh_token are supported")
+	}
+}
+
+// authenticateClient проверяет client_secret конфиденциального клиента (basic или в теле запроса).
+// Публичный клиент передает только client_id, его защищает PKCE.
+func (p *Provider) authenticateClient(w http.ResponseWriter, r *http.Request) (models.OAuthClient, bool) {
+	clientId, secret, basic := r.BasicAuth()
+	if !basic {
+		clientId = r.PostFormValue("client_id")
+		secret = r.PostFormValue("client_secret")
+	}
+
+	client, err := p.stor.GetOAuthClient(clientId)
+	if err != nil {
+		if !errors.Is(err, storage.ErrClientNotFound) {
+			p.logger.Error("error getting oauth client", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "server_error", "unable to get client")
+			return models.OAuthClient{}, false
+		}
+		clientFailed(w, basic)
+		return models.OAuthClient{}, false
+	}
+
+	if client.IsPublic {
+		return client, true
+	}
+
+	if subtle.ConstantTimeCompare([]byte(tokens.Hash(secret)), []byte(client.ClientSecretHash)) != 1 {
+		p.logger.Warn("invalid client secret", zap.String("clientId", clientId))
+		clientFailed(w, basic)
+		return models.OAuthClient{}, false
+	}
+
+	return client, true
+}
+
+func clientFailed(w http.ResponseWriter, basic bool) {
+	if basic {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth2"`)
+	}
+	writeError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+}
+
+func (p *Provider) exchangeCode(w http.ResponseWriter, r *http.Request, client models.OAuthClient) {
+	code, err := p.stor.ConsumeOAuthCode(tokens.Hash(r.PostFormValue("code")), client.ClientID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrCodeInvalid) {
+			p.logger.Error("error consuming authorization code", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "server_error", "unable to check code")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+		return
+	}
+
+	if code.RedirectURI != r.PostFormValue("redirect_uri") {
+		writeError(w, http.StatusBadRequest, "invalid_grant", "redirect_uri does not match")
+		return
+	}
+
+	if !verifyCodeChallenge(code.CodeChallenge, r.PostFormValue("code_verifier")) {
+		p.logger.Warn("pkce verification failed", zap.String("clientId", client.ClientID))
+		writeError(w, http.StatusBadRequest, "invalid_grant", "code_verifier does not match")
+		return
+	}
+
+	user, err := p.stor.GetUserById(code.UserID)
+	if err != nil {
+		p.userFailed(w, err)
+		return
+	}
+
+	p.issueTokens(w, r, client, user, code.Scope, code.Scope, code.Nonce, code.AuthTime)
+}
+
+// refresh обменивает refresh токен. Токен привязан к клиенту, которому выдан, и одноразовый:
+// в ответ выдается новый с тем же scope. Клиент может запросить scope уже, но не шире выданного.
+func (p *Provider) refresh(w http.ResponseWriter, r *http.Request, client models.OAuthClient) {
+	stored, err := p.stor.ConsumeOAuthRefreshToken(tokens.Hash(r.PostFormValue("refresh_token")), client.ClientID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrRefreshTokenInvalid) {
+			p.logger.Error("error consuming refresh token", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "server_error", "unable to check refresh token")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+		return
+	}
+
+	scope := stored.Scope
+	if requested := r.PostFormValue("scope"); requested != "" {
+		granted := strings.Fields(stored.Scope)
+		for _, s := range strings.Fields(requested) {
+			if !slices.Contains(granted, s) {
+				writeError(w, http.StatusBadRequest, "invalid_scope", "scope exceeds the originally granted scope")
+				return
+			}
+		}
+		scope = requested
+	}
+
+	user, err := p.stor.GetUserById(stored.UserID)
+	if err != nil {
+		p.userFailed(w, err)
+		return
+	}
+
+	p.issueTokens(w, r, client, user, scope, stored.Scope, "", time.Time{})
+}
+
+func (p *Provider) userFailed(w http.ResponseWriter, err error) {
+	if errors.Is(err, storage.ErrUserNotFound) {
+		writeError(w, http.StatusBadRequest, "invalid_grant", "user not found")
+		return
+	}
+
+	p.logger.Error("error getting user", zap.Error(err))
+	writeError(w, http.StatusInternalServerError, "server_error", "unable to get user")
+}
+
+// issueTokens выдает access токен магазина и id_token со scope, а при offline_access - refresh
+// токен клиента с grantedScope (при обновлении он не сужается вместе с запрошенным scope)
+func (p *Provider) issueTokens(w http.ResponseWriter, r *http.Request, client models.OAuthClient, user models.User, scope, grantedScope, nonce string, authTime time.Time) {
+	scopes := strings.Fields(scope)
+
+	accessToken, err := jwt.CreateAccessToken(&user)
+	if err != nil {
+		p.logger.Error("error creating access token", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "server_error", "unable to create access token")
+		return
+	}
+
+	accessTTL, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL"))
+	if err != nil {
+		p.logger.Error("error parsing access ttl", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "server_error", "unable to create access token")
+		return
+	}
+
+	resp := tokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(accessTTL.Seconds()),
+		Scope:       scope,
+	}
+
+	if slices.Contains(strings.Fields(grantedScope), scopeOfflineAccess) {
+		resp.RefreshToken, err = p.createRefreshToken(client, user, grantedScope)
+		if err != nil {
+			p.logger.Error("error creating refresh token", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "server_error", "unable to create refresh token")
+			return
+		}
+	}
+
+	resp.IDToken, err = p.idToken(client, user, scopes, nonce, authTime)
+	if err != nil {
+		p.logger.Error("error creating id token", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "server_error", "unable to create id token")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// createRefreshToken выдает refresh токен клиенту. Он хранится отдельно от refresh токена
+// grpc сервиса, поэтому вход в партнерском приложении не разлогинивает пользователя в магазине.
+func (p *Provider) createRefreshToken(client models.OAuthClient, user models.User, scope string) (string, error) {
+	refreshTTL, err := jwt.RefreshTTL()
+	if err != nil {
+		return "", err
+	}
+
+	token, hash, err := tokens.Generate()
+	if err != nil {
+		return "", err
+	}
+
+	err = p.stor.SaveOAuthRefreshToken(models.OAuthRefreshToken{
+		TokenHash: hash,
+		ClientID:  client.ClientID,
+		UserID:    user.ID,
+		Scope:     scope,
+		ExpiresAt: time.Now().Add(refreshTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (p *Provider) idToken(client models.OAuthClient, user models.User, scopes []string, nonce string, authTime time.Time) (string, error) {
+	now := time.Now()
+
+	claims := gojwt.MapClaims{
+		"iss": p.cfg.Issuer,
+		"sub": strconv.FormatInt(user.ID, 10),
+		"aud": client.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(p.cfg.IDTokenTTL).Unix(),
+	}
+	if !authTime.IsZero() {
+		claims["auth_time"] = authTime.Unix()
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	for key, value := range userClaims(user, scopes) {
+		claims[key] = value
+	}
+
+	token := gojwt.NewWithClaims(gojwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.key.kid
+
+	return token.SignedString(p.key.private)
+}
+
+// userClaims - стандартные claims OIDC, которые разрешены выданными scope
+func userClaims(user models.User, scopes []string) map[string]any {
+	claims := map[string]any{
+		"sub": strconv.FormatInt(user.ID, 10),
+	}
+
+	if slices.Contains(scopes, scopeProfile) {
+		claims["preferred_username"] = user.Username
+		if user.FullName != "" {
+			claims["name"] = user.FullName
+		}
+	}
+
+	if slices.Contains(scopes, scopeEmail) {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.EmailVerified
+	}
+
+	return claims
+}
+
+// verifyCodeChallenge проверяет PKCE S256: challenge = BASE64URL(SHA256(verifier))
+func verifyCodeChallenge(challenge, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
@@ -0,0 +1,43 @@
+package oidc
+
+import (
+	"auth_service/internal/jwt"
+	"auth_service/internal/repositiry/storage"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// userinfo отдает claims владельца access токена. В access токене магазина нет scope,
+// поэтому возвращаются claims всех поддерживаемых scope.
+func (p *Provider) userinfo(w http.ResponseWriter, r *http.Request) {
+	accessToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="userinfo"`)
+		writeError(w, http.StatusUnauthorized, "invalid_token", "bearer token is required")
+		return
+	}
+
+	userId, err := jwt.ParseAccessToken(accessToken)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		writeError(w, http.StatusUnauthorized, "invalid_token", "invalid or expired access token")
+		return
+	}
+
+	user, err := p.stor.GetUserById(userId)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, "invalid_token", "user not found")
+			return
+		}
+		p.logger.Error("error getting user", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "server_error", "unable to get user")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, userClaims(user, supportedScopes))
+}
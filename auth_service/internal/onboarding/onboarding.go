@@ -0,0 +1,108 @@
+// Package onboarding описывает жизненный цикл анкеты продавца.
+//
+//	draft -> submitted -> under_review -> approved
+//	                                   -> rejected -> draft
+//
+// Анкету заполняет продавец в статусах draft и rejected (правка отклоненной анкеты возвращает ее
+// в черновик), отправляет на проверку только заполненную. Остальные переходы делает администратор.
+package onboarding
+
+import (
+	"auth_service/internal/models"
+	"auth_service/internal/validation"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid seller status transition")
+	ErrNotEditable       = errors.New("seller profile can be edited only in draft or rejected status")
+	ErrIncomplete        = errors.New("seller profile is incomplete")
+)
+
+// Actor - кто меняет статус анкеты: сам продавец или администратор
+type Actor string
+
+const (
+	ActorSeller Actor = "seller"
+	ActorAdmin  Actor = "admin"
+)
+
+type transition struct {
+	to    string
+	actor Actor
+}
+
+var transitions = map[string][]transition{
+	models.SellerStatusDraft:     {{models.SellerStatusSubmitted, ActorSeller}},
+	models.SellerStatusSubmitted: {{models.SellerStatusUnderReview, ActorAdmin}},
+	models.SellerStatusUnderReview: {
+		{models.SellerStatusApproved, ActorAdmin},
+		{models.SellerStatusRejected, ActorAdmin},
+	},
+	models.SellerStatusRejected: {{models.SellerStatusDraft, ActorSeller}},
+}
+
+// Transition проверяет, может ли actor перевести анкету из статуса from в статус to.
+// Продавец не может сам начать проверку своей анкеты или одобрить ее.
+func Transition(from, to string, actor Actor) error {
+	for _, next := range transitions[from] {
+		if next.to == to && next.actor == actor {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s -> %s by %s", ErrInvalidTransition, from, to, actor)
+}
+
+func Editable(status string) error {
+	if status != models.SellerStatusDraft && status != models.SellerStatusRejected {
+		return ErrNotEditable
+	}
+
+	return nil
+}
+
+// CanPublish - публиковать товары может только одобренный продавец
+func CanPublish(status string) bool {
+	return status == models.SellerStatusApproved
+}
+
+// Validate проверяет формат заполненных полей, Complete - что заполнено все нужное для проверки
+func Validate(profile models.SellerProfile) error {
+	return errors.Join(
+		validation.LegalName(profile.LegalName),
+		validation.LegalForm(profile.LegalForm),
+		validation.TaxID(profile.TaxID),
+		validation.RegistrationNumber(profile.RegistrationNumber),
+		validation.Address(profile.LegalAddress),
+		validation.BankAccount(profile.BankAccount),
+		validation.BankBIC(profile.BankBIC),
+	)
+}
+
+func Complete(profile models.SellerProfile) error {
+	var missing []string
+	for field, value := range map[string]string{
+		"legal_name":          profile.LegalName,
+		"legal_form":          profile.LegalForm,
+		"tax_id":              profile.TaxID,
+		"registration_number": profile.RegistrationNumber,
+		"legal_address":       profile.LegalAddress,
+		"bank_account":        profile.BankAccount,
+		"bank_bic":            profile.BankBIC,
+	} {
+		if value == "" {
+			missing = append(missing, field)
+		}
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return fmt.Errorf("%w: %s are required", ErrIncomplete, strings.Join(missing, ", "))
+	}
+
+	return Validate(profile)
+}
@@ -0,0 +1,139 @@
+package onboarding
+
+import (
+	"auth_service/internal/models"
+	"auth_service/internal/validation"
+	"errors"
+	"testing"
+)
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		actor   Actor
+		allowed bool
+	}{
+		{"seller submits draft", models.SellerStatusDraft, models.SellerStatusSubmitted, ActorSeller, true},
+		{"admin starts review", models.SellerStatusSubmitted, models.SellerStatusUnderReview, ActorAdmin, true},
+		{"admin approves", models.SellerStatusUnderReview, models.SellerStatusApproved, ActorAdmin, true},
+		{"admin rejects", models.SellerStatusUnderReview, models.SellerStatusRejected, ActorAdmin, true},
+		{"seller edits rejected", models.SellerStatusRejected, models.SellerStatusDraft, ActorSeller, true},
+
+		{"seller approves own profile", models.SellerStatusUnderReview, models.SellerStatusApproved, ActorSeller, false},
+		{"seller rejects own profile", models.SellerStatusUnderReview, models.SellerStatusRejected, ActorSeller, false},
+		{"seller starts own review", models.SellerStatusSubmitted, models.SellerStatusUnderReview, ActorSeller, false},
+		{"admin submits for seller", models.SellerStatusDraft, models.SellerStatusSubmitted, ActorAdmin, false},
+		{"approved back to draft", models.SellerStatusApproved, models.SellerStatusDraft, ActorSeller, false},
+		{"approved to rejected", models.SellerStatusApproved, models.SellerStatusRejected, ActorAdmin, false},
+		{"approve without review", models.SellerStatusSubmitted, models.SellerStatusApproved, ActorAdmin, false},
+		{"approve draft", models.SellerStatusDraft, models.SellerStatusApproved, ActorAdmin, false},
+		{"resubmit rejected without edit", models.SellerStatusRejected, models.SellerStatusSubmitted, ActorSeller, false},
+		{"same status", models.SellerStatusDraft, models.SellerStatusDraft, ActorSeller, false},
+		{"unknown status", "archived", models.SellerStatusDraft, ActorAdmin, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Transition(tt.from, tt.to, tt.actor)
+			if tt.allowed && err != nil {
+				t.Errorf("Transition(%s, %s, %s) = %v, want nil", tt.from, tt.to, tt.actor, err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrInvalidTransition) {
+				t.Errorf("Transition(%s, %s, %s) = %v, want ErrInvalidTransition", tt.from, tt.to, tt.actor, err)
+			}
+		})
+	}
+}
+
+func TestEditable(t *testing.T) {
+	tests := []struct {
+		status string
+		want   error
+	}{
+		{models.SellerStatusDraft, nil},
+		{models.SellerStatusRejected, nil},
+		{models.SellerStatusSubmitted, ErrNotEditable},
+		{models.SellerStatusUnderReview, ErrNotEditable},
+		{models.SellerStatusApproved, ErrNotEditable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if err := Editable(tt.status); !errors.Is(err, tt.want) {
+				t.Errorf("Editable(%s) = %v, want %v", tt.status, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanPublish(t *testing.T) {
+	for _, status := range []string{
+		models.SellerStatusDraft,
+		models.SellerStatusSubmitted,
+		models.SellerStatusUnderReview,
+		models.SellerStatusRejected,
+	} {
+		if CanPublish(status) {
+			t.Errorf("CanPublish(%s) = true", status)
+		}
+	}
+	if !CanPublish(models.SellerStatusApproved) {
+		t.Error("CanPublish(approved) = false")
+	}
+}
+
+func completeProfile() models.SellerProfile {
+	return models.SellerProfile{
+		LegalName:          "ООО Ромашка",
+		LegalForm:          "llc",
+		TaxID:              "7701234567",
+		RegistrationNumber: "1027700132195",
+		LegalAddress:       "Москва, ул. Тверская, 1",
+		BankAccount:        "40702810900000000001",
+		BankBIC:            "044525225",
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*models.SellerProfile)
+		want    error
+		wantMsg string
+	}{
+		{name: "complete", modify: func(*models.SellerProfile) {}},
+		{
+			name:    "missing fields",
+			modify:  func(p *models.SellerProfile) { p.TaxID, p.BankBIC = "", "" },
+			want:    ErrIncomplete,
+			wantMsg: "seller profile is incomplete: bank_bic, tax_id are required",
+		},
+		{
+			name:   "invalid format",
+			modify: func(p *models.SellerProfile) { p.BankAccount = "4070281090" },
+			want:   validation.ErrInvalidBankAccount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := completeProfile()
+			tt.modify(&profile)
+			err := Complete(profile)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Complete() = %v, want %v", err, tt.want)
+			}
+			if tt.wantMsg != "" && err.Error() != tt.wantMsg {
+				t.Errorf("Complete() = %q, want %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestValidateAllowsEmptyFields(t *testing.T) {
+	if err := Validate(models.SellerProfile{}); err != nil {
+		t.Errorf("Validate(empty) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,190 @@
+package privacy
+
+import (
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"auth_service/pkg/storage/inmem"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const authServiceName = "auth"
+
+// Participant - сервис, который хранит данные пользователя и умеет их выгрузить или обезличить.
+// Erase должен быть идемпотентным: при ошибке шаг повторяется целиком.
+type Participant interface {
+	Name() string
+	Export(ctx context.Context, userId int64) (json.RawMessage, error)
+	Erase(ctx context.Context, userId int64) error
+}
+
+// httpParticipant вызывает внутренний http контракт сервиса:
+//
+//	GET  {url}/internal/users/{id}/export - JSON со всеми данными пользователя
+//	POST {url}/internal/users/{id}/erase  - удаление или обезличивание данных
+//
+// Запросы подписываются заголовком X-Internal-Token из переменной INTERNAL_API_TOKEN.
+type httpParticipant struct {
+	name    string
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewHTTPParticipant(name, baseURL string, timeout time.Duration) Participant {
+	return &httpParticipant{
+		name:    name,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   os.Getenv("INTERNAL_API_TOKEN"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *httpParticipant) Name() string {
+	return p.name
+}
+
+func (p *httpParticipant) Export(ctx context.Context, userId int64) (json.RawMessage, error) {
+	body, err := p.do(ctx, http.MethodGet, fmt.Sprintf("%s/internal/users/%d/export", p.baseURL, userId))
+	if err != nil {
+		return nil, err
+	}
+
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("%s returned invalid json", p.name)
+	}
+
+	return body, nil
+}
+
+func (p *httpParticipant) Erase(ctx context.Context, userId int64) error {
+	_, err := p.do(ctx, http.MethodPost, fmt.Sprintf("%s/internal/users/%d/erase", p.baseURL, userId))
+
+	return err
+}
+
+func (p *httpParticipant) do(ctx context.Context, method, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Internal-Token", p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %s: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s response: %w", p.name, err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("%s responded with %d: %s", p.name, resp.StatusCode, body)
+	}
+
+	return body, nil
+}
+
+// authParticipant - данные самого auth_service. При удалении он выполняется последним,
+// чтобы пользователь мог войти и проверить статус, пока другие сервисы не закончили.
+type authParticipant struct {
+	stor          *storage.Storage
+	refreshTokens inmem.RefreshTokenStorage
+}
+
+func NewAuthParticipant(stor *storage.Storage, refreshTokens inmem.RefreshTokenStorage) Participant {
+	return &authParticipant{
+		stor:          stor,
+		refreshTokens: refreshTokens,
+	}
+}
+
+func (p *authParticipant) Name() string {
+	return authServiceName
+}
+
+type authExport struct {
+	ID               int64            `json:"id"`
+	UUID             string           `json:"uuid"`
+	Username         string           `json:"username"`
+	Email            string           `json:"email"`
+	EmailVerified    bool             `json:"email_verified"`
+	FullName         string           `json:"full_name"`
+	Phone            string           `json:"phone"`
+	Address          string           `json:"address"`
+	City             string           `json:"city"`
+	Role             string           `json:"role"`
+	TwoFactorEnabled bool             `json:"two_factor_enabled"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	Identities       []identityExport `json:"linked_accounts"`
+}
+
+type identityExport struct {
+	Provider string    `json:"provider"`
+	Email    string    `json:"email"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+func (p *authParticipant) Export(ctx context.Context, userId int64) (json.RawMessage, error) {
+	user, err := p.stor.GetUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := p.stor.GetUserIdentities(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	export := newAuthExport(user)
+	for _, identity := range identities {
+		export.Identities = append(export.Identities, identityExport{
+			Provider: identity.Provider,
+			Email:    identity.Email,
+			LinkedAt: identity.CreatedAt,
+		})
+	}
+
+	return json.Marshal(export)
+}
+
+func newAuthExport(user models.User) authExport {
+	return authExport{
+		ID:               user.ID,
+		UUID:             user.UUID,
+		Username:         user.Username,
+		Email:            user.Email,
+		EmailVerified:    user.EmailVerified,
+		FullName:         user.FullName,
+		Phone:            user.Phone,
+		Address:          user.Address,
+		City:             user.City,
+		Role:             user.Role,
+		TwoFactorEnabled: user.TOTPEnabled,
+		CreatedAt:        user.TimeCreatedAcc,
+		UpdatedAt:        user.UpdatedAt,
+	}
+}
+
+func (p *authParticipant) Erase(ctx context.Context, userId int64) error {
+	err := p.stor.DeleteUser(userId)
+	if err != nil && !errors.Is(err, storage.ErrUserNotFound) {
+		return err
+	}
+
+	// токена может уже не быть, это не ошибка
+	_ = p.refreshTokens.RemoveToken(ctx, userId)
+
+	return nil
+}
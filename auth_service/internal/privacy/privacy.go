@@ -0,0 +1,198 @@
+package privacy
+
+import (
+	"auth_service/internal/config"
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const batchSize = 10
+
+// Orchestrator проводит запросы на выгрузку и удаление данных по всем сервисам.
+// Запрос и прогресс по каждому сервису хранятся в Postgres, поэтому после рестарта
+// или ошибки сервиса работа продолжается с незавершенных шагов.
+type Orchestrator struct {
+	cfg          config.PrivacyConfig
+	stor         *storage.Storage
+	participants []Participant
+	auth         Participant
+	logger       *zap.Logger
+}
+
+func New(cfg config.PrivacyConfig, stor *storage.Storage, auth Participant, logger *zap.Logger) *Orchestrator {
+	participants := make([]Participant, 0, len(cfg.Participants))
+	for _, p := range cfg.Participants {
+		participants = append(participants, NewHTTPParticipant(p.Name, p.URL, cfg.RequestTimeout))
+	}
+
+	return &Orchestrator{
+		cfg:          cfg,
+		stor:         stor,
+		participants: participants,
+		auth:         auth,
+		logger:       logger,
+	}
+}
+
+// Request ставит запрос в очередь, выполнит его Run
+func (o *Orchestrator) Request(userId int64, kind string) (int64, error) {
+	services := make([]string, 0, len(o.participants)+1)
+	for _, p := range o.participants {
+		services = append(services, p.Name())
+	}
+	services = append(services, o.auth.Name())
+
+	id, err := o.stor.CreatePrivacyRequest(userId, kind, services)
+	if err != nil {
+		return 0, err
+	}
+
+	o.logger.Info("privacy request created",
+		zap.Int64("request_id", id),
+		zap.Int64("user_id", userId),
+		zap.String("kind", kind))
+
+	return id, nil
+}
+
+func (o *Orchestrator) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.processPending(ctx)
+			o.purgeExpiredExports()
+		}
+	}
+}
+
+func (o *Orchestrator) processPending(ctx context.Context) {
+	reqs, err := o.stor.PendingPrivacyRequests(batchSize)
+	if err != nil {
+		o.logger.Error("error getting pending privacy requests", zap.Error(err))
+		return
+	}
+
+	for _, req := range reqs {
+		if err := o.process(ctx, req); err != nil {
+			o.logger.Error("error processing privacy request",
+				zap.Int64("request_id", req.ID),
+				zap.Error(err))
+		}
+	}
+}
+
+func (o *Orchestrator) process(ctx context.Context, req models.PrivacyRequest) error {
+	_, steps, err := o.stor.GetPrivacyRequest(req.ID)
+	if err != nil {
+		return err
+	}
+
+	status := make(map[string]string, len(steps))
+	for _, step := range steps {
+		status[step.Service] = step.Status
+	}
+
+	for _, p := range o.participants {
+		if status[p.Name()] == models.PrivacyStatusPending {
+			status[p.Name()] = o.runStep(ctx, req, p)
+		}
+	}
+
+	othersDone := true
+	for service, st := range status {
+		if service == o.auth.Name() {
+			continue
+		}
+		if st == models.PrivacyStatusFailed {
+			return o.stor.FinishPrivacyRequest(req.ID, models.PrivacyStatusFailed)
+		}
+		if st != models.PrivacyStatusDone {
+			othersDone = false
+		}
+	}
+
+	// учетная запись удаляется только после того, как остальные сервисы обезличили данные
+	if !othersDone {
+		return nil
+	}
+
+	if status[o.auth.Name()] == models.PrivacyStatusPending {
+		status[o.auth.Name()] = o.runStep(ctx, req, o.auth)
+	}
+
+	switch status[o.auth.Name()] {
+	case models.PrivacyStatusDone:
+		// выгрузки хранят те же персональные данные, после удаления их тоже не должно остаться
+		if req.Kind == models.PrivacyKindErasure {
+			if err := o.stor.PurgeExportData(req.UserID); err != nil {
+				return fmt.Errorf("error purging exports of erased user: %w", err)
+			}
+		}
+		o.logger.Info("privacy request completed", zap.Int64("request_id", req.ID))
+		return o.stor.FinishPrivacyRequest(req.ID, models.PrivacyStatusDone)
+	case models.PrivacyStatusFailed:
+		return o.stor.FinishPrivacyRequest(req.ID, models.PrivacyStatusFailed)
+	}
+
+	return nil
+}
+
+// purgeExpiredExports удаляет выгрузки старше ExportRetention
+func (o *Orchestrator) purgeExpiredExports() {
+	purged, err := o.stor.PurgeExpiredExportData(time.Now().Add(-o.cfg.ExportRetention))
+	if err != nil {
+		o.logger.Error("error purging expired exports", zap.Error(err))
+		return
+	}
+
+	if purged > 0 {
+		o.logger.Info("expired exports purged", zap.Int64("steps", purged))
+	}
+}
+
+// runStep выполняет шаг в одном сервисе и возвращает его новый статус
+func (o *Orchestrator) runStep(ctx context.Context, req models.PrivacyRequest, p Participant) string {
+	ctx, cancel := context.WithTimeout(ctx, o.cfg.RequestTimeout)
+	defer cancel()
+
+	var data json.RawMessage
+	var err error
+	switch req.Kind {
+	case models.PrivacyKindExport:
+		data, err = p.Export(ctx, req.UserID)
+	case models.PrivacyKindErasure:
+		err = p.Erase(ctx, req.UserID)
+	default:
+		err = fmt.Errorf("unknown privacy request kind %q", req.Kind)
+	}
+
+	if err != nil {
+		o.logger.Warn("privacy request step failed",
+			zap.Int64("request_id", req.ID),
+			zap.String("service", p.Name()),
+			zap.Error(err))
+		if err := o.stor.FailPrivacyStep(req.ID, p.Name(), err, o.cfg.MaxAttempts); err != nil {
+			o.logger.Error("error saving privacy step failure", zap.Error(err))
+		}
+		// точный статус прочитаем на следующем проходе, сейчас считаем шаг незавершенным
+		return ""
+	}
+
+	if err := o.stor.CompletePrivacyStep(req.ID, p.Name(), data); err != nil {
+		o.logger.Error("error saving privacy step", zap.Error(err))
+		return ""
+	}
+
+	return models.PrivacyStatusDone
+}
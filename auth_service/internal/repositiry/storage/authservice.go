@@ -14,7 +14,7 @@ import (
 	"github.com/golang-migrate/migrate/database/postgres"
 	_ "github.com/golang-migrate/migrate/source/file"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
@@ -31,6 +31,10 @@ type Storage struct {
 func New(config *config.Config, logger *zap.Logger) (*Storage, error) {
 	cfg := config.StorageCfg
 
+	if err := ensureDatabase(cfg, logger); err != nil {
+		return nil, err
+	}
+
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable", cfg.Host, cfg.Username, cfg.Password, cfg.DBName, cfg.Port)
 
 	db, err := sqlx.Connect("postgres", dsn)
@@ -67,6 +71,34 @@ func New(config *config.Config, logger *zap.Logger) (*Storage, error) {
 
 }
 
+// ensureDatabase создает базу сервиса, если ее нет. Скрипт init-db.sh выполняется только
+// на пустом томе Postgres, а на томе, оставшемся от сервиса auth, базы identity еще нет.
+func ensureDatabase(cfg config.StorageConfig, logger *zap.Logger) error {
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=postgres port=%s sslmode=disable", cfg.Host, cfg.Username, cfg.Password, cfg.Port)
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		return fmt.Errorf("unable to connect to db: %w", err)
+	}
+	defer db.Close()
+
+	var exists bool
+	if err := db.Get(&exists, "SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)", cfg.DBName); err != nil {
+		return fmt.Errorf("error checking database %s: %w", cfg.DBName, err)
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err := db.Exec("CREATE DATABASE " + pq.QuoteIdentifier(cfg.DBName)); err != nil {
+		return fmt.Errorf("error creating database %s: %w", cfg.DBName, err)
+	}
+
+	logger.Info("database created", zap.String("name", cfg.DBName))
+
+	return nil
+}
+
 func (s *Storage) GetUserById(userId int64) (models.User, error) {
 	tx, err := s.DB.Beginx()
 	if err != nil {
@@ -166,10 +198,14 @@ func (s *Storage) addNewUser(tx *sqlx.Tx, newUser models.User) (id int64, err er
 		return -1, fmt.Errorf("select exists error: %w", err)
 	}
 	if exists {
-		return -1, fmt.Errorf("user already exists")
+		return -1, ErrUserExist
+	}
+
+	if newUser.Role == "" {
+		newUser.Role = models.RoleCustomer
 	}
 
-	_, err = tx.Exec("INSERT INTO users (email, username, passhash) VALUES ($1, $2, $3)", newUser.Email, newUser.Username, newUser.PassHash)
+	_, err = tx.Exec("INSERT INTO users (email, username, passhash, role) VALUES ($1, $2, $3, $4)", newUser.Email, newUser.Username, newUser.PassHash, newUser.Role)
 	if err != nil {
 		return -1, err
 	}
@@ -209,11 +245,8 @@ func (s *Storage) UserRole(username string) (string, error) {
 }
 
 func (s *Storage) IsAdmin(userId int64) (bool, error) {
-	type req struct {
-		Username string `db:"username"`
-	}
-	var user req
-	err := s.DB.Get(&user, "SELECT username FROM admins WHERE id = $1", userId)
+	var role string
+	err := s.DB.Get(&role, "SELECT role FROM users WHERE id = $1", userId)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			slog.Info("user is not an admin")
@@ -222,7 +255,7 @@ func (s *Storage) IsAdmin(userId int64) (bool, error) {
 		return false, err
 	}
 
-	return true, nil
+	return role == models.RoleAdmin, nil
 }
 
 func (s *Storage) CheckUser(username, email string) error {
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"auth_service/internal/models"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// GetUserByIdentity ищет пользователя, привязанного к аккаунту provider/subject
+func (s *Storage) GetUserByIdentity(provider, subject string) (models.User, error) {
+	var user models.User
+	err := s.DB.Get(&user, `
+    SELECT users.* FROM users
+    JOIN user_identities ON user_identities.user_id = users.id
+    WHERE user_identities.provider = $1 AND user_identities.subject = $2`,
+		provider, subject,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, ErrUserNotFound
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *Storage) GetUserIdentities(userId int64) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := s.DB.Select(&identities, "SELECT * FROM user_identities WHERE user_id = $1 ORDER BY id", userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+func (s *Storage) LinkIdentity(identity models.UserIdentity) error {
+	_, err := s.DB.Exec(`
+    INSERT INTO user_identities (user_id, provider, subject, email)
+    VALUES ($1, $2, $3, $4)`,
+		identity.UserID, identity.Provider, identity.Subject, identity.Email,
+	)
+
+	return err
+}
+
+// AddUserWithIdentity создает пользователя, пришедшего через внешнего провайдера, и сразу привязывает аккаунт.
+// Почта считается подтвержденной, если ее подтвердил провайдер.
+func (s *Storage) AddUserWithIdentity(newUser models.User, identity models.UserIdentity) (id int64, err error) {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return -1, fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	id, err = s.addNewUser(tx, newUser)
+	if err != nil {
+		return -1, err
+	}
+
+	_, err = tx.Exec("UPDATE users SET email_verified = $1 WHERE id = $2", newUser.EmailVerified, id)
+	if err != nil {
+		return -1, err
+	}
+
+	_, err = tx.Exec(`
+    INSERT INTO user_identities (user_id, provider, subject, email)
+    VALUES ($1, $2, $3, $4)`,
+		id, identity.Provider, identity.Subject, identity.Email,
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// UsernameTaken нужен, чтобы подобрать свободный логин для пользователя внешнего провайдера
+func (s *Storage) UsernameTaken(username string) (bool, error) {
+	var exists bool
+	err := s.DB.Get(&exists, "SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)", username)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"auth_service/internal/models"
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrClientNotFound = errors.New("oauth client not found")
+	ErrCodeInvalid    = errors.New("authorization code is invalid or expired")
+
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+)
+
+func (s *Storage) CreateOAuthClient(client models.OAuthClient) error {
+	_, err := s.DB.Exec(`
+    INSERT INTO oauth_clients (client_id, client_secret_hash, name, redirect_uris, is_public)
+    VALUES ($1, $2, $3, $4, $5)`,
+		client.ClientID, client.ClientSecretHash, client.Name, client.RedirectURIs, client.IsPublic,
+	)
+
+	return err
+}
+
+func (s *Storage) GetOAuthClient(clientId string) (models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := s.DB.Get(&client, "SELECT * FROM oauth_clients WHERE client_id = $1", clientId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OAuthClient{}, ErrClientNotFound
+		}
+		return models.OAuthClient{}, err
+	}
+
+	return client, nil
+}
+
+func (s *Storage) SaveOAuthCode(code models.OAuthCode) error {
+	_, err := s.DB.Exec(`
+    INSERT INTO oauth_codes (code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, auth_time, expires_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		code.CodeHash, code.ClientID, code.UserID, code.RedirectURI, code.Scope, code.Nonce, code.CodeChallenge, code.AuthTime, code.ExpiresAt,
+	)
+
+	return err
+}
+
+// ConsumeOAuthCode гасит код. Код одноразовый: повторный обмен дает ErrCodeInvalid.
+func (s *Storage) ConsumeOAuthCode(codeHash, clientId string) (models.OAuthCode, error) {
+	var code models.OAuthCode
+	err := s.DB.Get(&code, `
+    UPDATE oauth_codes SET used_at = CURRENT_TIMESTAMP
+    WHERE code_hash = $1 AND client_id = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+    RETURNING *`,
+		codeHash, clientId,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OAuthCode{}, ErrCodeInvalid
+		}
+		return models.OAuthCode{}, err
+	}
+
+	return code, nil
+}
+
+func (s *Storage) SaveOAuthRefreshToken(token models.OAuthRefreshToken) error {
+	_, err := s.DB.Exec(`
+    INSERT INTO oauth_refresh_tokens (token_hash, client_id, user_id, scope, expires_at)
+    VALUES ($1, $2, $3, $4, $5)`,
+		token.TokenHash, token.ClientID, token.UserID, token.Scope, token.ExpiresAt,
+	)
+
+	return err
+}
+
+// ConsumeOAuthRefreshToken гасит refresh токен клиента clientId. Токен другого клиента,
+// истекший или уже обмененный дает ErrRefreshTokenInvalid.
+func (s *Storage) ConsumeOAuthRefreshToken(tokenHash, clientId string) (models.OAuthRefreshToken, error) {
+	var token models.OAuthRefreshToken
+	err := s.DB.Get(&token, `
+    DELETE FROM oauth_refresh_tokens
+    WHERE token_hash = $1 AND client_id = $2 AND expires_at > CURRENT_TIMESTAMP
+    RETURNING *`,
+		tokenHash, clientId,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OAuthRefreshToken{}, ErrRefreshTokenInvalid
+		}
+		return models.OAuthRefreshToken{}, err
+	}
+
+	return token, nil
+}
+
+// RevokeOAuthRefreshTokens отзывает refresh токены пользователя у всех клиентов
+func (s *Storage) RevokeOAuthRefreshTokens(userId int64) error {
+	_, err := s.DB.Exec("DELETE FROM oauth_refresh_tokens WHERE user_id = $1", userId)
+
+	return err
+}
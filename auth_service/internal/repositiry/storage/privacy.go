@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"auth_service/internal/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrPrivacyRequestNotFound = errors.New("privacy request not found")
+
+// CreatePrivacyRequest создает запрос и по шагу на каждый сервис
+func (s *Storage) CreatePrivacyRequest(userId int64, kind string, services []string) (id int64, err error) {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	err = tx.Get(&id, "INSERT INTO privacy_requests (user_id, kind) VALUES ($1, $2) RETURNING id", userId, kind)
+	if err != nil {
+		return 0, fmt.Errorf("error creating privacy request: %w", err)
+	}
+
+	for _, service := range services {
+		_, err = tx.Exec("INSERT INTO privacy_request_steps (request_id, service) VALUES ($1, $2)", id, service)
+		if err != nil {
+			return 0, fmt.Errorf("error creating privacy request step: %w", err)
+		}
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetPrivacyRequest(id int64) (models.PrivacyRequest, []models.PrivacyStep, error) {
+	var req models.PrivacyRequest
+	err := s.DB.Get(&req, "SELECT * FROM privacy_requests WHERE id = $1", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PrivacyRequest{}, nil, ErrPrivacyRequestNotFound
+		}
+		return models.PrivacyRequest{}, nil, err
+	}
+
+	var steps []models.PrivacyStep
+	err = s.DB.Select(&steps, "SELECT * FROM privacy_request_steps WHERE request_id = $1 ORDER BY service", id)
+	if err != nil {
+		return models.PrivacyRequest{}, nil, err
+	}
+
+	return req, steps, nil
+}
+
+func (s *Storage) PendingPrivacyRequests(limit int) ([]models.PrivacyRequest, error) {
+	var reqs []models.PrivacyRequest
+	err := s.DB.Select(&reqs, "SELECT * FROM privacy_requests WHERE status = $1 ORDER BY id LIMIT $2", models.PrivacyStatusPending, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return reqs, nil
+}
+
+func (s *Storage) CompletePrivacyStep(requestId int64, service string, data []byte) error {
+	_, err := s.DB.Exec(`
+    UPDATE privacy_request_steps
+    SET status = $1, attempts = attempts + 1, last_error = '', data = $2, updated_at = CURRENT_TIMESTAMP
+    WHERE request_id = $3 AND service = $4`,
+		models.PrivacyStatusDone, data, requestId, service,
+	)
+
+	return err
+}
+
+// FailPrivacyStep учитывает неудачную попытку; после maxAttempts шаг считается проваленным
+func (s *Storage) FailPrivacyStep(requestId int64, service string, stepErr error, maxAttempts int) error {
+	_, err := s.DB.Exec(`
+    UPDATE privacy_request_steps
+    SET attempts = attempts + 1,
+        last_error = $1,
+        status = CASE WHEN attempts + 1 >= $2 THEN $3 ELSE status END,
+        updated_at = CURRENT_TIMESTAMP
+    WHERE request_id = $4 AND service = $5`,
+		stepErr.Error(), maxAttempts, models.PrivacyStatusFailed, requestId, service,
+	)
+
+	return err
+}
+
+// PurgeExportData удаляет готовые выгрузки пользователя, например после удаления его данных
+func (s *Storage) PurgeExportData(userId int64) error {
+	_, err := s.DB.Exec(`
+    UPDATE privacy_request_steps
+    SET data = NULL, updated_at = CURRENT_TIMESTAMP
+    WHERE data IS NOT NULL
+      AND request_id IN (SELECT id FROM privacy_requests WHERE user_id = $1 AND kind = $2)`,
+		userId, models.PrivacyKindExport,
+	)
+
+	return err
+}
+
+// PurgeExpiredExportData удаляет выгрузки запросов, завершенных раньше before, и возвращает число очищенных шагов
+func (s *Storage) PurgeExpiredExportData(before time.Time) (int64, error) {
+	res, err := s.DB.Exec(`
+    UPDATE privacy_request_steps
+    SET data = NULL, updated_at = CURRENT_TIMESTAMP
+    WHERE data IS NOT NULL
+      AND request_id IN (SELECT id FROM privacy_requests WHERE kind = $1 AND completed_at < $2)`,
+		models.PrivacyKindExport, before,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (s *Storage) FinishPrivacyRequest(id int64, status string) error {
+	_, err := s.DB.Exec("UPDATE privacy_requests SET status = $1, completed_at = CURRENT_TIMESTAMP WHERE id = $2", status, id)
+
+	return err
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"fmt"
+)
+
+func (s *Storage) UpdateProfile(userId int64, fullName, phone, address string) error {
+	res, err := s.DB.Exec(`
+    UPDATE users SET full_name = $1, phone = $2, address = $3, updated_at = CURRENT_TIMESTAMP
+    WHERE id = $4`,
+		fullName, phone, address, userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+// ChangeEmail меняет почту и снимает отметку о ее подтверждении
+func (s *Storage) ChangeEmail(userId int64, email string) (err error) {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var exists bool
+	err = tx.Get(&exists, "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND id <> $2)", email, userId)
+	if err != nil {
+		return fmt.Errorf("select exists error: %w", err)
+	}
+	if exists {
+		return ErrUserExist
+	}
+
+	res, err := tx.Exec(`
+    UPDATE users SET email = $1, email_verified = FALSE, updated_at = CURRENT_TIMESTAMP
+    WHERE id = $2`,
+		email, userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+// DeleteUser удаляет пользователя, токены и коды восстановления удаляются каскадно
+func (s *Storage) DeleteUser(userId int64) error {
+	res, err := s.DB.Exec("DELETE FROM users WHERE id = $1", userId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"auth_service/internal/models"
+	"auth_service/internal/onboarding"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrSellerProfileNotFound = errors.New("seller profile not found")
+
+// AddSeller создает продавца вместе с пустой анкетой в статусе draft
+func (s *Storage) AddSeller(newUser models.User) (id int64, err error) {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return -1, fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	newUser.Role = models.RoleSeller
+	id, err = s.addNewUser(tx, newUser)
+	if err != nil {
+		return -1, err
+	}
+
+	_, err = tx.Exec("INSERT INTO seller_profiles (user_id) VALUES ($1)", id)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetSellerProfile(userId int64) (models.SellerProfile, error) {
+	var profile models.SellerProfile
+	err := s.DB.Get(&profile, "SELECT * FROM seller_profiles WHERE user_id = $1", userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SellerProfile{}, ErrSellerProfileNotFound
+		}
+		return models.SellerProfile{}, err
+	}
+
+	return profile, nil
+}
+
+// UpdateSellerProfile сохраняет реквизиты. Правка отклоненной анкеты возвращает ее в черновик.
+func (s *Storage) UpdateSellerProfile(update models.SellerProfile) (profile models.SellerProfile, err error) {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return models.SellerProfile{}, fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	current, err := lockSellerProfile(tx, update.UserID)
+	if err != nil {
+		return models.SellerProfile{}, err
+	}
+
+	if err = onboarding.Editable(current.Status); err != nil {
+		return models.SellerProfile{}, err
+	}
+
+	if current.Status == models.SellerStatusRejected {
+		err = addStatusChange(tx, update.UserID, current.Status, models.SellerStatusDraft, update.UserID, "")
+		if err != nil {
+			return models.SellerProfile{}, err
+		}
+	}
+
+	err = tx.Get(&profile, `
+    UPDATE seller_profiles SET
+        status = $2,
+        legal_name = $3,
+        legal_form = $4,
+        tax_id = $5,
+        registration_number = $6,
+        legal_address = $7,
+        bank_account = $8,
+        bank_bic = $9,
+        updated_at = CURRENT_TIMESTAMP
+    WHERE user_id = $1
+    RETURNING *`,
+		update.UserID, models.SellerStatusDraft, update.LegalName, update.LegalForm, update.TaxID,
+		update.RegistrationNumber, update.LegalAddress, update.BankAccount, update.BankBIC,
+	)
+	if err != nil {
+		return models.SellerProfile{}, err
+	}
+
+	return profile, nil
+}
+
+// ChangeSellerStatus переводит анкету в статус to по правилам onboarding. actorId - кто перевел
+// (сам продавец или администратор), comment для отклонения сохраняется как причина.
+func (s *Storage) ChangeSellerStatus(userId int64, to string, actorId int64, comment string) (profile models.SellerProfile, err error) {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return models.SellerProfile{}, fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	current, err := lockSellerProfile(tx, userId)
+	if err != nil {
+		return models.SellerProfile{}, err
+	}
+
+	// продавец меняет статус своей анкеты сам, остальные изменения делает администратор
+	actor := onboarding.ActorAdmin
+	if actorId == userId {
+		actor = onboarding.ActorSeller
+	}
+	if err = onboarding.Transition(current.Status, to, actor); err != nil {
+		return models.SellerProfile{}, err
+	}
+
+	if to == models.SellerStatusSubmitted {
+		if err = onboarding.Complete(current); err != nil {
+			return models.SellerProfile{}, err
+		}
+	}
+
+	reviewed := to == models.SellerStatusApproved || to == models.SellerStatusRejected
+	rejectionReason := ""
+	if to == models.SellerStatusRejected {
+		rejectionReason = comment
+	}
+
+	err = tx.Get(&profile, `
+    UPDATE seller_profiles SET
+        status = $2,
+        rejection_reason = $3,
+        submitted_at = CASE WHEN $2 = 'submitted' THEN CURRENT_TIMESTAMP ELSE submitted_at END,
+        reviewed_by = CASE WHEN $4 THEN $5 ELSE reviewed_by END,
+        reviewed_at = CASE WHEN $4 THEN CURRENT_TIMESTAMP ELSE reviewed_at END,
+        updated_at = CURRENT_TIMESTAMP
+    WHERE user_id = $1
+    RETURNING *`,
+		userId, to, rejectionReason, reviewed, actorId,
+	)
+	if err != nil {
+		return models.SellerProfile{}, err
+	}
+
+	if err = addStatusChange(tx, userId, current.Status, to, actorId, comment); err != nil {
+		return models.SellerProfile{}, err
+	}
+
+	return profile, nil
+}
+
+// ListSellerApplications - анкеты в статусе status (все, если пусто), сначала давно отправленные
+func (s *Storage) ListSellerApplications(status string, limit, offset int) ([]models.SellerApplication, error) {
+	var applications []models.SellerApplication
+	err := s.DB.Select(&applications, `
+    SELECT seller_profiles.*, users.uuid, users.username, users.email
+    FROM seller_profiles
+    JOIN users ON users.id = seller_profiles.user_id
+    WHERE $1 = '' OR seller_profiles.status = $1
+    ORDER BY seller_profiles.submitted_at NULLS LAST, seller_profiles.user_id
+    LIMIT $2 OFFSET $3`,
+		status, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+func (s *Storage) GetSellerStatusHistory(userId int64) ([]models.SellerStatusChange, error) {
+	var history []models.SellerStatusChange
+	err := s.DB.Select(&history, "SELECT * FROM seller_status_history WHERE user_id = $1 ORDER BY id", userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func lockSellerProfile(tx *sqlx.Tx, userId int64) (models.SellerProfile, error) {
+	var profile models.SellerProfile
+	err := tx.Get(&profile, "SELECT * FROM seller_profiles WHERE user_id = $1 FOR UPDATE", userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SellerProfile{}, ErrSellerProfileNotFound
+		}
+		return models.SellerProfile{}, err
+	}
+
+	return profile, nil
+}
+
+func addStatusChange(tx *sqlx.Tx, userId int64, from, to string, actorId int64, comment string) error {
+	_, err := tx.Exec(`
+    INSERT INTO seller_status_history (user_id, from_status, to_status, actor_id, comment)
+    VALUES ($1, $2, $3, $4, $5)`,
+		userId, from, to, actorId, comment,
+	)
+
+	return err
+}
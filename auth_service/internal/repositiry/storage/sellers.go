@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"auth_service/internal/models"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// sellerMatch - условие каталога продавцов: $2 - строка поиска (пусто - все продавцы),
+// $3 - статус проверки, $4 - подстрока для ILIKE. Триграммный оператор % находит
+// логин, имя и город с опечатками, ILIKE - по части слова; оба используют индексы gin_trgm_ops.
+const sellerMatch = `
+    FROM users
+    JOIN seller_profiles ON seller_profiles.user_id = users.id
+    WHERE users.role = $1
+      AND seller_profiles.status = $3
+      AND ($2 = ''
+        OR users.username ILIKE $4 OR users.full_name ILIKE $4 OR users.city ILIKE $4
+        OR users.username % $2 OR users.full_name % $2 OR users.city % $2)`
+
+// sellerOrders - допустимые сортировки каталога, значение подставляется в запрос только отсюда.
+// Релевантность: совпадение с началом логина, затем наибольшее сходство с одним из полей.
+var sellerOrders = map[string]string{
+	models.SellerSortRelevance: `starts_with(lower(users.username), lower($2)) DESC,
+        GREATEST(similarity(users.username, $2), similarity(users.full_name, $2), similarity(users.city, $2)) DESC,
+        users.username`,
+	models.SellerSortUsername: "users.username",
+	models.SellerSortNewest:   "users.created_acc DESC, users.id DESC",
+	models.SellerSortOldest:   "users.created_acc, users.id",
+}
+
+// GetSellerByUUID ищет продавца по публичному идентификатору. Старые идентификаторы сервиса auth,
+// которые при переносе достались объединенному аккаунту, находятся через legacy_user_ids.
+func (s *Storage) GetSellerByUUID(id string) (models.User, error) {
+	var user models.User
+	err := s.DB.Get(&user, `
+    SELECT * FROM users
+    WHERE role = $1 AND (uuid = $2 OR id = (SELECT user_id FROM legacy_user_ids WHERE legacy_id = $2))`,
+		models.RoleSeller, id,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, ErrUserNotFound
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// SearchSellersByUsername ищет продавца по точному логину. Поиск публичный, как и каталог,
+// поэтому находятся только одобренные продавцы.
+func (s *Storage) SearchSellersByUsername(username string) ([]models.User, error) {
+	var sellers []models.User
+	err := s.DB.Select(&sellers, `
+    SELECT users.* FROM users
+    JOIN seller_profiles ON seller_profiles.user_id = users.id
+    WHERE users.role = $1 AND users.username = $2 AND seller_profiles.status = $3`,
+		models.RoleSeller, username, models.SellerStatusApproved,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return sellers, nil
+}
+
+// ListSellers - страница каталога продавцов и общее число найденных. Каталог публичный,
+// поэтому в нем только одобренные продавцы; анкеты в других статусах видит администратор.
+func (s *Storage) ListSellers(filter models.SellerFilter) ([]models.SellerDirectoryEntry, int64, error) {
+	order, ok := sellerOrders[filter.Sort]
+	if !ok {
+		order = sellerOrders[models.SellerSortNewest]
+	}
+
+	query := strings.TrimSpace(filter.Query)
+	pattern := "%" + escapeLike(query) + "%"
+
+	var total int64
+	err := s.DB.Get(&total, "SELECT COUNT(*)"+sellerMatch,
+		models.RoleSeller, query, models.SellerStatusApproved, pattern,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sellers := []models.SellerDirectoryEntry{}
+	if total == 0 {
+		return sellers, 0, nil
+	}
+
+	err = s.DB.Select(&sellers, `
+    SELECT users.uuid, users.username, users.full_name, users.city, users.created_acc,
+        seller_profiles.status AS verification_status`+sellerMatch+`
+    ORDER BY `+order+`
+    LIMIT $5 OFFSET $6`,
+		models.RoleSeller, query, models.SellerStatusApproved, pattern, filter.Limit, filter.Offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return sellers, total, nil
+}
+
+// escapeLike экранирует спецсимволы LIKE, чтобы строка поиска сравнивалась буквально
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"auth_service/internal/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrTokenInvalid = errors.New("token is invalid or expired")
+
+// SaveUserToken сохраняет хеш нового одноразового токена, старые неиспользованные токены того же назначения удаляются
+func (s *Storage) SaveUserToken(userId int64, purpose, tokenHash string, expiresAt time.Time) (err error) {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.Exec("DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL", userId, purpose)
+	if err != nil {
+		return fmt.Errorf("error removing old tokens: %w", err)
+	}
+
+	_, err = tx.Exec("INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4)",
+		userId, purpose, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("error saving token: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeUserToken помечает токен использованным и возвращает id его владельца.
+// Повторное использование, истекший срок и чужое назначение дают ErrTokenInvalid.
+func (s *Storage) ConsumeUserToken(purpose, tokenHash string) (int64, error) {
+	var userId int64
+	err := s.DB.Get(&userId, `
+    UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP
+    WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+    RETURNING user_id`,
+		tokenHash, purpose,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrTokenInvalid
+		}
+		return 0, err
+	}
+
+	return userId, nil
+}
+
+func (s *Storage) GetUserByEmail(email string) (models.User, error) {
+	var user models.User
+	err := s.DB.Get(&user, "SELECT * FROM users WHERE email=$1", email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, ErrUserNotFound
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *Storage) SetEmailVerified(userId int64) error {
+	res, err := s.DB.Exec("UPDATE users SET email_verified = TRUE WHERE id = $1", userId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (s *Storage) UpdatePassword(userId int64, passHash string) error {
+	res, err := s.DB.Exec("UPDATE users SET passhash = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", passHash, userId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
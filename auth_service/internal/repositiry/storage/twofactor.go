@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// SetTOTPSecret сохраняет секрет, который еще нужно подтвердить кодом. 2FA при этом не включается.
+func (s *Storage) SetTOTPSecret(userId int64, secret string) error {
+	res, err := s.DB.Exec("UPDATE users SET totp_secret = $1, totp_enabled = FALSE, totp_last_step = 0 WHERE id = $2", secret, userId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+// EnableTOTP включает 2FA и заменяет коды восстановления на новые
+func (s *Storage) EnableTOTP(userId int64, recoveryCodeHashes []string) (err error) {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.Exec("UPDATE users SET totp_enabled = TRUE WHERE id = $1", userId)
+	if err != nil {
+		return fmt.Errorf("error enabling totp: %w", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userId)
+	if err != nil {
+		return fmt.Errorf("error removing old recovery codes: %w", err)
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		_, err = tx.Exec("INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)", userId, hash)
+		if err != nil {
+			return fmt.Errorf("error saving recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Storage) DisableTOTP(userId int64) (err error) {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.Exec("UPDATE users SET totp_secret = '', totp_enabled = FALSE, totp_last_step = 0 WHERE id = $1", userId)
+	if err != nil {
+		return fmt.Errorf("error disabling totp: %w", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userId)
+	if err != nil {
+		return fmt.Errorf("error removing recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep запоминает интервал принятого кода. false - код этого или более позднего интервала уже использовали.
+func (s *Storage) UseTOTPStep(userId, step int64) (bool, error) {
+	res, err := s.DB.Exec("UPDATE users SET totp_last_step = $1 WHERE id = $2 AND totp_last_step < $1", step, userId)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// UseRecoveryCode гасит код восстановления. false - кода нет или он уже использован.
+func (s *Storage) UseRecoveryCode(userId int64, codeHash string) (bool, error) {
+	res, err := s.DB.Exec("UPDATE recovery_codes SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL", userId, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
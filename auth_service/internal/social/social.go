@@ -0,0 +1,138 @@
+package social
+
+import (
+	"auth_service/internal/config"
+	"auth_service/internal/tokens"
+	"auth_service/pkg/storage/inmem"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	ErrInvalidState    = errors.New("login state is invalid or expired")
+	ErrExchangeFailed  = errors.New("code exchange failed")
+	ErrInvalidIDToken  = errors.New("invalid id token")
+)
+
+// Claims - данные пользователя от провайдера, проверенные по подписи id_token
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Name              string
+}
+
+// loginState сохраняется между редиректом к провайдеру и возвратом пользователя
+type loginState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// Service ведет вход через внешних провайдеров из конфига: формирует ссылку на вход
+// и по возвращенному коду получает проверенные claims пользователя.
+type Service struct {
+	providers map[string]*Provider
+	states    inmem.SocialStateStorage
+	stateTTL  time.Duration
+	logger    *zap.Logger
+}
+
+func New(cfg config.SocialConfig, states inmem.SocialStateStorage, logger *zap.Logger) *Service {
+	providers := make(map[string]*Provider, len(cfg.Providers))
+	for _, providerCfg := range cfg.Providers {
+		providers[providerCfg.Name] = newProvider(providerCfg)
+		logger.Info("social login provider configured",
+			zap.String("provider", providerCfg.Name),
+			zap.String("issuer", providerCfg.Issuer))
+	}
+
+	return &Service{
+		providers: providers,
+		states:    states,
+		stateTTL:  cfg.StateTTL,
+		logger:    logger,
+	}
+}
+
+// Start возвращает адрес входа у провайдера и state, по которому потом завершается вход
+func (s *Service) Start(ctx context.Context, providerName string) (authURL, state string, err error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	state, _, err = tokens.Generate()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, _, err := tokens.Generate()
+	if err != nil {
+		return "", "", err
+	}
+	codeVerifier, _, err := tokens.Generate()
+	if err != nil {
+		return "", "", err
+	}
+
+	value, err := json.Marshal(loginState{
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.states.Save(ctx, state, value, s.stateTTL); err != nil {
+		return "", "", err
+	}
+
+	sum := sha256.Sum256([]byte(codeVerifier))
+	authURL, err = provider.authURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(sum[:]))
+	if err != nil {
+		return "", "", fmt.Errorf("provider %s: %w", providerName, err)
+	}
+
+	return authURL, state, nil
+}
+
+// Complete гасит state и обменивает код провайдера на claims пользователя
+func (s *Service) Complete(ctx context.Context, providerName, code, state string) (Claims, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return Claims{}, ErrUnknownProvider
+	}
+
+	value, err := s.states.Pop(ctx, state)
+	if err != nil {
+		if errors.Is(err, inmem.ErrStateNotFound) {
+			return Claims{}, ErrInvalidState
+		}
+		return Claims{}, err
+	}
+
+	var saved loginState
+	if err := json.Unmarshal(value, &saved); err != nil {
+		return Claims{}, fmt.Errorf("error decoding login state: %w", err)
+	}
+	if saved.Provider != providerName {
+		return Claims{}, ErrInvalidState
+	}
+
+	claims, err := provider.exchange(ctx, code, saved.CodeVerifier, saved.Nonce)
+	if err != nil {
+		return Claims{}, fmt.Errorf("provider %s: %w", providerName, err)
+	}
+
+	return claims, nil
+}
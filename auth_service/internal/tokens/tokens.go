@@ -0,0 +1,54 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const tokenSize = 32
+
+// Generate создает случайный одноразовый токен. Пользователю уходит token, в базе хранится только hash.
+func Generate() (token, hash string, err error) {
+	buf := make([]byte, tokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("error generating token: %w", err)
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buf)
+
+	return token, Hash(token), nil
+}
+
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateRecoveryCode создает код восстановления 2FA вида xxxxx-xxxxx
+func GenerateRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating recovery code: %w", err)
+	}
+
+	code := strings.ToLower(recoveryEncoding.EncodeToString(buf))[:10]
+
+	return code[:5] + "-" + code[5:], nil
+}
+
+// NormalizeRecoveryCode приводит введенный пользователем код к виду, в котором он хешировался
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if len(code) != 10 {
+		return code
+	}
+
+	return code[:5] + "-" + code[5:]
+}
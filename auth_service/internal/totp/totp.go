@@ -0,0 +1,89 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Параметры по RFC 6238, их понимают Google Authenticator и аналоги
+const (
+	secretSize = 20
+	digits     = 6
+	period     = 30
+	// skew - сколько соседних интервалов принимаем из-за расхождения часов
+	skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateSecret() (string, error) {
+	buf := make([]byte, secretSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating totp secret: %w", err)
+	}
+
+	return encoding.EncodeToString(buf), nil
+}
+
+// URI возвращает otpauth ссылку для QR кода
+func URI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", digits))
+	params.Set("period", fmt.Sprintf("%d", period))
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+// Validate проверяет код и возвращает номер интервала, которому он соответствует.
+// Номер нужен, чтобы не принять один и тот же код дважды.
+func Validate(secret, code string, now time.Time) (int64, bool) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != digits {
+		return 0, false
+	}
+
+	step := now.Unix() / period
+	for i := int64(-skew); i <= skew; i++ {
+		if hmac.Equal([]byte(generate(key, step+i)), []byte(code)) {
+			return step + i, true
+		}
+	}
+
+	return 0, false
+}
+
+// Code возвращает код интервала, в который попадает now, как его покажет приложение-аутентификатор
+func Code(secret string, now time.Time) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid totp secret: %w", err)
+	}
+
+	return generate(key, now.Unix()/period), nil
+}
+
+func generate(key []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", digits, value%1_000_000)
+}
@@ -0,0 +1,136 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret - ключ "12345678901234567890" из тестовых векторов RFC 6238 в base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// в RFC коды из 8 цифр, приложения показывают последние 6
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / period
+
+	tests := []struct {
+		name   string
+		codeAt time.Time
+		ok     bool
+		step   int64
+	}{
+		{"current interval", now, true, step},
+		{"previous interval", now.Add(-period * time.Second), true, step - 1},
+		{"next interval", now.Add(period * time.Second), true, step + 1},
+		{"two intervals ago", now.Add(-2 * period * time.Second), false, 0},
+		{"two intervals ahead", now.Add(2 * period * time.Second), false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, tt.codeAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := Validate(rfcSecret, code, now)
+			if ok != tt.ok || got != tt.step {
+				t.Errorf("Validate() = %d, %v, want %d, %v", got, ok, tt.step, tt.ok)
+			}
+		})
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, err := Code(rfcSecret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+	}{
+		{"wrong code", rfcSecret, "000000"},
+		{"short code", rfcSecret, code[:5]},
+		{"long code", rfcSecret, code + "0"},
+		{"other secret", "JBSWY3DPEHPK3PXP", code},
+		{"invalid secret", "not base32!", code},
+		{"empty secret", "", code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := Validate(tt.secret, tt.code, now); ok {
+				t.Errorf("Validate(%q, %q) accepted the code", tt.secret, tt.code)
+			}
+		})
+	}
+
+	// секрет могут ввести строчными буквами
+	if _, ok := Validate("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", code, now); !ok {
+		t.Error("lowercase secret is rejected")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := encoding.DecodeString(first)
+	if err != nil || len(key) != secretSize {
+		t.Errorf("secret %q decodes to %d bytes (err: %v), want %d", first, len(key), err, secretSize)
+	}
+	if first == second {
+		t.Error("two secrets are equal")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("Web Shop", "alice", rfcSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Web Shop:alice" {
+		t.Errorf("uri = %s", uri)
+	}
+	want := map[string]string{"secret": rfcSecret, "issuer": "Web Shop", "algorithm": "SHA1", "digits": "6", "period": "30"}
+	for key, value := range want {
+		if got := uri.Query().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -1,11 +1,20 @@
 package grpc
 
 import (
+	"auth_service/internal/config"
 	"auth_service/internal/jwt"
+	"auth_service/internal/loginguard"
 	"auth_service/internal/models"
+	"auth_service/internal/privacy"
 	"auth_service/internal/repositiry/storage"
+	"auth_service/internal/social"
+	"auth_service/internal/validation"
+	"auth_service/pkg/events"
 	"auth_service/pkg/storage/inmem"
+	"errors"
 	"fmt"
+	"grpcsec"
+	"net"
 
 	//"auth_service/pkg/api"
 	"context"
@@ -13,17 +22,37 @@ import (
 
 	api "github.com/artemSorokin1/Auth-proto/protos/gen/protos/proto"
 	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 type AuthService struct {
 	api.UnimplementedAuthServiceServer
 	stor                *storage.Storage
 	refreshInMemStorage inmem.RefreshTokenStorage
+	sessions            inmem.SessionStorage
+	guard               *loginguard.Guard
+	events              events.Publisher
+	verificationCfg     config.VerificationConfig
+	twoFactorCfg        config.TwoFactorConfig
+	privacy             *privacy.Orchestrator
+	social              *social.Service
 }
 
 func (s *AuthService) Register(ctx context.Context, req *api.RegisterRequest) (*api.RegisterResponse, error) {
 	slog.Info("Register method called")
 
+	err := errors.Join(
+		validation.Username(req.GetUsername()),
+		validation.Email(req.GetEmail()),
+		validation.Password(req.GetPassword()),
+	)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	passHash, err := bcrypt.GenerateFromPassword([]byte(req.GetPassword()), bcrypt.DefaultCost)
 	if err != nil {
 		slog.Warn("error hashing password")
@@ -34,15 +63,21 @@ func (s *AuthService) Register(ctx context.Context, req *api.RegisterRequest) (*
 		Email:    req.GetEmail(),
 		Username: req.GetUsername(),
 		PassHash: string(passHash),
-		Role:     "user",
+		Role:     models.RoleCustomer,
 	}
 
 	id, err := s.stor.AddNewUser(user)
 	if err != nil || id == -1 {
-		slog.Warn("error adding new user", err)
+		slog.Warn("error adding new user", slog.Any("error", err))
 		return nil, err
 	}
 
+	user.ID = id
+	if err := s.sendUserToken(ctx, user, models.TokenPurposeEmailVerification); err != nil {
+		// пользователь уже создан, письмо можно запросить повторно через RequestEmailVerification
+		slog.Warn("error sending verification email", slog.String("error", err.Error()))
+	}
+
 	return &api.RegisterResponse{
 		UserId: id,
 	}, nil
@@ -52,43 +87,160 @@ func (s *AuthService) Register(ctx context.Context, req *api.RegisterRequest) (*
 func (s *AuthService) Login(ctx context.Context, req *api.LoginRequest) (*api.LoginResponse, error) {
 	slog.Info("Login method called")
 
-	user, err := s.stor.GetUser(req.GetUsername())
+	username := req.GetUsername()
+
+	if err := s.guard.Allow(ctx, username, clientIP(ctx)); err != nil {
+		var limitErr *loginguard.LimitError
+		if errors.As(err, &limitErr) {
+			slog.Warn("login rejected", slog.String("username", username), slog.String("reason", limitErr.Error()))
+			return nil, status.Error(codes.ResourceExhausted, limitErr.Error())
+		}
+		slog.Error("error checking login limits", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to check login limits")
+	}
+
+	user, err := s.stor.GetUser(username)
 	if err != nil {
+		if !errors.Is(err, storage.ErrUserNotFound) {
+			slog.Error("error getting user in login", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "unable to get user")
+		}
 		slog.Warn("user not found in login")
-		return nil, storage.ErrUserNotFound
+		return nil, s.loginFailed(ctx, username, "unknown_user")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PassHash), []byte(req.GetPassword())); err != nil {
 		slog.Warn("error comparing hashes")
-		return nil, storage.ErrUserNotFound
+		return nil, s.loginFailed(ctx, username, "wrong_password")
 	}
 
+	if err := s.guard.Success(ctx, username); err != nil {
+		slog.Warn("error resetting failed logins", slog.String("error", err.Error()))
+	}
+
+	return s.completeLogin(ctx, user)
+}
+
+// completeLogin - общая часть входа после проверки первого фактора (пароля или внешнего провайдера)
+func (s *AuthService) completeLogin(ctx context.Context, user models.User) (*api.LoginResponse, error) {
+	if s.verificationCfg.RequireVerifiedEmail && !user.EmailVerified {
+		slog.Warn("login with unverified email", slog.String("username", user.Username))
+		return nil, status.Error(codes.FailedPrecondition, "email is not verified")
+	}
+
+	if user.TOTPEnabled {
+		challengeToken, err := jwt.CreateChallengeToken(&user, s.twoFactorCfg.ChallengeTTL)
+		if err != nil {
+			slog.Warn("error creating challenge token")
+			return nil, status.Error(codes.Internal, "unable to create challenge token")
+		}
+
+		return &api.LoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		}, nil
+	}
+
+	accessToken, _, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.LoginResponse{
+		AccessToken: accessToken,
+	}, nil
+}
+
+// issueTokens создает пару токенов и сохраняет refresh токен в redis
+func (s *AuthService) issueTokens(ctx context.Context, user models.User) (string, string, error) {
 	accessToken, err := jwt.CreateAccessToken(&user)
 	if err != nil {
 		slog.Warn("error creating access token")
-		return nil, err
+		return "", "", status.Error(codes.Internal, "unable to create access token")
 	}
 	refreshToken, err := jwt.CreateRefreshToken(&user)
 	if err != nil {
 		slog.Warn("error creating refresh token")
-		return nil, err
+		return "", "", status.Error(codes.Internal, "unable to create refresh token")
 	}
 
-	err = s.refreshInMemStorage.SaveToken(context.Background(), user.ID, refreshToken)
+	err = s.refreshInMemStorage.SaveToken(ctx, user.ID, refreshToken)
 	if err != nil {
 		slog.Warn("error saving refresh token to redis")
-		return nil, fmt.Errorf("error saving refresh token to redis: %w", err)
+		return "", "", status.Error(codes.Internal, fmt.Sprintf("error saving refresh token to redis: %v", err))
 	}
 
-	return &api.LoginResponse{
-		AccessToken: accessToken,
+	return accessToken, refreshToken, nil
+}
+
+// loginFailed учитывает неудачу и возвращает одинаковую ошибку для неизвестного логина и неверного пароля
+func (s *AuthService) loginFailed(ctx context.Context, username, reason string) error {
+	if err := s.guard.Failure(ctx, username, reason); err != nil {
+		slog.Error("error registering failed login", slog.String("error", err.Error()))
+	}
+
+	return status.Error(codes.Unauthenticated, "invalid username or password")
+}
+
+func (s *AuthService) UnlockUser(ctx context.Context, req *api.UnlockUserRequest) (*api.UnlockUserResponse, error) {
+	slog.Info("UnlockUser method called")
+
+	isAdmin, err := s.isAdmin(req.GetAdminId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "unable to check admin rights")
+	}
+	if !isAdmin {
+		return nil, status.Error(codes.PermissionDenied, "only admins can unlock users")
+	}
+
+	if req.GetUsername() == "" {
+		return nil, status.Error(codes.InvalidArgument, "username is required")
+	}
+
+	if err := s.guard.Unlock(ctx, req.GetUsername()); err != nil {
+		slog.Error("error unlocking user", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to unlock user")
+	}
+
+	return &api.UnlockUserResponse{
+		IsSuccess: true,
 	}, nil
 }
 
+// clientIP берет адрес конечного клиента из метаданных шлюза delivery_service, иначе адрес соединения.
+// Метаданным других вызывающих не верим: иначе лимит попыток входа обходится подменой x-real-ip
+func clientIP(ctx context.Context) string {
+	if caller, _ := grpcsec.IdentityFromContext(ctx); caller != deliveryService {
+		return peerIP(ctx)
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ips := md.Get("x-real-ip"); len(ips) > 0 && ips[0] != "" {
+			return ips[0]
+		}
+	}
+
+	return peerIP(ctx)
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
 func (s *AuthService) IsAdmin(ctx context.Context, req *api.IsAdminRequest) (*api.IsAdminResponse, error) {
 	slog.Info("IsAdmin method called")
 
-	isAdmin, err := s.stor.IsAdmin(req.GetUserId())
+	isAdmin, err := s.isAdmin(req.GetUserId())
 	if err != nil {
 		return nil, err
 	}
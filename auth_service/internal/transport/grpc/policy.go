@@ -0,0 +1,25 @@
+package grpc
+
+import (
+	"grpcsec"
+
+	api "github.com/artemSorokin1/Auth-proto/protos/gen/protos/proto"
+	pb "github.com/artemSorokin1/sellers-grpc-api/gen/go/seller"
+)
+
+const (
+	deliveryService = "delivery_service"
+	sellersService  = "sellers_service"
+	productsService = "products_service"
+)
+
+// callersPolicy - какие сервисы могут вызывать методы AuthService (по CommonName сертификата).
+// Все пользовательские методы и методы администратора вызывает шлюз delivery_service.
+// Аккаунты продавцов запрашивают sellers_service (остальным сервисам карточки отдает он)
+// и products_service, который перед публикацией товара проверяет статус анкеты продавца.
+var callersPolicy = grpcsec.Policy{
+	"/" + api.AuthService_ServiceDesc.ServiceName + "/*":   {deliveryService},
+	api.AuthService_IsAdmin_FullMethodName:                 {deliveryService},
+	api.AuthService_UnlockUser_FullMethodName:              {deliveryService},
+	"/" + pb.SellersService_ServiceDesc.ServiceName + "/*": {sellersService, productsService},
+}
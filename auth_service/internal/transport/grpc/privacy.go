@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"context"
+	"errors"
+	"log/slog"
+
+	api "github.com/artemSorokin1/Auth-proto/protos/gen/protos/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *AuthService) RequestDataExport(ctx context.Context, req *api.RequestDataExportRequest) (*api.RequestDataExportResponse, error) {
+	slog.Info("RequestDataExport method called")
+
+	user, err := s.getUser(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.privacy.Request(user.ID, models.PrivacyKindExport)
+	if err != nil {
+		slog.Error("error creating export request", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to create export request")
+	}
+
+	return &api.RequestDataExportResponse{
+		RequestId: id,
+	}, nil
+}
+
+func (s *AuthService) RequestErasure(ctx context.Context, req *api.RequestErasureRequest) (*api.RequestErasureResponse, error) {
+	slog.Info("RequestErasure method called")
+
+	id, err := s.requestErasure(ctx, req.GetUserId(), req.GetPassword())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.RequestErasureResponse{
+		RequestId: id,
+	}, nil
+}
+
+func (s *AuthService) GetPrivacyRequest(ctx context.Context, req *api.GetPrivacyRequestRequest) (*api.GetPrivacyRequestResponse, error) {
+	slog.Info("GetPrivacyRequest method called")
+
+	privacyReq, steps, err := s.stor.GetPrivacyRequest(req.GetRequestId())
+	if err != nil {
+		if errors.Is(err, storage.ErrPrivacyRequestNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		slog.Error("error getting privacy request", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to get privacy request")
+	}
+
+	// чужие запросы не показываем, отвечаем так же, как на несуществующий
+	if privacyReq.UserID != req.GetUserId() {
+		return nil, status.Error(codes.NotFound, storage.ErrPrivacyRequestNotFound.Error())
+	}
+
+	apiSteps := make([]*api.PrivacyStep, 0, len(steps))
+	for _, step := range steps {
+		apiSteps = append(apiSteps, &api.PrivacyStep{
+			Service:   step.Service,
+			Status:    step.Status,
+			Attempts:  int32(step.Attempts),
+			LastError: step.LastError,
+			Data:      string(step.Data),
+		})
+	}
+
+	return &api.GetPrivacyRequestResponse{
+		RequestId: privacyReq.ID,
+		Kind:      privacyReq.Kind,
+		Status:    privacyReq.Status,
+		Steps:     apiSteps,
+	}, nil
+}
+
+// requestErasure проверяет пароль, отзывает refresh токен и ставит удаление данных в очередь
+func (s *AuthService) requestErasure(ctx context.Context, userId int64, password string) (int64, error) {
+	user, err := s.getUser(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.checkPassword(ctx, user, password); err != nil {
+		return 0, err
+	}
+
+	id, err := s.privacy.Request(user.ID, models.PrivacyKindErasure)
+	if err != nil {
+		slog.Error("error creating erasure request", slog.String("error", err.Error()))
+		return 0, status.Error(codes.Internal, "unable to create erasure request")
+	}
+
+	if err := s.refreshInMemStorage.RemoveToken(ctx, user.ID); err != nil {
+		slog.Warn("error removing refresh token of erased user", slog.String("error", err.Error()))
+	}
+	if err := s.sessions.RevokeAll(ctx, user.ID); err != nil {
+		slog.Warn("error revoking sessions of erased user", slog.String("error", err.Error()))
+	}
+	if err := s.stor.RevokeOAuthRefreshTokens(user.ID); err != nil {
+		slog.Warn("error revoking oauth refresh tokens of erased user", slog.String("error", err.Error()))
+	}
+
+	return id, nil
+}
@@ -0,0 +1,190 @@
+package grpc
+
+import (
+	"auth_service/internal/loginguard"
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"auth_service/internal/validation"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	api "github.com/artemSorokin1/Auth-proto/protos/gen/protos/proto"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *AuthService) GetProfile(ctx context.Context, req *api.GetProfileRequest) (*api.GetProfileResponse, error) {
+	slog.Info("GetProfile method called")
+
+	user, err := s.getUser(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.GetProfileResponse{
+		Profile: toProfile(user),
+	}, nil
+}
+
+func (s *AuthService) UpdateProfile(ctx context.Context, req *api.UpdateProfileRequest) (*api.UpdateProfileResponse, error) {
+	slog.Info("UpdateProfile method called")
+
+	fullName := strings.TrimSpace(req.GetFullName())
+	phone := strings.TrimSpace(req.GetPhone())
+	address := strings.TrimSpace(req.GetAddress())
+
+	if err := errors.Join(validation.FullName(fullName), validation.Phone(phone), validation.Address(address)); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.stor.UpdateProfile(req.GetUserId(), fullName, phone, address); err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		slog.Error("error updating profile", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to update profile")
+	}
+
+	user, err := s.getUser(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.UpdateProfileResponse{
+		Profile: toProfile(user),
+	}, nil
+}
+
+func (s *AuthService) ChangePassword(ctx context.Context, req *api.ChangePasswordRequest) (*api.ChangePasswordResponse, error) {
+	slog.Info("ChangePassword method called")
+
+	if err := validation.Password(req.GetNewPassword()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	user, err := s.getUser(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPassword(ctx, user, req.GetOldPassword()); err != nil {
+		return nil, err
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(req.GetNewPassword()), bcrypt.DefaultCost)
+	if err != nil {
+		slog.Warn("error hashing password")
+		return nil, status.Error(codes.Internal, "unable to hash password")
+	}
+
+	if err := s.stor.UpdatePassword(user.ID, string(passHash)); err != nil {
+		slog.Error("error updating password", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to update password")
+	}
+
+	// как и после сброса пароля, сессии, открытые со старым паролем, больше недействительны:
+	// пароль часто меняют именно потому, что он утек
+	if err := s.refreshInMemStorage.RemoveToken(ctx, user.ID); err != nil {
+		slog.Warn("error removing refresh token after password change", slog.String("error", err.Error()))
+	}
+	if err := s.sessions.RevokeAll(ctx, user.ID); err != nil {
+		slog.Warn("error revoking sessions after password change", slog.String("error", err.Error()))
+	}
+	if err := s.stor.RevokeOAuthRefreshTokens(user.ID); err != nil {
+		slog.Warn("error revoking oauth refresh tokens after password change", slog.String("error", err.Error()))
+	}
+
+	return &api.ChangePasswordResponse{
+		IsSuccess: true,
+	}, nil
+}
+
+func (s *AuthService) ChangeEmail(ctx context.Context, req *api.ChangeEmailRequest) (*api.ChangeEmailResponse, error) {
+	slog.Info("ChangeEmail method called")
+
+	email := strings.TrimSpace(req.GetNewEmail())
+	if err := validation.Email(email); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	user, err := s.getUser(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPassword(ctx, user, req.GetPassword()); err != nil {
+		return nil, err
+	}
+
+	if err := s.stor.ChangeEmail(user.ID, email); err != nil {
+		if errors.Is(err, storage.ErrUserExist) {
+			return nil, status.Error(codes.AlreadyExists, "email is already in use")
+		}
+		slog.Error("error changing email", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to change email")
+	}
+
+	user.Email = email
+	if err := s.sendUserToken(ctx, user, models.TokenPurposeEmailVerification); err != nil {
+		slog.Warn("error sending verification email", slog.String("error", err.Error()))
+	}
+
+	return &api.ChangeEmailResponse{
+		IsSuccess: true,
+	}, nil
+}
+
+func (s *AuthService) DeleteAccount(ctx context.Context, req *api.DeleteAccountRequest) (*api.DeleteAccountResponse, error) {
+	slog.Info("DeleteAccount method called")
+
+	id, err := s.requestErasure(ctx, req.GetUserId(), req.GetPassword())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.DeleteAccountResponse{
+		IsSuccess: true,
+		RequestId: id,
+	}, nil
+}
+
+// checkPassword повторно проверяет пароль перед чувствительными изменениями, с теми же лимитами, что и вход
+func (s *AuthService) checkPassword(ctx context.Context, user models.User, password string) error {
+	if err := s.guard.Allow(ctx, user.Username, clientIP(ctx)); err != nil {
+		var limitErr *loginguard.LimitError
+		if errors.As(err, &limitErr) {
+			return status.Error(codes.ResourceExhausted, limitErr.Error())
+		}
+		slog.Error("error checking login limits", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "unable to check login limits")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PassHash), []byte(password)); err != nil {
+		s.loginFailed(ctx, user.Username, "wrong_password")
+		return status.Error(codes.Unauthenticated, "invalid password")
+	}
+
+	if err := s.guard.Success(ctx, user.Username); err != nil {
+		slog.Warn("error resetting failed logins", slog.String("error", err.Error()))
+	}
+
+	return nil
+}
+
+func toProfile(user models.User) *api.Profile {
+	return &api.Profile{
+		UserId:           user.ID,
+		Username:         user.Username,
+		Email:            user.Email,
+		EmailVerified:    user.EmailVerified,
+		FullName:         user.FullName,
+		Phone:            user.Phone,
+		Address:          user.Address,
+		Role:             user.Role,
+		TwoFactorEnabled: user.TOTPEnabled,
+		CreatedAt:        user.TimeCreatedAcc.Unix(),
+	}
+}
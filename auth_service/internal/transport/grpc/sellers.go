@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"context"
+	"errors"
+	"log/slog"
+
+	pb "github.com/artemSorokin1/sellers-grpc-api/gen/go/seller"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SellersService отдает аккаунт продавца по публичному uuid. Карточки продавцов хранит sellers_service,
+// отсюда он переносит продавцов, которых у себя еще не знает.
+// По verification_status сервис товаров решает, можно ли продавцу публиковать товары.
+type SellersService struct {
+	pb.UnimplementedSellersServiceServer
+	stor *storage.Storage
+}
+
+func (s *SellersService) GetSeller(ctx context.Context, req *pb.GetSellerRequest) (*pb.GetSellerResponse, error) {
+	slog.Info("GetSeller method called", slog.String("id", req.GetId()))
+
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid seller id")
+	}
+
+	user, err := s.stor.GetSellerByUUID(req.GetId())
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "seller not found")
+		}
+		slog.Error("error getting seller", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to get seller")
+	}
+
+	profile, err := s.stor.GetSellerProfile(user.ID)
+	if err != nil && !errors.Is(err, storage.ErrSellerProfileNotFound) {
+		slog.Error("error getting seller profile", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to get seller")
+	}
+	// продавец без анкеты (роль выдана вручную) считается не прошедшим проверку
+	verificationStatus := profile.Status
+	if verificationStatus == "" {
+		verificationStatus = models.SellerStatusDraft
+	}
+
+	return &pb.GetSellerResponse{
+		Seller: &pb.Seller{
+			Id:                 user.UUID,
+			Phone:              user.Phone,
+			Email:              user.Email,
+			Fullname:           user.FullName,
+			City:               user.City,
+			CreatedAt:          timestamppb.New(user.TimeCreatedAcc),
+			VerificationStatus: verificationStatus,
+		},
+	}, nil
+}
@@ -2,13 +2,20 @@ package grpc
 
 import (
 	"auth_service/internal/config"
+	"auth_service/internal/loginguard"
+	"auth_service/internal/privacy"
 	"auth_service/internal/repositiry/storage"
+	"auth_service/internal/social"
+	"auth_service/pkg/events"
 	"auth_service/pkg/storage/inmem"
+	"context"
 	"fmt"
+	"grpcsec"
 	"log/slog"
 	"net"
 
 	api "github.com/artemSorokin1/Auth-proto/protos/gen/protos/proto"
+	pb "github.com/artemSorokin1/sellers-grpc-api/gen/go/seller"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -20,19 +27,35 @@ type Server struct {
 }
 
 // New создает связб между grpc сервером и реализацией его методов
-func New(config config.ServerConfig, s *storage.Storage, logger *zap.Logger) *Server {
+func New(config config.ServerConfig, s *storage.Storage, refreshStor inmem.RefreshTokenStorage, sessions inmem.SessionStorage, guard *loginguard.Guard, privacy *privacy.Orchestrator, social *social.Service, logger *zap.Logger) *Server {
 	lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%s", config.GRPCPort))
 	if err != nil {
 		logger.Fatal("failed to listen", zap.Error(err))
 	}
 
-	var opts []grpc.ServerOption
+	opts, err := grpcsec.ServerOptions(context.Background(), grpcsec.ConfigFromEnv(), callersPolicy)
+	if err != nil {
+		logger.Fatal("failed to configure grpc tls", zap.Error(err))
+	}
 
 	grpcServer := grpc.NewServer(opts...)
 
-	refreshStor := inmem.NewRedisStorage()
+	publisher := events.NewRedisPublisher(config.Verification.EventsChannel)
 
-	api.RegisterAuthServiceServer(grpcServer, &AuthService{stor: s, refreshInMemStorage: refreshStor})
+	api.RegisterAuthServiceServer(grpcServer, &AuthService{
+		stor:                s,
+		refreshInMemStorage: refreshStor,
+		sessions:            sessions,
+		guard:               guard,
+		events:              publisher,
+		verificationCfg:     config.Verification,
+		twoFactorCfg:        config.TwoFactor,
+		privacy:             privacy,
+		social:              social,
+	})
+	pb.RegisterSellersServiceServer(grpcServer, &SellersService{
+		stor: s,
+	})
 
 	return &Server{
 		grpcServer,
@@ -0,0 +1,213 @@
+package grpc
+
+import (
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"auth_service/internal/social"
+	"auth_service/internal/tokens"
+	"auth_service/internal/validation"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"regexp"
+	"strings"
+
+	api "github.com/artemSorokin1/Auth-proto/protos/gen/protos/proto"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxSocialUsernameLen = 50
+
+var usernameDisallowedRe = regexp.MustCompile(`[^a-zA-Z0-9_.\-]+`)
+
+func (s *AuthService) StartSocialLogin(ctx context.Context, req *api.StartSocialLoginRequest) (*api.StartSocialLoginResponse, error) {
+	slog.Info("StartSocialLogin method called")
+
+	authURL, state, err := s.social.Start(ctx, req.GetProvider())
+	if err != nil {
+		if errors.Is(err, social.ErrUnknownProvider) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		slog.Error("error starting social login", slog.String("provider", req.GetProvider()), slog.String("error", err.Error()))
+		return nil, status.Error(codes.Unavailable, "identity provider is unavailable")
+	}
+
+	return &api.StartSocialLoginResponse{
+		AuthorizationUrl: authURL,
+		State:            state,
+	}, nil
+}
+
+func (s *AuthService) CompleteSocialLogin(ctx context.Context, req *api.CompleteSocialLoginRequest) (*api.CompleteSocialLoginResponse, error) {
+	slog.Info("CompleteSocialLogin method called")
+
+	claims, err := s.social.Complete(ctx, req.GetProvider(), req.GetCode(), req.GetState())
+	if err != nil {
+		switch {
+		case errors.Is(err, social.ErrUnknownProvider):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, social.ErrInvalidState), errors.Is(err, social.ErrExchangeFailed), errors.Is(err, social.ErrInvalidIDToken):
+			slog.Warn("social login rejected", slog.String("provider", req.GetProvider()), slog.String("error", err.Error()))
+			return nil, status.Error(codes.Unauthenticated, "social login failed")
+		}
+		slog.Error("error completing social login", slog.String("provider", req.GetProvider()), slog.String("error", err.Error()))
+		return nil, status.Error(codes.Unavailable, "identity provider is unavailable")
+	}
+
+	user, isNew, err := s.socialUser(req.GetProvider(), claims)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.completeLogin(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CompleteSocialLoginResponse{
+		AccessToken:       resp.GetAccessToken(),
+		RefreshToken:      resp.GetRefreshToken(),
+		TwoFactorRequired: resp.GetTwoFactorRequired(),
+		ChallengeToken:    resp.GetChallengeToken(),
+		IsNewUser:         isNew,
+	}, nil
+}
+
+// socialUser находит или создает пользователя для аккаунта провайдера:
+//  1. аккаунт уже привязан - вход в привязанного пользователя;
+//  2. провайдер подтвердил почту, и у нас есть пользователь с этой подтвержденной почтой - аккаунт привязывается;
+//  3. пользователя с такой почтой нет - создается новый.
+//
+// С неподтвержденной почтой (у нас или у провайдера) аккаунты не связываются: иначе можно было бы
+// заранее зарегистрироваться на чужую почту и получить доступ к аккаунту жертвы после ее входа.
+func (s *AuthService) socialUser(provider string, claims social.Claims) (models.User, bool, error) {
+	user, err := s.stor.GetUserByIdentity(provider, claims.Subject)
+	if err == nil {
+		return user, false, nil
+	}
+	if !errors.Is(err, storage.ErrUserNotFound) {
+		slog.Error("error getting user by identity", slog.String("error", err.Error()))
+		return models.User{}, false, status.Error(codes.Internal, "unable to get user")
+	}
+
+	if validation.Email(claims.Email) != nil {
+		return models.User{}, false, status.Error(codes.FailedPrecondition, "identity provider did not share a valid email")
+	}
+
+	identity := models.UserIdentity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+
+	user, err = s.stor.GetUserByEmail(claims.Email)
+	switch {
+	case err == nil:
+		if !claims.EmailVerified || !user.EmailVerified {
+			slog.Warn("social account not linked: email is not verified",
+				slog.String("provider", provider), slog.Int64("userId", user.ID))
+			return models.User{}, false, status.Error(codes.AlreadyExists,
+				"an account with this email already exists, sign in with password and verify the email to link it")
+		}
+
+		identity.UserID = user.ID
+		if err := s.stor.LinkIdentity(identity); err != nil {
+			slog.Error("error linking identity", slog.String("error", err.Error()))
+			return models.User{}, false, status.Error(codes.Internal, "unable to link account")
+		}
+		slog.Info("social account linked", slog.String("provider", provider), slog.Int64("userId", user.ID))
+
+		return user, false, nil
+	case !errors.Is(err, storage.ErrUserNotFound):
+		slog.Error("error getting user by email", slog.String("error", err.Error()))
+		return models.User{}, false, status.Error(codes.Internal, "unable to get user")
+	}
+
+	user, err = s.createSocialUser(claims, identity)
+	if err != nil {
+		return models.User{}, false, err
+	}
+
+	return user, true, nil
+}
+
+// createSocialUser создает пользователя без пароля: войти по паролю он сможет после сброса пароля
+func (s *AuthService) createSocialUser(claims social.Claims, identity models.UserIdentity) (models.User, error) {
+	username, err := s.freeUsername(claims)
+	if err != nil {
+		slog.Error("error choosing username", slog.String("error", err.Error()))
+		return models.User{}, status.Error(codes.Internal, "unable to create user")
+	}
+
+	password, _, err := tokens.Generate()
+	if err != nil {
+		return models.User{}, status.Error(codes.Internal, "unable to create user")
+	}
+	passHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, status.Error(codes.Internal, "unable to create user")
+	}
+
+	user := models.User{
+		Email:         claims.Email,
+		Username:      username,
+		PassHash:      string(passHash),
+		Role:          models.RoleCustomer,
+		EmailVerified: claims.EmailVerified,
+	}
+
+	id, err := s.stor.AddUserWithIdentity(user, identity)
+	if err != nil {
+		slog.Error("error adding social user", slog.String("error", err.Error()))
+		return models.User{}, status.Error(codes.Internal, "unable to create user")
+	}
+
+	if claims.Name != "" && validation.FullName(claims.Name) == nil {
+		if err := s.stor.UpdateProfile(id, claims.Name, "", ""); err != nil {
+			slog.Warn("error saving full name", slog.String("error", err.Error()))
+		}
+	}
+
+	slog.Info("user created via social login", slog.String("provider", identity.Provider), slog.Int64("userId", id))
+
+	return s.getUser(id)
+}
+
+// freeUsername строит логин из preferred_username или почты и добавляет суффикс, если он занят
+func (s *AuthService) freeUsername(claims social.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = usernameDisallowedRe.ReplaceAllString(base, "")
+	if len(base) > maxSocialUsernameLen {
+		base = base[:maxSocialUsernameLen]
+	}
+	if len(base) < 3 {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		taken, err := s.stor.UsernameTaken(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken && validation.Username(candidate) == nil {
+			return candidate, nil
+		}
+
+		suffix, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s_%06d", base, suffix.Int64())
+	}
+
+	return "", errors.New("no free username found")
+}
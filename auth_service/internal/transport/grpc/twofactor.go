@@ -0,0 +1,218 @@
+package grpc
+
+import (
+	"auth_service/internal/jwt"
+	"auth_service/internal/loginguard"
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"auth_service/internal/tokens"
+	"auth_service/internal/totp"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	api "github.com/artemSorokin1/Auth-proto/protos/gen/protos/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *AuthService) EnrollTwoFactor(ctx context.Context, req *api.EnrollTwoFactorRequest) (*api.EnrollTwoFactorResponse, error) {
+	slog.Info("EnrollTwoFactor method called")
+
+	user, err := s.getUser(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		slog.Error("error generating totp secret", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to generate secret")
+	}
+
+	if err := s.stor.SetTOTPSecret(user.ID, secret); err != nil {
+		slog.Error("error saving totp secret", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to save secret")
+	}
+
+	return &api.EnrollTwoFactorResponse{
+		Secret:     secret,
+		OtpauthUri: totp.URI(s.twoFactorCfg.Issuer, user.Username, secret),
+	}, nil
+}
+
+func (s *AuthService) ConfirmTwoFactor(ctx context.Context, req *api.ConfirmTwoFactorRequest) (*api.ConfirmTwoFactorResponse, error) {
+	slog.Info("ConfirmTwoFactor method called")
+
+	user, err := s.getUser(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor enrollment is not started")
+	}
+
+	step, ok := totp.Validate(user.TOTPSecret, req.GetCode(), time.Now())
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	if _, err := s.stor.UseTOTPStep(user.ID, step); err != nil {
+		slog.Error("error saving totp step", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to confirm two-factor authentication")
+	}
+
+	recoveryCodes := make([]string, 0, s.twoFactorCfg.RecoveryCodes)
+	hashes := make([]string, 0, s.twoFactorCfg.RecoveryCodes)
+	for i := 0; i < s.twoFactorCfg.RecoveryCodes; i++ {
+		code, err := tokens.GenerateRecoveryCode()
+		if err != nil {
+			return nil, status.Error(codes.Internal, "unable to generate recovery codes")
+		}
+		recoveryCodes = append(recoveryCodes, code)
+		hashes = append(hashes, tokens.Hash(code))
+	}
+
+	if err := s.stor.EnableTOTP(user.ID, hashes); err != nil {
+		slog.Error("error enabling totp", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to enable two-factor authentication")
+	}
+
+	return &api.ConfirmTwoFactorResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (s *AuthService) DisableTwoFactor(ctx context.Context, req *api.DisableTwoFactorRequest) (*api.DisableTwoFactorResponse, error) {
+	slog.Info("DisableTwoFactor method called")
+
+	user, err := s.getUser(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TOTPEnabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+
+	if err := s.checkSecondFactor(ctx, user, req.GetCode()); err != nil {
+		return nil, err
+	}
+
+	if err := s.stor.DisableTOTP(user.ID); err != nil {
+		slog.Error("error disabling totp", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to disable two-factor authentication")
+	}
+
+	return &api.DisableTwoFactorResponse{
+		IsSuccess: true,
+	}, nil
+}
+
+func (s *AuthService) VerifySecondFactor(ctx context.Context, req *api.VerifySecondFactorRequest) (*api.VerifySecondFactorResponse, error) {
+	slog.Info("VerifySecondFactor method called")
+
+	userId, err := jwt.ParseChallengeToken(req.GetChallengeToken())
+	if err != nil {
+		slog.Warn("invalid challenge token", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired challenge token")
+	}
+
+	user, err := s.getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkSecondFactor(ctx, user, req.GetCode()); err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.VerifySecondFactorResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// checkSecondFactor принимает TOTP код или код восстановления. Неверные коды считаются
+// неудачными попытками входа, поэтому перебор упирается в те же лимиты, что и пароль.
+func (s *AuthService) checkSecondFactor(ctx context.Context, user models.User, code string) error {
+	if err := s.guard.Allow(ctx, user.Username, clientIP(ctx)); err != nil {
+		var limitErr *loginguard.LimitError
+		if errors.As(err, &limitErr) {
+			return status.Error(codes.ResourceExhausted, limitErr.Error())
+		}
+		slog.Error("error checking login limits", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "unable to check login limits")
+	}
+
+	ok, err := s.useSecondFactor(user, code)
+	if err != nil {
+		slog.Error("error checking second factor", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "unable to check code")
+	}
+	if !ok {
+		slog.Warn("wrong second factor code", slog.Int64("userId", user.ID))
+		return s.loginFailed(ctx, user.Username, "wrong_second_factor")
+	}
+
+	if err := s.guard.Success(ctx, user.Username); err != nil {
+		slog.Warn("error resetting failed logins", slog.String("error", err.Error()))
+	}
+
+	return nil
+}
+
+func (s *AuthService) useSecondFactor(user models.User, code string) (bool, error) {
+	if step, ok := totp.Validate(user.TOTPSecret, code, time.Now()); ok {
+		return s.stor.UseTOTPStep(user.ID, step)
+	}
+
+	return s.stor.UseRecoveryCode(user.ID, tokens.Hash(tokens.NormalizeRecoveryCode(code)))
+}
+
+func (s *AuthService) getUser(userId int64) (models.User, error) {
+	user, err := s.stor.GetUserById(userId)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.User{}, status.Error(codes.NotFound, err.Error())
+		}
+		slog.Error("error getting user", slog.String("error", err.Error()))
+		return models.User{}, status.Error(codes.Internal, "unable to get user")
+	}
+
+	return user, nil
+}
+
+// isAdmin учитывает обязательную 2FA: без нее администратор работает как обычный пользователь
+func (s *AuthService) isAdmin(userId int64) (bool, error) {
+	isAdmin, err := s.stor.IsAdmin(userId)
+	if err != nil || !isAdmin || !s.twoFactorCfg.EnforceForAdmins {
+		return isAdmin, err
+	}
+
+	user, err := s.stor.GetUserById(userId)
+	if err != nil {
+		return false, err
+	}
+
+	if !user.TOTPEnabled {
+		slog.Warn("admin rights denied: two-factor authentication is not enabled", slog.Int64("userId", userId))
+		return false, nil
+	}
+
+	return true, nil
+}
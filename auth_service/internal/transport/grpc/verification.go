@@ -0,0 +1,161 @@
+package grpc
+
+import (
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"auth_service/internal/tokens"
+	"auth_service/internal/validation"
+	"auth_service/pkg/events"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	api "github.com/artemSorokin1/Auth-proto/protos/gen/protos/proto"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *AuthService) RequestEmailVerification(ctx context.Context, req *api.RequestEmailVerificationRequest) (*api.RequestEmailVerificationResponse, error) {
+	slog.Info("RequestEmailVerification method called")
+
+	user, err := s.stor.GetUserById(req.GetUserId())
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "unable to get user")
+	}
+
+	if user.EmailVerified {
+		return nil, status.Error(codes.FailedPrecondition, "email is already verified")
+	}
+
+	if err := s.sendUserToken(ctx, user, models.TokenPurposeEmailVerification); err != nil {
+		slog.Error("error sending verification email", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to send verification email")
+	}
+
+	return &api.RequestEmailVerificationResponse{
+		IsSuccess: true,
+	}, nil
+}
+
+func (s *AuthService) ConfirmEmail(ctx context.Context, req *api.ConfirmEmailRequest) (*api.ConfirmEmailResponse, error) {
+	slog.Info("ConfirmEmail method called")
+
+	userId, err := s.stor.ConsumeUserToken(models.TokenPurposeEmailVerification, tokens.Hash(req.GetToken()))
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenInvalid) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "unable to check token")
+	}
+
+	if err := s.stor.SetEmailVerified(userId); err != nil {
+		slog.Error("error setting email verified", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to verify email")
+	}
+
+	return &api.ConfirmEmailResponse{
+		UserId: userId,
+	}, nil
+}
+
+func (s *AuthService) RequestPasswordReset(ctx context.Context, req *api.RequestPasswordResetRequest) (*api.RequestPasswordResetResponse, error) {
+	slog.Info("RequestPasswordReset method called")
+
+	user, err := s.stor.GetUserByEmail(req.GetEmail())
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			slog.Info("password reset requested for unknown email")
+			return &api.RequestPasswordResetResponse{IsSuccess: true}, nil
+		}
+		return nil, status.Error(codes.Internal, "unable to get user")
+	}
+
+	if err := s.sendUserToken(ctx, user, models.TokenPurposePasswordReset); err != nil {
+		slog.Error("error sending password reset email", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to send password reset email")
+	}
+
+	return &api.RequestPasswordResetResponse{
+		IsSuccess: true,
+	}, nil
+}
+
+func (s *AuthService) ResetPassword(ctx context.Context, req *api.ResetPasswordRequest) (*api.ResetPasswordResponse, error) {
+	slog.Info("ResetPassword method called")
+
+	if err := validation.Password(req.GetNewPassword()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	userId, err := s.stor.ConsumeUserToken(models.TokenPurposePasswordReset, tokens.Hash(req.GetToken()))
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenInvalid) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "unable to check token")
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(req.GetNewPassword()), bcrypt.DefaultCost)
+	if err != nil {
+		slog.Warn("error hashing password")
+		return nil, status.Error(codes.Internal, "unable to hash password")
+	}
+
+	if err := s.stor.UpdatePassword(userId, string(passHash)); err != nil {
+		slog.Error("error updating password", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "unable to update password")
+	}
+
+	// письмо со ссылкой пришло на почту владельца, значит почта подтверждена
+	if err := s.stor.SetEmailVerified(userId); err != nil {
+		slog.Warn("error setting email verified", slog.String("error", err.Error()))
+	}
+
+	// старые сессии больше недействительны
+	if err := s.refreshInMemStorage.RemoveToken(ctx, userId); err != nil {
+		slog.Warn("error removing refresh token after password reset", slog.String("error", err.Error()))
+	}
+	if err := s.sessions.RevokeAll(ctx, userId); err != nil {
+		slog.Warn("error revoking sessions after password reset", slog.String("error", err.Error()))
+	}
+	if err := s.stor.RevokeOAuthRefreshTokens(userId); err != nil {
+		slog.Warn("error revoking oauth refresh tokens after password reset", slog.String("error", err.Error()))
+	}
+
+	return &api.ResetPasswordResponse{
+		IsSuccess: true,
+	}, nil
+}
+
+// sendUserToken создает одноразовый токен и просит notification_service отправить его на почту
+func (s *AuthService) sendUserToken(ctx context.Context, user models.User, purpose string) error {
+	ttl := s.verificationCfg.EmailTokenTTL
+	eventType := events.TypeEmailVerification
+	if purpose == models.TokenPurposePasswordReset {
+		ttl = s.verificationCfg.ResetTokenTTL
+		eventType = events.TypePasswordReset
+	}
+
+	token, hash, err := tokens.Generate()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	if err := s.stor.SaveUserToken(user.ID, purpose, hash, expiresAt); err != nil {
+		return err
+	}
+
+	return s.events.Publish(ctx, events.Event{
+		Type:      eventType,
+		Email:     user.Email,
+		Username:  user.Username,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
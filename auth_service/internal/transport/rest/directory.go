@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"auth_service/internal/models"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSellersLimit = 20
+	maxSellersLimit     = 100
+	maxSellerQueryLen   = 100
+)
+
+var sellerSorts = []string{
+	models.SellerSortRelevance,
+	models.SellerSortUsername,
+	models.SellerSortNewest,
+	models.SellerSortOldest,
+}
+
+type sellerDirectoryResponse struct {
+	Sellers []sellerResponse `json:"sellers"`
+	Total   int64            `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+}
+
+// ListSellers - публичный каталог одобренных продавцов: ?q= ищет по логину, имени и городу
+// с учетом опечаток, sort - relevance, username, newest или oldest. Без q по умолчанию сначала
+// новые продавцы, с q - самые похожие. Фильтр по статусу проверки есть только у администратора
+// (/api/auth/admin/sellers?status=), иначе каталог раскрывал бы непроверенные и отклоненные анкеты.
+func (h *Handler) ListSellers(c *gin.Context) {
+	filter := models.SellerFilter{
+		Query: strings.TrimSpace(c.Query("q")),
+		Sort:  c.Query("sort"),
+	}
+
+	if len([]rune(filter.Query)) > maxSellerQueryLen {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "search query is too long"})
+		return
+	}
+
+	switch {
+	case filter.Sort == "" && filter.Query != "":
+		filter.Sort = models.SellerSortRelevance
+	case filter.Sort == "":
+		filter.Sort = models.SellerSortNewest
+	case !slices.Contains(sellerSorts, filter.Sort):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be one of: " + strings.Join(sellerSorts, ", ")})
+		return
+	}
+
+	var err error
+	filter.Limit, err = queryInt(c, "limit", defaultSellersLimit)
+	if err != nil || filter.Limit <= 0 || filter.Limit > maxSellersLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+		return
+	}
+
+	filter.Offset, err = queryInt(c, "offset", 0)
+	if err != nil || filter.Offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must not be negative"})
+		return
+	}
+
+	sellers, total, err := h.stor.ListSellers(filter)
+	if err != nil {
+		h.logger.Error("error listing sellers", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to list sellers"})
+		return
+	}
+
+	resp := sellerDirectoryResponse{
+		Sellers: make([]sellerResponse, 0, len(sellers)),
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}
+	for _, seller := range sellers {
+		resp.Sellers = append(resp.Sellers, sellerResponse{
+			ID:                 seller.UUID,
+			Username:           seller.Username,
+			FullName:           seller.FullName,
+			City:               seller.City,
+			CreatedAt:          seller.TimeCreatedAcc,
+			VerificationStatus: seller.VerificationStatus,
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
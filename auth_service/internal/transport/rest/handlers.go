@@ -0,0 +1,434 @@
+package rest
+
+import (
+	"auth_service/internal/config"
+	"auth_service/internal/jwt"
+	"auth_service/internal/loginguard"
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"auth_service/internal/tokens"
+	"auth_service/internal/totp"
+	"auth_service/internal/validation"
+	"auth_service/pkg/storage/inmem"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const refreshCookie = "refresh_token"
+
+var (
+	errInvalidCredentials   = errors.New("invalid username or password")
+	errSecondFactorRequired = errors.New("two-factor code is required")
+	errEmailNotVerified     = errors.New("email is not verified")
+	errNotSeller            = errors.New("account is not a seller")
+	errInvalidRefreshToken  = errors.New("invalid refresh token")
+)
+
+// UserStorage - методы storage.Storage, которые нужны http api
+type UserStorage interface {
+	AddNewUser(newUser models.User) (int64, error)
+	AddSeller(newUser models.User) (int64, error)
+	GetUser(username string) (models.User, error)
+	GetUserById(userId int64) (models.User, error)
+	IsAdmin(userId int64) (bool, error)
+	UseTOTPStep(userId, step int64) (bool, error)
+	UseRecoveryCode(userId int64, codeHash string) (bool, error)
+	SearchSellersByUsername(username string) ([]models.User, error)
+	GetSellerByUUID(id string) (models.User, error)
+	ListSellers(filter models.SellerFilter) ([]models.SellerDirectoryEntry, int64, error)
+	GetSellerProfile(userId int64) (models.SellerProfile, error)
+	UpdateSellerProfile(update models.SellerProfile) (models.SellerProfile, error)
+	ChangeSellerStatus(userId int64, to string, actorId int64, comment string) (models.SellerProfile, error)
+	GetSellerStatusHistory(userId int64) ([]models.SellerStatusChange, error)
+	ListSellerApplications(status string, limit, offset int) ([]models.SellerApplication, error)
+}
+
+type Handler struct {
+	cfg             config.HTTPConfig
+	verificationCfg config.VerificationConfig
+	twoFactorCfg    config.TwoFactorConfig
+	stor            UserStorage
+	sessions        inmem.SessionStorage
+	guard           *loginguard.Guard
+	refreshTTL      time.Duration
+	logger          *zap.Logger
+}
+
+type registrationRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Username string `json:"username" binding:"required"`
+}
+
+// loginRequest - otp нужен только пользователям с включенной 2FA (код TOTP или код восстановления)
+type loginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	OTP      string `json:"otp"`
+}
+
+type sellerResponse struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	FullName  string    `json:"fullname"`
+	City      string    `json:"city"`
+	CreatedAt time.Time `json:"created_at"`
+	// VerificationStatus есть только в каталоге продавцов
+	VerificationStatus string `json:"verification_status,omitempty"`
+}
+
+func (h *Handler) SignUpSeller(c *gin.Context) {
+	h.signUp(c, models.RoleSeller)
+}
+
+func (h *Handler) SignUpCustomer(c *gin.Context) {
+	h.signUp(c, models.RoleCustomer)
+}
+
+// SignInSeller пускает только продавцов, SignInCustomer - любой аккаунт: покупать может каждый.
+// Сессия запоминает, через какой вход она открыта, и обновляется только тем же маршрутом.
+func (h *Handler) SignInSeller(c *gin.Context) {
+	h.signIn(c, models.RoleSeller)
+}
+
+func (h *Handler) SignInCustomer(c *gin.Context) {
+	h.signIn(c, models.RoleCustomer)
+}
+
+func (h *Handler) RefreshSeller(c *gin.Context) {
+	h.refresh(c, models.RoleSeller)
+}
+
+func (h *Handler) RefreshCustomer(c *gin.Context) {
+	h.refresh(c, models.RoleCustomer)
+}
+
+func (h *Handler) signUp(c *gin.Context, role string) {
+	var req registrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := errors.Join(
+		validation.Username(req.Username),
+		validation.Email(req.Email),
+		validation.Password(req.Password),
+	)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		h.logger.Error("error hashing password", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to register user"})
+		return
+	}
+
+	user := models.User{
+		Email:    req.Email,
+		Username: req.Username,
+		PassHash: string(passHash),
+		Role:     role,
+	}
+
+	// продавец начинает с черновика анкеты, публиковать товары он сможет после проверки
+	if role == models.RoleSeller {
+		_, err = h.stor.AddSeller(user)
+	} else {
+		_, err = h.stor.AddNewUser(user)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrUserExist) {
+			c.JSON(http.StatusConflict, gin.H{"error": "user with email or username already exists"})
+			return
+		}
+		h.logger.Error("error adding new user", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to register user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User created"})
+}
+
+func (h *Handler) signIn(c *gin.Context, role string) {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	user, err := h.authenticate(ctx, req.Username, req.Password, req.OTP, c.ClientIP())
+	if err != nil {
+		var limitErr *loginguard.LimitError
+		switch {
+		case errors.As(err, &limitErr):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": limitErr.Error()})
+		case errors.Is(err, errInvalidCredentials), errors.Is(err, errSecondFactorRequired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, errEmailNotVerified):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if role == models.RoleSeller && user.Role != models.RoleSeller {
+		c.JSON(http.StatusForbidden, gin.H{"error": errNotSeller.Error()})
+		return
+	}
+
+	session := inmem.Session{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Role:      role,
+		TokenID:   uuid.NewString(),
+		CreatedAt: time.Now(),
+	}
+
+	refreshToken, err := jwt.CreateSessionToken(&user, session.ID, session.TokenID)
+	if err != nil {
+		h.logger.Error("error creating refresh token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to create refresh token"})
+		return
+	}
+
+	if err := h.sessions.Create(ctx, session, h.refreshTTL); err != nil {
+		h.logger.Error("error creating session", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to create session"})
+		return
+	}
+
+	h.issueTokens(c, user, refreshToken)
+}
+
+// Logout отзывает сессию из cookie. Cookie удаляется, даже если токен уже недействителен.
+func (h *Handler) Logout(c *gin.Context) {
+	if refreshToken, err := c.Cookie(refreshCookie); err == nil && refreshToken != "" {
+		if claims, err := jwt.ParseSessionToken(refreshToken); err == nil {
+			if err := h.sessions.Revoke(c.Request.Context(), claims.SessionID); err != nil {
+				h.logger.Error("error revoking session", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to revoke session"})
+				return
+			}
+		}
+	}
+
+	// Удаляем refresh token из cookies
+	h.setRefreshCookie(c, "", -1)
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out successfully"})
+}
+
+// refresh обменивает refresh токен сессии на новую пару. Старый токен после этого недействителен,
+// а его повторное предъявление отзывает сессию целиком.
+func (h *Handler) refresh(c *gin.Context, role string) {
+	refreshToken, err := c.Cookie(refreshCookie)
+	if err != nil || refreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh token is required"})
+		return
+	}
+
+	claims, err := jwt.ParseSessionToken(refreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidRefreshToken.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	session, err := h.sessions.Get(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, inmem.ErrSessionNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidRefreshToken.Error()})
+			return
+		}
+		h.logger.Error("error getting session", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to get session"})
+		return
+	}
+
+	if session.UserID != claims.UserID {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidRefreshToken.Error()})
+		return
+	}
+	if session.Role != role {
+		c.JSON(http.StatusForbidden, gin.H{"error": "session was opened for another role"})
+		return
+	}
+
+	tokenId := uuid.NewString()
+	session, err = h.sessions.Rotate(ctx, session.ID, claims.ID, tokenId, h.refreshTTL)
+	if err != nil {
+		switch {
+		case errors.Is(err, inmem.ErrTokenReused):
+			h.logger.Warn("refresh token reuse, session revoked", zap.Int64("user_id", claims.UserID), zap.String("session_id", claims.SessionID))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidRefreshToken.Error()})
+		case errors.Is(err, inmem.ErrSessionNotFound):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidRefreshToken.Error()})
+		default:
+			h.logger.Error("error rotating session", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to refresh session"})
+		}
+		return
+	}
+
+	user, err := h.stor.GetUserById(session.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			h.revoke(c, session.ID)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidRefreshToken.Error()})
+			return
+		}
+		h.logger.Error("error getting user", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to get user"})
+		return
+	}
+
+	// продавца могли лишить роли, пока сессия была открыта
+	if role == models.RoleSeller && user.Role != models.RoleSeller {
+		h.revoke(c, session.ID)
+		c.JSON(http.StatusForbidden, gin.H{"error": errNotSeller.Error()})
+		return
+	}
+
+	newRefreshToken, err := jwt.CreateSessionToken(&user, session.ID, tokenId)
+	if err != nil {
+		h.logger.Error("error creating refresh token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to create refresh token"})
+		return
+	}
+
+	h.issueTokens(c, user, newRefreshToken)
+}
+
+// issueTokens отдает access токен в заголовке Authorization, refresh токен - в cookie
+func (h *Handler) issueTokens(c *gin.Context, user models.User, refreshToken string) {
+	accessToken, err := jwt.CreateAccessToken(&user)
+	if err != nil {
+		h.logger.Error("error creating access token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to create access token"})
+		return
+	}
+
+	h.setRefreshCookie(c, refreshToken, int(h.refreshTTL.Seconds()))
+	c.Header("Authorization", "Bearer "+accessToken)
+}
+
+func (h *Handler) revoke(c *gin.Context, sessionId string) {
+	if err := h.sessions.Revoke(c.Request.Context(), sessionId); err != nil {
+		h.logger.Warn("error revoking session", zap.Error(err))
+	}
+}
+
+// SearchSeller находит одобренного продавца по точному логину. Поиск по части логина,
+// имени и городу - в каталоге /sellers?q=.
+func (h *Handler) SearchSeller(c *gin.Context) {
+	username := c.Query("username")
+	if username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "username is required"})
+		return
+	}
+
+	sellers, err := h.stor.SearchSellersByUsername(username)
+	if err != nil {
+		h.logger.Error("error searching sellers", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to search sellers"})
+		return
+	}
+
+	resp := make([]sellerResponse, 0, len(sellers))
+	for _, seller := range sellers {
+		resp = append(resp, sellerResponse{
+			ID:        seller.UUID,
+			Username:  seller.Username,
+			FullName:  seller.FullName,
+			City:      seller.City,
+			CreatedAt: seller.TimeCreatedAcc,
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) setRefreshCookie(c *gin.Context, value string, maxAge int) {
+	c.SetCookie(refreshCookie, value, maxAge, "/", h.cfg.Domain, h.cfg.SecureCookie, true)
+}
+
+// authenticate - та же проверка, что при входе через grpc и oidc: лимиты попыток, пароль, второй фактор
+func (h *Handler) authenticate(ctx context.Context, username, password, otp, ip string) (models.User, error) {
+	if err := h.guard.Allow(ctx, username, ip); err != nil {
+		var limitErr *loginguard.LimitError
+		if errors.As(err, &limitErr) {
+			return models.User{}, limitErr
+		}
+		h.logger.Error("error checking login limits", zap.Error(err))
+		return models.User{}, errors.New("unable to check login limits")
+	}
+
+	user, err := h.stor.GetUser(username)
+	if err != nil {
+		if !errors.Is(err, storage.ErrUserNotFound) {
+			h.logger.Error("error getting user", zap.Error(err))
+			return models.User{}, errors.New("unable to get user")
+		}
+		return models.User{}, h.loginFailed(ctx, username, "unknown_user")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PassHash), []byte(password)); err != nil {
+		return models.User{}, h.loginFailed(ctx, username, "wrong_password")
+	}
+
+	if user.TOTPEnabled {
+		if otp == "" {
+			return models.User{}, errSecondFactorRequired
+		}
+
+		ok, err := h.useSecondFactor(user, otp)
+		if err != nil {
+			h.logger.Error("error checking second factor", zap.Error(err))
+			return models.User{}, errors.New("unable to check two-factor code")
+		}
+		if !ok {
+			return models.User{}, h.loginFailed(ctx, username, "wrong_second_factor")
+		}
+	}
+
+	if err := h.guard.Success(ctx, username); err != nil {
+		h.logger.Warn("error resetting failed logins", zap.Error(err))
+	}
+
+	if h.verificationCfg.RequireVerifiedEmail && !user.EmailVerified {
+		return models.User{}, errEmailNotVerified
+	}
+
+	return user, nil
+}
+
+func (h *Handler) useSecondFactor(user models.User, code string) (bool, error) {
+	if step, ok := totp.Validate(user.TOTPSecret, code, time.Now()); ok {
+		return h.stor.UseTOTPStep(user.ID, step)
+	}
+
+	return h.stor.UseRecoveryCode(user.ID, tokens.Hash(tokens.NormalizeRecoveryCode(code)))
+}
+
+func (h *Handler) loginFailed(ctx context.Context, username, reason string) error {
+	if err := h.guard.Failure(ctx, username, reason); err != nil {
+		h.logger.Error("error registering failed login", zap.Error(err))
+	}
+
+	return errInvalidCredentials
+}
@@ -0,0 +1,433 @@
+package rest
+
+import (
+	"auth_service/internal/config"
+	"auth_service/internal/jwt"
+	"auth_service/internal/loginguard"
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"auth_service/internal/tokens"
+	"auth_service/internal/totp"
+	"auth_service/pkg/metrics"
+	"auth_service/pkg/storage/inmem"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	testPassword     = "Secret-password-1"
+	testRefreshTTL   = 24 * time.Hour
+	testTOTPSecret   = "JBSWY3DPEHPK3PXP"
+	testRecoveryCode = "abcde-fghij"
+)
+
+// метрики регистрируются в общем реестре prometheus, поэтому создаются один раз на все тесты
+var testLoginMetrics = metrics.NewLoginMetrics()
+
+// fakeStorage - пользователи в памяти; методы, которые тестам не нужны, остаются от nil интерфейса
+type fakeStorage struct {
+	UserStorage
+	users []models.User
+	// totpSteps - последний принятый интервал TOTP, recoveryCodes - хеши кодов и признак использования
+	totpSteps     map[int64]int64
+	recoveryCodes map[string]bool
+}
+
+func (s *fakeStorage) GetUser(username string) (models.User, error) {
+	for _, user := range s.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+
+	return models.User{}, storage.ErrUserNotFound
+}
+
+func (s *fakeStorage) GetUserById(userId int64) (models.User, error) {
+	for _, user := range s.users {
+		if user.ID == userId {
+			return user, nil
+		}
+	}
+
+	return models.User{}, storage.ErrUserNotFound
+}
+
+// UseTOTPStep и UseRecoveryCode повторяют условия UPDATE в storage
+func (s *fakeStorage) UseTOTPStep(userId, step int64) (bool, error) {
+	if s.totpSteps[userId] >= step {
+		return false, nil
+	}
+	s.totpSteps[userId] = step
+
+	return true, nil
+}
+
+func (s *fakeStorage) UseRecoveryCode(userId int64, codeHash string) (bool, error) {
+	used, ok := s.recoveryCodes[codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	s.recoveryCodes[codeHash] = true
+
+	return true, nil
+}
+
+type testServer struct {
+	handler http.Handler
+	redis   *miniredis.Miniredis
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	t.Setenv("REDIS_HOST", mr.Host())
+	t.Setenv("REDIS_PORT", mr.Port())
+	t.Setenv("ACCESS_TOKEN_SECRET", "test-access-secret")
+	t.Setenv("REFRESH_TOKEN_SECRET", "test-refresh-secret")
+	t.Setenv("CHALLENGE_TOKEN_SECRET", "test-challenge-secret")
+	t.Setenv("ACCESS_TOKEN_TTL", "15m")
+	t.Setenv("REFRESH_TOKEN_TTL", testRefreshTTL.String())
+	if err := jwt.LoadSecrets(); err != nil {
+		t.Fatal(err)
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stor := &fakeStorage{users: []models.User{
+		{ID: 1, UUID: "c0ffee00-0000-0000-0000-000000000001", Username: "customer", Email: "customer@example.com", PassHash: string(passHash), Role: models.RoleCustomer, EmailVerified: true},
+		{ID: 2, UUID: "c0ffee00-0000-0000-0000-000000000002", Username: "seller", Email: "seller@example.com", PassHash: string(passHash), Role: models.RoleSeller, EmailVerified: true},
+		{ID: 3, UUID: "c0ffee00-0000-0000-0000-000000000003", Username: "twofactor", Email: "twofactor@example.com", PassHash: string(passHash), Role: models.RoleCustomer, EmailVerified: true,
+			TOTPEnabled: true, TOTPSecret: testTOTPSecret},
+	},
+		totpSteps:     map[int64]int64{},
+		recoveryCodes: map[string]bool{tokens.Hash(testRecoveryCode): false},
+	}
+
+	guardCfg := config.LoginGuardConfig{
+		Window:           time.Minute,
+		UserMaxAttempts:  10,
+		IPMaxAttempts:    50,
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         time.Minute,
+		LockoutThreshold: 5,
+		LockoutDuration:  time.Minute,
+	}
+	guard := loginguard.New(guardCfg, inmem.NewLoginAttemptsStorage(), testLoginMetrics, zap.NewNop())
+
+	srv := New(config.HTTPConfig{Domain: "localhost"}, config.VerificationConfig{}, config.TwoFactorConfig{},
+		stor, inmem.NewSessionStorage(), guard, zap.NewNop())
+
+	return &testServer{handler: srv.server.Handler, redis: mr}
+}
+
+func (s *testServer) do(t *testing.T, path string, body any, refreshToken string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if refreshToken != "" {
+		req.AddCookie(&http.Cookie{Name: refreshCookie, Value: refreshToken})
+	}
+
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *testServer) login(t *testing.T, role, username string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return s.do(t, "/api/auth/login/"+role, loginRequest{Username: username, Password: testPassword}, "")
+}
+
+// issued проверяет, что ответ выдал пару токенов пользователю userId, и возвращает refresh токен
+func issued(t *testing.T, rec *httptest.ResponseRecorder, userId int64) string {
+	t.Helper()
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+
+	accessToken, ok := bytes.CutPrefix([]byte(rec.Header().Get("Authorization")), []byte("Bearer "))
+	if !ok {
+		t.Fatalf("Authorization header = %q, want bearer token", rec.Header().Get("Authorization"))
+	}
+	if id, err := jwt.ParseAccessToken(string(accessToken)); err != nil || id != userId {
+		t.Fatalf("access token user = %d (err: %v), want %d", id, err, userId)
+	}
+
+	cookie := refreshCookieOf(t, rec)
+	if cookie == nil || cookie.Value == "" || cookie.MaxAge <= 0 {
+		t.Fatalf("refresh cookie = %+v, want a persistent token", cookie)
+	}
+	if !cookie.HttpOnly {
+		t.Error("refresh cookie is not http only")
+	}
+
+	return cookie.Value
+}
+
+func refreshCookieOf(t *testing.T, rec *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == refreshCookie {
+			return cookie
+		}
+	}
+
+	return nil
+}
+
+func TestLoginRefreshLogout(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		username string
+		userId   int64
+	}{
+		{"customer", models.RoleCustomer, "customer", 1},
+		{"seller", models.RoleSeller, "seller", 2},
+		{"seller buys as customer", models.RoleCustomer, "seller", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+
+			refreshToken := issued(t, s.login(t, tt.role, tt.username), tt.userId)
+
+			rotated := issued(t, s.do(t, "/api/auth/refresh/"+tt.role, nil, refreshToken), tt.userId)
+			if rotated == refreshToken {
+				t.Fatal("refresh returned the same refresh token")
+			}
+
+			rotated = issued(t, s.do(t, "/api/auth/refresh/"+tt.role, nil, rotated), tt.userId)
+
+			rec := s.do(t, "/api/auth/logout", nil, rotated)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("logout status = %d, want 200", rec.Code)
+			}
+			if cookie := refreshCookieOf(t, rec); cookie == nil || cookie.MaxAge >= 0 {
+				t.Fatalf("logout cookie = %+v, want it removed", cookie)
+			}
+
+			if rec := s.do(t, "/api/auth/refresh/"+tt.role, nil, rotated); rec.Code != http.StatusUnauthorized {
+				t.Fatalf("refresh after logout status = %d, want 401", rec.Code)
+			}
+		})
+	}
+}
+
+func TestLoginRejections(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		username string
+		password string
+		want     int
+	}{
+		{"customer on seller login", models.RoleSeller, "customer", testPassword, http.StatusForbidden},
+		{"wrong password", models.RoleCustomer, "customer", "Wrong-password-1", http.StatusUnauthorized},
+		{"unknown user", models.RoleSeller, "nobody", testPassword, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+
+			rec := s.do(t, "/api/auth/login/"+tt.role, loginRequest{Username: tt.username, Password: tt.password}, "")
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body: %s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if cookie := refreshCookieOf(t, rec); cookie != nil {
+				t.Fatalf("rejected login set refresh cookie %+v", cookie)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	for _, role := range []string{models.RoleCustomer, models.RoleSeller} {
+		t.Run(role, func(t *testing.T) {
+			s := newTestServer(t)
+
+			refreshToken := issued(t, s.login(t, role, "seller"), 2)
+			rotated := issued(t, s.do(t, "/api/auth/refresh/"+role, nil, refreshToken), 2)
+
+			if rec := s.do(t, "/api/auth/refresh/"+role, nil, refreshToken); rec.Code != http.StatusUnauthorized {
+				t.Fatalf("reused token status = %d, want 401", rec.Code)
+			}
+			if rec := s.do(t, "/api/auth/refresh/"+role, nil, rotated); rec.Code != http.StatusUnauthorized {
+				t.Fatalf("token of revoked session status = %d, want 401", rec.Code)
+			}
+		})
+	}
+}
+
+func TestRefreshRequiresSameRole(t *testing.T) {
+	s := newTestServer(t)
+
+	refreshToken := issued(t, s.login(t, models.RoleCustomer, "seller"), 2)
+
+	if rec := s.do(t, "/api/auth/refresh/"+models.RoleSeller, nil, refreshToken); rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", rec.Code)
+	}
+
+	// отказ по роли не расходует токен
+	issued(t, s.do(t, "/api/auth/refresh/"+models.RoleCustomer, nil, refreshToken), 2)
+}
+
+func TestRefreshExtendsUserSessionIndex(t *testing.T) {
+	s := newTestServer(t)
+
+	refreshToken := issued(t, s.login(t, models.RoleSeller, "seller"), 2)
+
+	s.redis.FastForward(testRefreshTTL / 2)
+	issued(t, s.do(t, "/api/auth/refresh/"+models.RoleSeller, nil, refreshToken), 2)
+
+	userKey := "session:user:2"
+	if ttl := s.redis.TTL(userKey); ttl != testRefreshTTL {
+		t.Fatalf("ttl of %s = %s, want %s", userKey, ttl, testRefreshTTL)
+	}
+
+	// после первоначального срока сессия и ее индекс живы, значит RevokeAll ее найдет
+	s.redis.FastForward(testRefreshTTL/2 + time.Minute)
+	if !s.redis.Exists(userKey) {
+		t.Fatalf("%s expired before the session", userKey)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	s := newTestServer(t)
+	wrong := loginRequest{Username: "customer", Password: "Wrong-password-1"}
+	status := func(body loginRequest) int {
+		return s.do(t, "/api/auth/login/"+models.RoleCustomer, body, "").Code
+	}
+
+	// бесплатные попытки и первая неудача сверх них отвечают 401, после нее - пауза
+	for i := 0; i < 4; i++ {
+		if got := status(wrong); got != http.StatusUnauthorized {
+			t.Fatalf("wrong password %d status = %d, want 401", i+1, got)
+		}
+	}
+	if got := s.login(t, models.RoleCustomer, "customer").Code; got != http.StatusTooManyRequests {
+		t.Fatalf("login during delay status = %d, want 429", got)
+	}
+
+	// пятая неудача блокирует логин даже для верного пароля
+	s.redis.FastForward(time.Second)
+	if got := status(wrong); got != http.StatusUnauthorized {
+		t.Fatalf("fifth wrong password status = %d, want 401", got)
+	}
+	if got := s.login(t, models.RoleCustomer, "customer").Code; got != http.StatusTooManyRequests {
+		t.Fatalf("login while locked status = %d, want 429", got)
+	}
+
+	s.redis.FastForward(time.Minute)
+	issued(t, s.login(t, models.RoleCustomer, "customer"), 1)
+
+	// успешный вход обнулил неудачи: новая ошибка не ставит паузу
+	if got := status(wrong); got != http.StatusUnauthorized {
+		t.Fatalf("wrong password after success status = %d, want 401", got)
+	}
+	issued(t, s.login(t, models.RoleCustomer, "customer"), 1)
+}
+
+func TestLoginSecondFactor(t *testing.T) {
+	s := newTestServer(t)
+	login := func(otp string) *httptest.ResponseRecorder {
+		return s.do(t, "/api/auth/login/"+models.RoleCustomer, loginRequest{Username: "twofactor", Password: testPassword, OTP: otp}, "")
+	}
+	codeAt := func(at time.Time) string {
+		code, err := totp.Code(testTOTPSecret, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	if rec := login(""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("login without code status = %d, want 401", rec.Code)
+	}
+	if rec := login("000000"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong code status = %d, want 401", rec.Code)
+	}
+
+	// код прошлого интервала принимается из-за расхождения часов
+	now := time.Now()
+	previous := codeAt(now.Add(-30 * time.Second))
+	issued(t, login(previous), 3)
+
+	current := codeAt(now)
+	issued(t, login(current), 3)
+
+	// ни тот же код, ни код более раннего интервала второй раз не принимаются
+	if rec := login(current); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("reused code status = %d, want 401", rec.Code)
+	}
+	if rec := login(previous); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("older code status = %d, want 401", rec.Code)
+	}
+}
+
+func TestLoginRecoveryCode(t *testing.T) {
+	s := newTestServer(t)
+	login := func(otp string) *httptest.ResponseRecorder {
+		return s.do(t, "/api/auth/login/"+models.RoleCustomer, loginRequest{Username: "twofactor", Password: testPassword, OTP: otp}, "")
+	}
+
+	if rec := login("zzzzz-zzzzz"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown recovery code status = %d, want 401", rec.Code)
+	}
+
+	// код вводят как угодно: заглавными, без дефиса, с пробелами
+	issued(t, login(" ABCDEFGHIJ "), 3)
+
+	if rec := login(testRecoveryCode); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("used recovery code status = %d, want 401", rec.Code)
+	}
+}
+
+func TestLoginWrongSecondFactorLocksOut(t *testing.T) {
+	s := newTestServer(t)
+
+	// неверные коды считаются неудачными входами, как неверный пароль
+	for i := 0; i < 5; i++ {
+		s.redis.FastForward(time.Minute / 2)
+		rec := s.do(t, "/api/auth/login/"+models.RoleCustomer, loginRequest{Username: "twofactor", Password: testPassword, OTP: "000000"}, "")
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong code %d status = %d, want 401", i+1, rec.Code)
+		}
+	}
+
+	rec := s.do(t, "/api/auth/login/"+models.RoleCustomer, loginRequest{Username: "twofactor", Password: testPassword, OTP: testRecoveryCode}, "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("login while locked status = %d, want 429", rec.Code)
+	}
+}
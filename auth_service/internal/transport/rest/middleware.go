@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"auth_service/internal/jwt"
+	"auth_service/internal/models"
+	"auth_service/internal/repositiry/storage"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const userIdKey = "user_id"
+
+// authRequired пускает запросы с действующим access токеном в заголовке Authorization
+// и кладет id пользователя в контекст
+func (h *Handler) authRequired(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "access token is required"})
+		return
+	}
+
+	userId, err := jwt.ParseAccessToken(token)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid access token"})
+		return
+	}
+
+	c.Set(userIdKey, userId)
+	c.Next()
+}
+
+// sellerRequired - роль берется из базы, а не из токена: токен мог быть выдан до смены роли
+func (h *Handler) sellerRequired(c *gin.Context) {
+	user, err := h.stor.GetUserById(c.GetInt64(userIdKey))
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid access token"})
+			return
+		}
+		h.logger.Error("error getting user", zap.Error(err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "unable to get user"})
+		return
+	}
+
+	if user.Role != models.RoleSeller {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errNotSeller.Error()})
+		return
+	}
+
+	c.Next()
+}
+
+// adminRequired - как и в grpc, при обязательной 2FA администратор без нее прав не получает
+func (h *Handler) adminRequired(c *gin.Context) {
+	userId := c.GetInt64(userIdKey)
+
+	isAdmin, err := h.stor.IsAdmin(userId)
+	if err != nil {
+		h.logger.Error("error checking admin rights", zap.Error(err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "unable to check admin rights"})
+		return
+	}
+
+	if isAdmin && h.twoFactorCfg.EnforceForAdmins {
+		user, err := h.stor.GetUserById(userId)
+		if err != nil {
+			h.logger.Error("error getting user", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "unable to get user"})
+			return
+		}
+		if !user.TOTPEnabled {
+			h.logger.Warn("admin rights denied: two-factor authentication is not enabled", zap.Int64("user_id", userId))
+			isAdmin = false
+		}
+	}
+
+	if !isAdmin {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin rights required"})
+		return
+	}
+
+	c.Next()
+}
@@ -0,0 +1,301 @@
+package rest
+
+import (
+	"auth_service/internal/models"
+	"auth_service/internal/onboarding"
+	"auth_service/internal/repositiry/storage"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultApplicationsLimit = 20
+	maxApplicationsLimit     = 100
+)
+
+var sellerStatuses = []string{
+	models.SellerStatusDraft,
+	models.SellerStatusSubmitted,
+	models.SellerStatusUnderReview,
+	models.SellerStatusApproved,
+	models.SellerStatusRejected,
+}
+
+type sellerProfileRequest struct {
+	LegalName          string `json:"legal_name"`
+	LegalForm          string `json:"legal_form"`
+	TaxID              string `json:"tax_id"`
+	RegistrationNumber string `json:"registration_number"`
+	LegalAddress       string `json:"legal_address"`
+	BankAccount        string `json:"bank_account"`
+	BankBIC            string `json:"bank_bic"`
+}
+
+type rejectRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type sellerProfileResponse struct {
+	Status             string     `json:"status"`
+	LegalName          string     `json:"legal_name"`
+	LegalForm          string     `json:"legal_form"`
+	TaxID              string     `json:"tax_id"`
+	RegistrationNumber string     `json:"registration_number"`
+	LegalAddress       string     `json:"legal_address"`
+	BankAccount        string     `json:"bank_account"`
+	BankBIC            string     `json:"bank_bic"`
+	RejectionReason    string     `json:"rejection_reason,omitempty"`
+	SubmittedAt        *time.Time `json:"submitted_at,omitempty"`
+	ReviewedAt         *time.Time `json:"reviewed_at,omitempty"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+type sellerApplicationResponse struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	sellerProfileResponse
+}
+
+type statusChangeResponse struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Comment    string    `json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (h *Handler) GetSellerProfile(c *gin.Context) {
+	profile, err := h.stor.GetSellerProfile(c.GetInt64(userIdKey))
+	if err != nil {
+		h.profileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newSellerProfileResponse(profile))
+}
+
+// UpdateSellerProfile сохраняет реквизиты целиком. Частично заполненная анкета допустима,
+// полноту проверяет отправка на проверку.
+func (h *Handler) UpdateSellerProfile(c *gin.Context) {
+	var req sellerProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	update := models.SellerProfile{
+		UserID:             c.GetInt64(userIdKey),
+		LegalName:          strings.TrimSpace(req.LegalName),
+		LegalForm:          req.LegalForm,
+		TaxID:              req.TaxID,
+		RegistrationNumber: req.RegistrationNumber,
+		LegalAddress:       strings.TrimSpace(req.LegalAddress),
+		BankAccount:        req.BankAccount,
+		BankBIC:            req.BankBIC,
+	}
+	if err := onboarding.Validate(update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.stor.UpdateSellerProfile(update)
+	if err != nil {
+		h.profileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newSellerProfileResponse(profile))
+}
+
+func (h *Handler) SubmitSellerProfile(c *gin.Context) {
+	userId := c.GetInt64(userIdKey)
+	h.changeStatus(c, userId, models.SellerStatusSubmitted, userId, "")
+}
+
+func (h *Handler) ListSellerApplications(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && !slices.Contains(sellerStatuses, status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown seller status"})
+		return
+	}
+
+	limit, err := queryInt(c, "limit", defaultApplicationsLimit)
+	if err != nil || limit <= 0 || limit > maxApplicationsLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+		return
+	}
+
+	offset, err := queryInt(c, "offset", 0)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must not be negative"})
+		return
+	}
+
+	applications, err := h.stor.ListSellerApplications(status, limit, offset)
+	if err != nil {
+		h.logger.Error("error listing seller applications", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to list seller applications"})
+		return
+	}
+
+	resp := make([]sellerApplicationResponse, 0, len(applications))
+	for _, application := range applications {
+		resp = append(resp, sellerApplicationResponse{
+			ID:                    application.UUID,
+			Username:              application.Username,
+			Email:                 application.Email,
+			sellerProfileResponse: newSellerProfileResponse(application.SellerProfile),
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetSellerApplication - анкета продавца и история ее статусов
+func (h *Handler) GetSellerApplication(c *gin.Context) {
+	seller, ok := h.sellerFromPath(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.stor.GetSellerProfile(seller.ID)
+	if err != nil {
+		h.profileError(c, err)
+		return
+	}
+
+	history, err := h.stor.GetSellerStatusHistory(seller.ID)
+	if err != nil {
+		h.logger.Error("error getting seller status history", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to get seller status history"})
+		return
+	}
+
+	changes := make([]statusChangeResponse, 0, len(history))
+	for _, change := range history {
+		changes = append(changes, statusChangeResponse{
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			Comment:    change.Comment,
+			CreatedAt:  change.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"seller": sellerApplicationResponse{
+			ID:                    seller.UUID,
+			Username:              seller.Username,
+			Email:                 seller.Email,
+			sellerProfileResponse: newSellerProfileResponse(profile),
+		},
+		"history": changes,
+	})
+}
+
+func (h *Handler) StartSellerReview(c *gin.Context) {
+	h.review(c, models.SellerStatusUnderReview, "")
+}
+
+func (h *Handler) ApproveSeller(c *gin.Context) {
+	h.review(c, models.SellerStatusApproved, "")
+}
+
+func (h *Handler) RejectSeller(c *gin.Context) {
+	var req rejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rejection reason is required"})
+		return
+	}
+
+	h.review(c, models.SellerStatusRejected, strings.TrimSpace(req.Reason))
+}
+
+func (h *Handler) review(c *gin.Context, to, comment string) {
+	seller, ok := h.sellerFromPath(c)
+	if !ok {
+		return
+	}
+
+	adminId := c.GetInt64(userIdKey)
+	if h.changeStatus(c, seller.ID, to, adminId, comment) {
+		h.logger.Info("seller status changed",
+			zap.String("seller_id", seller.UUID),
+			zap.String("status", to),
+			zap.Int64("admin_id", adminId),
+		)
+	}
+}
+
+func (h *Handler) changeStatus(c *gin.Context, userId int64, to string, actorId int64, comment string) bool {
+	profile, err := h.stor.ChangeSellerStatus(userId, to, actorId, comment)
+	if err != nil {
+		h.profileError(c, err)
+		return false
+	}
+
+	c.JSON(http.StatusOK, newSellerProfileResponse(profile))
+	return true
+}
+
+// sellerFromPath находит продавца по публичному uuid из пути
+func (h *Handler) sellerFromPath(c *gin.Context) (models.User, bool) {
+	seller, err := h.stor.GetSellerByUUID(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "seller not found"})
+			return models.User{}, false
+		}
+		h.logger.Error("error getting seller", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to get seller"})
+		return models.User{}, false
+	}
+
+	return seller, true
+}
+
+func (h *Handler) profileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrSellerProfileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, onboarding.ErrInvalidTransition), errors.Is(err, onboarding.ErrNotEditable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, onboarding.ErrIncomplete):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("error processing seller profile", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to process seller profile"})
+	}
+}
+
+func newSellerProfileResponse(profile models.SellerProfile) sellerProfileResponse {
+	return sellerProfileResponse{
+		Status:             profile.Status,
+		LegalName:          profile.LegalName,
+		LegalForm:          profile.LegalForm,
+		TaxID:              profile.TaxID,
+		RegistrationNumber: profile.RegistrationNumber,
+		LegalAddress:       profile.LegalAddress,
+		BankAccount:        profile.BankAccount,
+		BankBIC:            profile.BankBIC,
+		RejectionReason:    profile.RejectionReason,
+		SubmittedAt:        profile.SubmittedAt,
+		ReviewedAt:         profile.ReviewedAt,
+		UpdatedAt:          profile.UpdatedAt,
+	}
+}
+
+func queryInt(c *gin.Context, key string, def int) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return def, nil
+	}
+
+	return strconv.Atoi(value)
+}
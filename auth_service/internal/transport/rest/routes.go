@@ -0,0 +1,36 @@
+package rest
+
+import "github.com/gin-gonic/gin"
+
+// setRoutes - те же пути, что были у сервиса auth, чтобы клиентам не пришлось ничего менять
+func setRoutes(router *gin.Engine, handler *Handler) {
+	auth := router.Group("/api/auth")
+	{
+		auth.POST("/login/seller", handler.SignInSeller)
+		auth.POST("/register/seller", handler.SignUpSeller)
+		auth.POST("/login/customer", handler.SignInCustomer)
+		auth.POST("/register/customer", handler.SignUpCustomer)
+		auth.POST("/logout", handler.Logout)
+		auth.POST("/refresh/customer", handler.RefreshCustomer)
+		auth.POST("/refresh/seller", handler.RefreshSeller)
+		auth.GET("/search/seller", handler.SearchSeller)
+		auth.GET("/sellers", handler.ListSellers)
+	}
+
+	// анкета продавца: заполняет сам продавец, проверяет администратор
+	seller := auth.Group("/seller", handler.authRequired, handler.sellerRequired)
+	{
+		seller.GET("/profile", handler.GetSellerProfile)
+		seller.PUT("/profile", handler.UpdateSellerProfile)
+		seller.POST("/profile/submit", handler.SubmitSellerProfile)
+	}
+
+	admin := auth.Group("/admin/sellers", handler.authRequired, handler.adminRequired)
+	{
+		admin.GET("", handler.ListSellerApplications)
+		admin.GET("/:id", handler.GetSellerApplication)
+		admin.POST("/:id/review", handler.StartSellerReview)
+		admin.POST("/:id/approve", handler.ApproveSeller)
+		admin.POST("/:id/reject", handler.RejectSeller)
+	}
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"auth_service/internal/config"
+	"auth_service/internal/jwt"
+	"auth_service/internal/loginguard"
+	"auth_service/pkg/storage/inmem"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Server - http api покупателей и продавцов, которое раньше обслуживал отдельный сервис auth.
+// Пользователи и лимиты входа общие с grpc сервисом, сессии входа хранятся отдельно (SessionStorage):
+// у пользователя их может быть несколько, по одной на устройство.
+type Server struct {
+	cfg    config.HTTPConfig
+	server *http.Server
+	logger *zap.Logger
+}
+
+func New(cfg config.HTTPConfig, verificationCfg config.VerificationConfig, twoFactorCfg config.TwoFactorConfig, stor UserStorage, sessions inmem.SessionStorage, guard *loginguard.Guard, logger *zap.Logger) *Server {
+	refreshTTL, err := jwt.RefreshTTL()
+	if err != nil {
+		logger.Warn("error parsing refresh ttl, refresh cookie will expire with the session", zap.Error(err))
+	}
+
+	handler := &Handler{
+		cfg:             cfg,
+		verificationCfg: verificationCfg,
+		twoFactorCfg:    twoFactorCfg,
+		stor:            stor,
+		sessions:        sessions,
+		guard:           guard,
+		refreshTTL:      refreshTTL,
+		logger:          logger,
+	}
+
+	router := gin.Default()
+	// адрес клиента берется из соединения: заголовкам X-Forwarded-For доверять нельзя,
+	// иначе лимиты входа по ip обходятся подменой заголовка
+	if err := router.SetTrustedProxies(nil); err != nil {
+		logger.Fatal("failed to configure trusted proxies", zap.Error(err))
+	}
+	setRoutes(router, handler)
+
+	return &Server{
+		cfg: cfg,
+		server: &http.Server{
+			Addr:              fmt.Sprintf("0.0.0.0:%s", cfg.Port),
+			Handler:           router,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+func (s *Server) MustStart() {
+	s.logger.Info("http auth server start", zap.String("port", s.cfg.Port))
+	err := s.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.logger.Fatal("failed to start http auth server", zap.Error(err))
+	}
+}
+
+func (s *Server) Shutdown(ctx context.Context) {
+	s.logger.Info("http auth server stopping")
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.logger.Error("error stopping http auth server", zap.Error(err))
+	}
+	s.logger.Info("http auth server stopped")
+}
@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MinPasswordLen = 8
+	maxPasswordLen = 72 // bcrypt учитывает только первые 72 байта
+	maxUsernameLen = 64
+	maxFullNameLen = 255
+	maxAddressLen  = 500
+)
+
+var (
+	ErrInvalidEmail    = errors.New("invalid email")
+	ErrInvalidUsername = errors.New("username must be 3-64 characters: letters, digits, '_', '-' or '.'")
+	ErrInvalidPhone    = errors.New("phone must contain 10-15 digits and may start with '+'")
+	ErrInvalidPassword = fmt.Errorf("password must be %d-%d bytes long", MinPasswordLen, maxPasswordLen)
+	ErrFullNameTooLong = fmt.Errorf("full name must be at most %d characters", maxFullNameLen)
+	ErrAddressTooLong  = fmt.Errorf("address must be at most %d characters", maxAddressLen)
+
+	ErrLegalNameTooLong          = fmt.Errorf("legal name must be at most %d characters", maxFullNameLen)
+	ErrInvalidLegalForm          = errors.New("legal form must be one of: individual, llc, jsc, self_employed")
+	ErrInvalidTaxID              = errors.New("tax id must contain 10 or 12 digits")
+	ErrInvalidRegistrationNumber = errors.New("registration number must contain 13 or 15 digits")
+	ErrInvalidBankAccount        = errors.New("bank account must contain 20 digits")
+	ErrInvalidBankBIC            = errors.New("bank bic must contain 9 digits")
+)
+
+var (
+	usernameRe = regexp.MustCompile(`^[a-zA-Z0-9_.\-]{3,64}$`)
+	phoneRe    = regexp.MustCompile(`^\+?[0-9]{10,15}$`)
+
+	taxIDRe              = regexp.MustCompile(`^([0-9]{10}|[0-9]{12})$`)
+	registrationNumberRe = regexp.MustCompile(`^([0-9]{13}|[0-9]{15})$`)
+	bankAccountRe        = regexp.MustCompile(`^[0-9]{20}$`)
+	bankBICRe            = regexp.MustCompile(`^[0-9]{9}$`)
+)
+
+// LegalForms - организационно-правовые формы продавца: ИП, ООО, АО, самозанятый
+var LegalForms = []string{"individual", "llc", "jsc", "self_employed"}
+
+func Email(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return ErrInvalidEmail
+	}
+
+	return nil
+}
+
+func Username(username string) error {
+	if len(username) > maxUsernameLen || !usernameRe.MatchString(username) {
+		return ErrInvalidUsername
+	}
+
+	return nil
+}
+
+func Password(password string) error {
+	if len(password) < MinPasswordLen || len(password) > maxPasswordLen {
+		return ErrInvalidPassword
+	}
+
+	return nil
+}
+
+// Phone допускает пустое значение: телефон в профиле необязателен
+func Phone(phone string) error {
+	if phone == "" {
+		return nil
+	}
+
+	normalized := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(phone)
+	if !phoneRe.MatchString(normalized) {
+		return ErrInvalidPhone
+	}
+
+	return nil
+}
+
+func FullName(name string) error {
+	if utf8.RuneCountInString(name) > maxFullNameLen {
+		return ErrFullNameTooLong
+	}
+
+	return nil
+}
+
+func Address(address string) error {
+	if utf8.RuneCountInString(address) > maxAddressLen {
+		return ErrAddressTooLong
+	}
+
+	return nil
+}
+
+// Реквизиты продавца проверяются только по формату, пустое значение допустимо (анкета еще заполняется)
+
+func LegalName(name string) error {
+	if utf8.RuneCountInString(name) > maxFullNameLen {
+		return ErrLegalNameTooLong
+	}
+
+	return nil
+}
+
+func LegalForm(form string) error {
+	if form == "" || slices.Contains(LegalForms, form) {
+		return nil
+	}
+
+	return ErrInvalidLegalForm
+}
+
+func TaxID(taxID string) error {
+	return matchOptional(taxIDRe, taxID, ErrInvalidTaxID)
+}
+
+func RegistrationNumber(number string) error {
+	return matchOptional(registrationNumberRe, number, ErrInvalidRegistrationNumber)
+}
+
+func BankAccount(account string) error {
+	return matchOptional(bankAccountRe, account, ErrInvalidBankAccount)
+}
+
+func BankBIC(bic string) error {
+	return matchOptional(bankBICRe, bic, ErrInvalidBankBIC)
+}
+
+func matchOptional(re *regexp.Regexp, value string, err error) error {
+	if value == "" || re.MatchString(value) {
+		return nil
+	}
+
+	return err
+}
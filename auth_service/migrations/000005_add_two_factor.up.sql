@@ -0,0 +1,13 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS totp_secret TEXT DEFAULT '' NOT NULL,
+ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN DEFAULT FALSE NOT NULL,
+ADD COLUMN IF NOT EXISTS totp_last_step BIGINT DEFAULT 0 NOT NULL;
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS recovery_codes_user_idx ON recovery_codes (user_id);
@@ -0,0 +1,5 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS updated_at,
+DROP COLUMN IF EXISTS address,
+DROP COLUMN IF EXISTS phone,
+DROP COLUMN IF EXISTS full_name;
@@ -0,0 +1,5 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS full_name TEXT DEFAULT '' NOT NULL,
+ADD COLUMN IF NOT EXISTS phone TEXT DEFAULT '' NOT NULL,
+ADD COLUMN IF NOT EXISTS address TEXT DEFAULT '' NOT NULL,
+ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL;
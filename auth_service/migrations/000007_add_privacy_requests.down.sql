@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS privacy_request_steps;
+
+DROP TABLE IF EXISTS privacy_requests;
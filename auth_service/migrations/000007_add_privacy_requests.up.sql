@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS privacy_requests (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    status TEXT DEFAULT 'pending' NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS privacy_requests_status_idx ON privacy_requests (status);
+
+CREATE TABLE IF NOT EXISTS privacy_request_steps (
+    request_id INTEGER NOT NULL REFERENCES privacy_requests(id) ON DELETE CASCADE,
+    service TEXT NOT NULL,
+    status TEXT DEFAULT 'pending' NOT NULL,
+    attempts INTEGER DEFAULT 0 NOT NULL,
+    last_error TEXT DEFAULT '' NOT NULL,
+    data JSONB,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (request_id, service)
+);
@@ -0,0 +1,22 @@
+DROP TABLE IF EXISTS legacy_user_ids;
+
+CREATE TABLE IF NOT EXISTS admins (
+    id SERIAL PRIMARY KEY,
+    username text NOT NULL UNIQUE,
+    email text NOT NULL UNIQUE
+);
+
+INSERT INTO admins (id, username, email)
+SELECT id, username, email FROM users WHERE role = 'admin';
+
+SELECT setval('admins_id_seq', GREATEST((SELECT MAX(id) FROM admins), 1));
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'user';
+UPDATE users SET role = 'user' WHERE role IN ('customer', 'admin');
+
+DROP INDEX IF EXISTS users_uuid_idx;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS city,
+DROP COLUMN IF EXISTS uuid;
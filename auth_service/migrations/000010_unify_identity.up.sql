@@ -0,0 +1,26 @@
+-- общая схема пользователей для grpc api и http маршрутов покупателей и продавцов:
+-- uuid - публичный идентификатор (seller_id в товарах), роли вместо отдельной таблицы admins
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS uuid UUID DEFAULT gen_random_uuid() NOT NULL,
+ADD COLUMN IF NOT EXISTS city TEXT DEFAULT '' NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_uuid_idx ON users (uuid);
+
+UPDATE users SET role = 'customer' WHERE role = 'user';
+UPDATE users SET role = 'admin' WHERE id IN (SELECT id FROM admins);
+
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'customer';
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('customer', 'seller', 'admin'));
+
+DROP TABLE IF EXISTS admins;
+
+-- идентификаторы пользователей старого сервиса auth; один пользователь может иметь несколько,
+-- если при переносе объединялись аккаунты покупателя и продавца с одной почтой
+CREATE TABLE IF NOT EXISTS legacy_user_ids (
+    legacy_id UUID PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    legacy_role TEXT NOT NULL,
+    migrated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS legacy_user_ids_user_id_idx ON legacy_user_ids (user_id);
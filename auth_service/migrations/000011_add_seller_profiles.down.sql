@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS seller_status_history;
+DROP TABLE IF EXISTS seller_profiles;
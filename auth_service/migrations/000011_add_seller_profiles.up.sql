@@ -0,0 +1,39 @@
+-- анкета продавца: черновик -> отправлена -> на проверке -> одобрена или отклонена.
+-- Публиковать товары могут только продавцы со статусом approved.
+CREATE TABLE IF NOT EXISTS seller_profiles (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT DEFAULT 'draft' NOT NULL CHECK (status IN ('draft', 'submitted', 'under_review', 'approved', 'rejected')),
+    legal_name TEXT DEFAULT '' NOT NULL,
+    legal_form TEXT DEFAULT '' NOT NULL,
+    tax_id TEXT DEFAULT '' NOT NULL,
+    registration_number TEXT DEFAULT '' NOT NULL,
+    legal_address TEXT DEFAULT '' NOT NULL,
+    bank_account TEXT DEFAULT '' NOT NULL,
+    bank_bic TEXT DEFAULT '' NOT NULL,
+    rejection_reason TEXT DEFAULT '' NOT NULL,
+    reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    submitted_at TIMESTAMP,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS seller_profiles_status_idx ON seller_profiles (status, submitted_at);
+
+-- история смены статусов для разбора спорных решений
+CREATE TABLE IF NOT EXISTS seller_status_history (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    comment TEXT DEFAULT '' NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS seller_status_history_user_id_idx ON seller_status_history (user_id);
+
+-- продавцы, которые уже торгуют, проверку проходить не должны
+INSERT INTO seller_profiles (user_id, status, reviewed_at)
+SELECT id, 'approved', CURRENT_TIMESTAMP FROM users WHERE role = 'seller'
+ON CONFLICT (user_id) DO NOTHING;
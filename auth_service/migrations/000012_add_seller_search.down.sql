@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS users_role_created_acc_idx;
+DROP INDEX IF EXISTS users_city_trgm_idx;
+DROP INDEX IF EXISTS users_full_name_trgm_idx;
+DROP INDEX IF EXISTS users_username_trgm_idx;
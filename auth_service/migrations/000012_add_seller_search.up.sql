@@ -0,0 +1,8 @@
+-- каталог продавцов: нечеткий поиск по логину, имени и городу через триграммы
+-- (в том числе по началу логина) и сортировка по дате регистрации
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS users_username_trgm_idx ON users USING GIN (username gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_full_name_trgm_idx ON users USING GIN (full_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_city_trgm_idx ON users USING GIN (city gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_role_created_acc_idx ON users (role, created_acc);
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	TypeEmailVerification = "email_verification"
+	TypePasswordReset     = "password_reset"
+)
+
+// Event - письмо, которое должен отправить notification_service
+type Event struct {
+	Type      string    `json:"type"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+type redisPublisher struct {
+	client  *redis.Client
+	channel string
+}
+
+func (r *redisPublisher) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshalling event: %w", err)
+	}
+
+	if err := r.client.Publish(ctx, r.channel, payload).Err(); err != nil {
+		return fmt.Errorf("error publishing event: %w", err)
+	}
+
+	slog.Info("event published", slog.String("type", event.Type), slog.String("channel", r.channel))
+
+	return nil
+}
+
+func NewRedisPublisher(channel string) Publisher {
+	host := os.Getenv("REDIS_HOST")
+	port := os.Getenv("REDIS_PORT")
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("%s:%s", host, port),
+		DB:   1,
+	})
+
+	return &redisPublisher{
+		client:  redisClient,
+		channel: channel,
+	}
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// logPath - файл, который читает fluent-bit (input auth.log)
+const logPath = "/var/log/auth/auth.log"
+
+func InitLogger(env string) (*zap.Logger, error) {
+	var level zapcore.Level
+	switch strings.ToLower(env) {
+	case "dev", "local":
+		level = zapcore.DebugLevel
+	default:
+		level = zapcore.InfoLevel
+	}
+
+	if err := os.MkdirAll("/var/log/auth", 0755); err != nil {
+		return nil, fmt.Errorf("cannot create log directory: %w", err)
+	}
+
+	// Конфигурация ротации логов через lumberjack
+	logFile := &lumberjack.Logger{
+		Filename:   logPath,
+		MaxSize:    10, // MB
+		MaxBackups: 5,  // количество архивов
+		MaxAge:     7,  // дней
+	}
+
+	encoderCfg := zapcore.EncoderConfig{
+		TimeKey:      "time",
+		LevelKey:     "level",
+		MessageKey:   "msg",
+		CallerKey:    "caller",
+		EncodeTime:   zapcore.ISO8601TimeEncoder,
+		EncodeLevel:  zapcore.CapitalLevelEncoder,
+		EncodeCaller: zapcore.ShortCallerEncoder,
+	}
+
+	// в файл для fluent-bit и в stdout для docker logs
+	core := zapcore.NewTee(
+		zapcore.NewCore(zapcore.NewJSONEncoder(encoderCfg), zapcore.AddSync(logFile), level),
+		zapcore.NewCore(zapcore.NewJSONEncoder(encoderCfg), zapcore.Lock(os.Stdout), level),
+	)
+
+	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+
+	serviceName := os.Getenv("SERVICE_NAME")
+	if serviceName != "" {
+		logger = logger.With(zap.String("service", serviceName))
+	}
+
+	return logger, nil
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type LoginMetrics struct {
+	failures *prometheus.CounterVec
+	rejected *prometheus.CounterVec
+	lockouts prometheus.Counter
+	unlocks  prometheus.Counter
+}
+
+func NewLoginMetrics() *LoginMetrics {
+	var lm = &LoginMetrics{
+		failures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "auth_service_login_failures_total",
+				Help: "Total number of failed login attempts",
+			},
+			[]string{"reason"},
+		),
+		rejected: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "auth_service_login_rejected_total",
+				Help: "Total number of login attempts rejected by rate limits or lockouts",
+			},
+			[]string{"reason"},
+		),
+		lockouts: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "auth_service_login_lockouts_total",
+				Help: "Total number of temporary account lockouts",
+			},
+		),
+		unlocks: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "auth_service_login_unlocks_total",
+				Help: "Total number of accounts unlocked by admins",
+			},
+		),
+	}
+	prometheus.MustRegister(lm.failures)
+	prometheus.MustRegister(lm.rejected)
+	prometheus.MustRegister(lm.lockouts)
+	prometheus.MustRegister(lm.unlocks)
+
+	return lm
+}
+
+func (lm *LoginMetrics) Failure(reason string) {
+	lm.failures.WithLabelValues(reason).Inc()
+}
+
+func (lm *LoginMetrics) Rejected(reason string) {
+	lm.rejected.WithLabelValues(reason).Inc()
+}
+
+func (lm *LoginMetrics) Lockout() {
+	lm.lockouts.Inc()
+}
+
+func (lm *LoginMetrics) Unlock() {
+	lm.unlocks.Inc()
+}
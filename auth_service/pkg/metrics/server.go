@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// MustServe отдает метрики Prometheus на /metrics, grpc сервер сам по себе http не обслуживает
+func MustServe(port string, logger *zap.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	logger.Info("metrics server start", zap.String("port", port))
+	err := http.ListenAndServe(fmt.Sprintf("0.0.0.0:%s", port), mux)
+	if err != nil {
+		logger.Fatal("failed to start metrics server", zap.Error(err))
+	}
+}
@@ -1,9 +1,41 @@
 package inmem
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type RefreshTokenStorage interface {
 	SaveToken(ctx context.Context, userId int64, token string) error
 	GetToken(ctx context.Context, userId int64) (string, error)
 	RemoveToken(ctx context.Context, userId int64) error
 }
+
+// LoginAttemptsStorage хранит скользящие окна попыток входа и временные блокировки.
+type LoginAttemptsStorage interface {
+	// AddAttempt добавляет попытку в окно key и возвращает число попыток за последние window.
+	AddAttempt(ctx context.Context, key string, window time.Duration) (int64, error)
+	Block(ctx context.Context, key string, ttl time.Duration) error
+	// BlockedFor возвращает оставшееся время блокировки или 0, если блокировки нет.
+	BlockedFor(ctx context.Context, key string) (time.Duration, error)
+	Remove(ctx context.Context, keys ...string) error
+}
+
+// SocialStateStorage хранит одноразовое состояние входа через внешнего провайдера (state, nonce, PKCE).
+type SocialStateStorage interface {
+	Save(ctx context.Context, state string, value []byte, ttl time.Duration) error
+	// Pop возвращает и сразу удаляет состояние, повторно использовать state нельзя.
+	Pop(ctx context.Context, state string) ([]byte, error)
+}
+
+// SessionStorage хранит http сессии входа. Refresh токен сессии одноразовый: Rotate заменяет его jti,
+// а предъявление уже использованного токена означает утечку и отзывает всю сессию.
+type SessionStorage interface {
+	Create(ctx context.Context, session Session, ttl time.Duration) error
+	Get(ctx context.Context, id string) (Session, error)
+	// Rotate меняет jti текущего токена сессии с oldTokenId на newTokenId и продлевает сессию на ttl.
+	Rotate(ctx context.Context, id, oldTokenId, newTokenId string, ttl time.Duration) (Session, error)
+	Revoke(ctx context.Context, id string) error
+	// RevokeAll отзывает все сессии пользователя, например после сброса пароля.
+	RevokeAll(ctx context.Context, userId int64) error
+}
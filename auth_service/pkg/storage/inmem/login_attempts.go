@@ -0,0 +1,79 @@
+package inmem
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisLoginAttempts struct {
+	client *redis.Client
+}
+
+func (r *redisLoginAttempts) AddAttempt(ctx context.Context, key string, window time.Duration) (int64, error) {
+	now := time.Now()
+
+	var card *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Add(-window).UnixNano(), 10))
+		pipe.ZAdd(ctx, key, redis.Z{
+			Score:  float64(now.UnixNano()),
+			Member: fmt.Sprintf("%d-%d", now.UnixNano(), rand.Int63()),
+		})
+		card = pipe.ZCard(ctx, key)
+		pipe.Expire(ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error adding login attempt: %w", err)
+	}
+
+	return card.Val(), nil
+}
+
+func (r *redisLoginAttempts) Block(ctx context.Context, key string, ttl time.Duration) error {
+	if err := r.client.Set(ctx, key, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("error blocking %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (r *redisLoginAttempts) BlockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("error getting ttl of %s: %w", key, err)
+	}
+
+	// -2 - ключа нет, -1 - ключ без срока жизни; блокировки без срока мы не ставим
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (r *redisLoginAttempts) Remove(ctx context.Context, keys ...string) error {
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("error removing login attempts: %w", err)
+	}
+
+	return nil
+}
+
+func NewLoginAttemptsStorage() LoginAttemptsStorage {
+	host := os.Getenv("REDIS_HOST")
+	port := os.Getenv("REDIS_PORT")
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("%s:%s", host, port),
+	})
+
+	return &redisLoginAttempts{
+		client: redisClient,
+	}
+}
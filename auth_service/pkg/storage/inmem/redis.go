@@ -17,7 +17,7 @@ func (r *redisStorage) SaveToken(ctx context.Context, userId int64, token string
 	exp := os.Getenv("REFRESH_TOKEN_TTL")
 	ttl, err := time.ParseDuration(exp)
 	if err != nil {
-		slog.Error("error parsing refresh token ttl", err)
+		slog.Error("error parsing refresh token ttl", slog.Any("error", err))
 		return err
 	}
 	r.client.Set(ctx, fmt.Sprintf("%d", userId), token, ttl)
@@ -30,7 +30,7 @@ func (r *redisStorage) SaveToken(ctx context.Context, userId int64, token string
 func (r *redisStorage) GetToken(ctx context.Context, userId int64) (string, error) {
 	res, err := r.client.Get(ctx, fmt.Sprintf("%d", userId)).Result()
 	if err != nil {
-		slog.Error("error getting token from redis", err)
+		slog.Error("error getting token from redis", slog.Any("error", err))
 		return "", err
 	}
 	if res == "" {
@@ -46,7 +46,7 @@ func (r *redisStorage) GetToken(ctx context.Context, userId int64) (string, erro
 func (r *redisStorage) RemoveToken(ctx context.Context, userId int64) error {
 	err := r.client.Del(ctx, fmt.Sprintf("%d", userId)).Err()
 	if err != nil {
-		slog.Error("error deleting token from redis", err)
+		slog.Error("error deleting token from redis", slog.Any("error", err))
 		return err
 	}
 
@@ -0,0 +1,177 @@
+package inmem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	sessionPrefix      = "session:"
+	userSessionsPrefix = "session:user:"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrTokenReused     = errors.New("refresh token has already been used")
+)
+
+// Session - http сессия пользователя. Role - роль, для которой открыта сессия (customer или seller),
+// TokenID - jti текущего refresh токена.
+type Session struct {
+	ID        string
+	UserID    int64
+	Role      string
+	TokenID   string
+	CreatedAt time.Time
+}
+
+// rotateScript сравнивает и заменяет jti атомарно, чтобы два параллельных обновления
+// одним токеном не получили две действующие пары. Вместе с сессией продлевается и список
+// сессий пользователя, иначе он истекает раньше сессии и RevokeAll ее не найдет.
+var rotateScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], 'token_id')
+if not current then
+	return 0
+end
+if current ~= ARGV[1] then
+	return -1
+end
+redis.call('HSET', KEYS[1], 'token_id', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+local userId = redis.call('HGET', KEYS[1], 'user_id')
+if userId then
+	redis.call('PEXPIRE', ARGV[4] .. userId, ARGV[3])
+end
+return 1
+`)
+
+type redisSessions struct {
+	client *redis.Client
+}
+
+func (r *redisSessions) Create(ctx context.Context, session Session, ttl time.Duration) error {
+	key := sessionPrefix + session.ID
+	userKey := userSessionsPrefix + strconv.FormatInt(session.UserID, 10)
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"user_id", session.UserID,
+			"role", session.Role,
+			"token_id", session.TokenID,
+			"created_at", session.CreatedAt.Unix(),
+		)
+		pipe.Expire(ctx, key, ttl)
+		pipe.SAdd(ctx, userKey, session.ID)
+		pipe.Expire(ctx, userKey, ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error creating session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *redisSessions) Rotate(ctx context.Context, id, oldTokenId, newTokenId string, ttl time.Duration) (Session, error) {
+	res, err := rotateScript.Run(ctx, r.client, []string{sessionPrefix + id}, oldTokenId, newTokenId, ttl.Milliseconds(), userSessionsPrefix).Int()
+	if err != nil {
+		return Session{}, fmt.Errorf("error rotating session: %w", err)
+	}
+
+	switch res {
+	case 0:
+		return Session{}, ErrSessionNotFound
+	case -1:
+		if err := r.Revoke(ctx, id); err != nil {
+			return Session{}, err
+		}
+		return Session{}, ErrTokenReused
+	}
+
+	return r.Get(ctx, id)
+}
+
+func (r *redisSessions) Revoke(ctx context.Context, id string) error {
+	key := sessionPrefix + id
+
+	userId, err := r.client.HGet(ctx, key, "user_id").Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.SRem(ctx, userSessionsPrefix+userId, id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *redisSessions) RevokeAll(ctx context.Context, userId int64) error {
+	userKey := userSessionsPrefix + strconv.FormatInt(userId, 10)
+
+	ids, err := r.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	keys := []string{userKey}
+	for _, id := range ids {
+		keys = append(keys, sessionPrefix+id)
+	}
+
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *redisSessions) Get(ctx context.Context, id string) (Session, error) {
+	values, err := r.client.HGetAll(ctx, sessionPrefix+id).Result()
+	if err != nil {
+		return Session{}, fmt.Errorf("error getting session: %w", err)
+	}
+	if len(values) == 0 {
+		return Session{}, ErrSessionNotFound
+	}
+
+	userId, err := strconv.ParseInt(values["user_id"], 10, 64)
+	if err != nil {
+		return Session{}, fmt.Errorf("invalid session %s: %w", id, err)
+	}
+	createdAt, _ := strconv.ParseInt(values["created_at"], 10, 64)
+
+	return Session{
+		ID:        id,
+		UserID:    userId,
+		Role:      values["role"],
+		TokenID:   values["token_id"],
+		CreatedAt: time.Unix(createdAt, 0),
+	}, nil
+}
+
+func NewSessionStorage() SessionStorage {
+	host := os.Getenv("REDIS_HOST")
+	port := os.Getenv("REDIS_PORT")
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("%s:%s", host, port),
+	})
+
+	return &redisSessions{
+		client: redisClient,
+	}
+}
@@ -0,0 +1,51 @@
+package inmem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const socialStatePrefix = "social:state:"
+
+var ErrStateNotFound = errors.New("state not found")
+
+type redisSocialState struct {
+	client *redis.Client
+}
+
+func (r *redisSocialState) Save(ctx context.Context, state string, value []byte, ttl time.Duration) error {
+	if err := r.client.Set(ctx, socialStatePrefix+state, value, ttl).Err(); err != nil {
+		return fmt.Errorf("error saving social login state: %w", err)
+	}
+
+	return nil
+}
+
+func (r *redisSocialState) Pop(ctx context.Context, state string) ([]byte, error) {
+	value, err := r.client.GetDel(ctx, socialStatePrefix+state).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrStateNotFound
+		}
+		return nil, fmt.Errorf("error getting social login state: %w", err)
+	}
+
+	return value, nil
+}
+
+func NewSocialStateStorage() SocialStateStorage {
+	host := os.Getenv("REDIS_HOST")
+	port := os.Getenv("REDIS_PORT")
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("%s:%s", host, port),
+	})
+
+	return &redisSocialState{
+		client: redisClient,
+	}
+}
@@ -0,0 +1,10 @@
+#!/bin/bash
+
+set -e
+
+# база auth остается от старого сервиса auth: из нее identitymigrate переносит пользователей.
+# Скрипт выполняется только на пустом томе, на существующем базу identity создает сам сервис при старте
+psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" <<-EOSQL
+    CREATE DATABASE identity;
+    GRANT ALL PRIVILEGES ON DATABASE identity TO root;
+EOSQL
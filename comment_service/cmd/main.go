@@ -78,6 +78,10 @@ func main() {
 	ratingService := service.NewRatingService(ratingRepo)
 	ratingHandler := handler.NewRatingHandler(ratingService)
 
+	privacyRepo := repository.NewPrivacyRepository(db)
+	privacyService := service.NewPrivacyService(privacyRepo)
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+
 	// Настройка маршрутов
 	r := gin.Default()
 
@@ -101,6 +105,13 @@ func main() {
 		ratings.PUT("/:id", ratingHandler.UpdateRating)
 	}
 
+	// Внутренние маршруты для выгрузки и удаления данных пользователя по запросу auth_service
+	internal := r.Group("/internal/users", privacyHandler.RequireInternalToken)
+	{
+		internal.GET("/:user_id/export", privacyHandler.ExportUserData)
+		internal.POST("/:user_id/erase", privacyHandler.EraseUserData)
+	}
+
 	// Запуск сервера в горутине
 	go func() {
 		if err := r.Run(":8084"); err != nil {
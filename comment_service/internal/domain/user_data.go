@@ -0,0 +1,7 @@
+package domain
+
+// UserData - все комментарии и оценки пользователя для выгрузки по запросу auth_service
+type UserData struct {
+	Comments []Comment `json:"comments"`
+	Ratings  []Rating  `json:"ratings"`
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"comment_service/internal/service"
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// PrivacyHandler реализует внутренний контракт выгрузки и удаления данных, который вызывает auth_service
+type PrivacyHandler struct {
+	service *service.PrivacyService
+	token   string
+}
+
+func NewPrivacyHandler(service *service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		service: service,
+		token:   os.Getenv("INTERNAL_API_TOKEN"),
+	}
+}
+
+// RequireInternalToken пропускает только запросы с общим токеном INTERNAL_API_TOKEN
+func (h *PrivacyHandler) RequireInternalToken(c *gin.Context) {
+	got := c.GetHeader("X-Internal-Token")
+	if h.token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid internal token"})
+		return
+	}
+	c.Next()
+}
+
+func (h *PrivacyHandler) ExportUserData(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	data, err := h.service.ExportUserData(uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+func (h *PrivacyHandler) EraseUserData(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.service.EraseUserData(uint(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user data erased"})
+}
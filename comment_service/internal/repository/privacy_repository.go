@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"comment_service/internal/domain"
+	"gorm.io/gorm"
+	"time"
+)
+
+// anonymizedUserID - владелец обезличенных оценок. Оценки остаются, чтобы не менять средний рейтинг товаров.
+const anonymizedUserID = 0
+
+type PrivacyRepository struct {
+	db *gorm.DB
+}
+
+func NewPrivacyRepository(db *gorm.DB) *PrivacyRepository {
+	return &PrivacyRepository{db: db}
+}
+
+func (r *PrivacyRepository) ExportUserData(userID uint) (domain.UserData, error) {
+	var data domain.UserData
+	if err := r.db.Where("user_id = ?", userID).Find(&data.Comments).Error; err != nil {
+		return domain.UserData{}, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Find(&data.Ratings).Error; err != nil {
+		return domain.UserData{}, err
+	}
+	return data, nil
+}
+
+// EraseUserData удаляет комментарии пользователя и обезличивает его оценки
+func (r *PrivacyRepository) EraseUserData(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.Comment{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Rating{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"user_id":    anonymizedUserID,
+			"updated_at": time.Now(),
+		}).Error
+	})
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"comment_service/internal/domain"
+	"comment_service/internal/repository"
+)
+
+type PrivacyService struct {
+	repo *repository.PrivacyRepository
+}
+
+func NewPrivacyService(repo *repository.PrivacyRepository) *PrivacyService {
+	return &PrivacyService{repo: repo}
+}
+
+func (s *PrivacyService) ExportUserData(userID uint) (domain.UserData, error) {
+	return s.repo.ExportUserData(userID)
+}
+
+func (s *PrivacyService) EraseUserData(userID uint) error {
+	return s.repo.EraseUserData(userID)
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "encoding/json"
+
+// SearchItemDTO - карточка товара из результатов поиска с оценкой релевантности
+// и подсвеченными фрагментами name и description
+type SearchItemDTO struct {
+	ResponseDTO
+	Score     float64             `json:"score"`
+	Highlight map[string][]string `json:"highlight,omitempty"`
+}
+
+// SearchResponseDTO - страница поиска. Facets передаются из search_service как есть,
+// next_search_after нужно передать в следующий запрос, чтобы получить следующую страницу,
+// ranking - в показы и клики по этой выдаче. missing - найденные товары, которых уже нет
+// в каталоге: в items их нет, total их учитывает.
+type SearchResponseDTO struct {
+	Total           int64           `json:"total"`
+	Items           []SearchItemDTO `json:"items"`
+	Missing         []string        `json:"missing,omitempty"`
+	Facets          json.RawMessage `json:"facets"`
+	NextSearchAfter string          `json:"next_search_after,omitempty"`
+	Ranking         string          `json:"ranking"`
+}
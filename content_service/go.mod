@@ -3,9 +3,11 @@ module content_service
 go 1.24.2
 
 require (
-	github.com/artemSorokin1/products-grpc-api v1.0.7
-	github.com/artemSorokin1/sellers-grpc-api v0.0.1
+	github.com/artemSorokin1/products-grpc-api v1.1.0
+	github.com/artemSorokin1/sellers-grpc-api v0.1.0
+	github.com/google/uuid v1.6.0
 	google.golang.org/grpc v1.72.2
+	grpcsec v0.0.0
 )
 
 require (
@@ -24,3 +26,11 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
 	google.golang.org/protobuf v1.36.6 // indirect
 )
+
+replace grpcsec => ../grpcsec
+
+// api генерируется в репозитории из sellers_service/api/seller.proto, см. proto/generate.sh
+replace github.com/artemSorokin1/sellers-grpc-api => ../proto/sellers-grpc-api
+
+// api генерируется в репозитории из products_service/api/product.proto, см. proto/generate.sh
+replace github.com/artemSorokin1/products-grpc-api => ../proto/products-grpc-api
@@ -2,6 +2,7 @@ package product_client
 
 import (
 	"context"
+	"grpcsec"
 	"time"
 
 	pb "github.com/artemSorokin1/products-grpc-api/gen/go/product"
@@ -14,7 +15,12 @@ type ProductsClient struct {
 }
 
 func NewProductsClient() (*ProductsClient, error) {
-	conn, err := grpc.Dial("products_service:50051", grpc.WithInsecure())
+	transport, err := grpcsec.DialOption(context.Background(), grpcsec.ConfigFromEnv(), "products_service")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient("products_service:50051", transport)
 	if err != nil {
 		return nil, err
 	}
@@ -33,3 +39,19 @@ func (c *ProductsClient) GetProduct(ctx context.Context, id string) (*pb.Product
 	}
 	return resp.GetProduct(), nil
 }
+
+// BatchGetProducts - товары ids одним запросом, по id. Товаров, которых нет в каталоге, в ответе нет.
+func (c *ProductsClient) BatchGetProducts(ctx context.Context, ids []string) (map[string]*pb.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := c.grpcClient.BatchGetProducts(ctx, &pb.BatchGetProductsRequest{Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+	products := make(map[string]*pb.Product, len(resp.GetProducts()))
+	for _, product := range resp.GetProducts() {
+		products[product.GetId()] = product
+	}
+	return products, nil
+}
@@ -2,6 +2,7 @@ package seller_client
 
 import (
 	"context"
+	"grpcsec"
 	"time"
 
 	pb "github.com/artemSorokin1/sellers-grpc-api/gen/go/seller"
@@ -13,7 +14,12 @@ type SellersClient struct {
 }
 
 func NewSellersClient() (*SellersClient, error) {
-	conn, err := grpc.Dial("auth_service:50052", grpc.WithInsecure())
+	transport, err := grpcsec.DialOption(context.Background(), grpcsec.ConfigFromEnv(), "sellers_service")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient("sellers_service:50052", transport)
 	if err != nil {
 		return nil, err
 	}
@@ -29,3 +35,18 @@ func (c *SellersClient) GetSeller(ctx context.Context, productID string) (*pb.Se
 	}
 	return resp.GetSeller(), nil
 }
+
+// BatchGetSellers - продавцы ids одним запросом, по id. Продавцов, которых нет в sellers_service, в ответе нет.
+func (c *SellersClient) BatchGetSellers(ctx context.Context, ids []string) (map[string]*pb.Seller, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	resp, err := c.grpcClient.BatchGetSellers(ctx, &pb.BatchGetSellersRequest{Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+	sellers := make(map[string]*pb.Seller, len(resp.GetSellers()))
+	for _, seller := range resp.GetSellers() {
+		sellers[seller.GetId()] = seller
+	}
+	return sellers, nil
+}
@@ -7,11 +7,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
+	sellerpb "github.com/artemSorokin1/sellers-grpc-api/gen/go/seller"
 	"github.com/go-chi/chi"
+	"github.com/google/uuid"
 )
 
 type Handler struct {
@@ -26,14 +32,63 @@ func New(sellerClient *seller_client.SellersClient, producClient *product_client
 	}
 }
 
+// searchParams - параметры, которые SearchHandler передает в search_service как есть
+var searchParams = []string{"price_min", "price_max", "tag", "product_size", "seller", "sort", "from", "size", "search_after", "session"}
+
+// SearchHandler - GET /api/content/search в прежнем виде: массив карточек найденных товаров.
+// Оценки, подсветку, фасеты и следующую страницу возвращает только SearchV2Handler.
 func (h *Handler) SearchHandler(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("query")
-	if query == "" {
-		http.Error(w, "missing query parameter", http.StatusBadRequest)
+	page, ok := h.search(w, r)
+	if !ok {
+		return
+	}
+
+	response := make([]dto.ResponseDTO, 0, len(page.Items))
+	for _, item := range page.Items {
+		response = append(response, item.ResponseDTO)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// SearchV2Handler - GET /api/content/v2/search: страница поиска dto.SearchResponseDTO
+func (h *Handler) SearchV2Handler(w http.ResponseWriter, r *http.Request) {
+	page, ok := h.search(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		http.Error(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+}
 
-	searchURL := fmt.Sprintf("http://search_service:8085/search?q=%s", url.QueryEscape(query))
+// search ищет товары через search_service. Текст запроса - параметр query, фильтры,
+// сортировка и пагинация передаются в search_service без изменений, их проверяет он.
+// Товары, которых уже нет в каталоге, пропускаются: индекс догоняет каталог с задержкой.
+// При ошибке ответ уже записан в w и ok = false.
+func (h *Handler) search(w http.ResponseWriter, r *http.Request) (page dto.SearchResponseDTO, ok bool) {
+	params := url.Values{}
+	if query := r.URL.Query().Get("query"); query != "" {
+		params.Set("q", query)
+	}
+	for _, key := range searchParams {
+		if values, ok := r.URL.Query()[key]; ok {
+			params[key] = values
+		}
+	}
+	if len(params) == 0 {
+		http.Error(w, "missing query parameter", http.StatusBadRequest)
+		return page, false
+	}
+
+	searchURL := "http://search_service:8085/search?" + params.Encode()
 
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
@@ -41,48 +96,156 @@ func (h *Handler) SearchHandler(w http.ResponseWriter, r *http.Request) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
 	if err != nil {
 		http.Error(w, "failed to create request to search service", http.StatusInternalServerError)
-		return
+		return page, false
 	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		http.Error(w, "error calling search service: "+err.Error(), http.StatusServiceUnavailable)
-		return
+		return page, false
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		http.Error(w, strings.TrimSpace(string(message)), http.StatusBadRequest)
+		return page, false
+	}
 	if resp.StatusCode != http.StatusOK {
 		http.Error(w, fmt.Sprintf("search service returned status %d", resp.StatusCode), http.StatusBadGateway)
-		return
+		return page, false
 	}
 
 	var result struct {
-		ProductIDs []string `json:"product_ids"`
+		Total int64 `json:"total"`
+		Hits  []struct {
+			ProductID string              `json:"product_id"`
+			Score     float64             `json:"score"`
+			Highlight map[string][]string `json:"highlight"`
+		} `json:"hits"`
+		Facets          json.RawMessage `json:"facets"`
+		NextSearchAfter string          `json:"next_search_after"`
+		Ranking         string          `json:"ranking"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		http.Error(w, "failed to decode search response: "+err.Error(), http.StatusInternalServerError)
+		return page, false
+	}
+
+	page = dto.SearchResponseDTO{
+		Total:           result.Total,
+		Items:           make([]dto.SearchItemDTO, 0, len(result.Hits)),
+		Facets:          result.Facets,
+		NextSearchAfter: result.NextSearchAfter,
+		Ranking:         result.Ranking,
+	}
+	ids := make([]string, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.ProductID)
+	}
+	products, missing, err := h.productInfos(r.Context(), ids)
+	if err != nil {
+		http.Error(w, "failed to get product info: "+err.Error(), http.StatusInternalServerError)
+		return page, false
+	}
+	page.Missing = missing
+	for _, hit := range result.Hits {
+		product, found := products[hit.ProductID]
+		if !found {
+			continue
+		}
+		page.Items = append(page.Items, dto.SearchItemDTO{
+			ResponseDTO: product,
+			Score:       hit.Score,
+			Highlight:   hit.Highlight,
+		})
+	}
+	if len(page.Missing) > 0 {
+		log.Printf("Search returned %d products missing from the catalog: %v", len(page.Missing), page.Missing)
+	}
+
+	return page, true
+}
+
+// SuggestHandler - подсказки search_service к началу запроса prefix. Ответ передается как есть:
+// названия товаров уже в нем, и подсказки не ждут карточек товаров.
+func (h *Handler) SuggestHandler(w http.ResponseWriter, r *http.Request) {
+	params := url.Values{}
+	for _, key := range []string{"prefix", "size"} {
+		if value := r.URL.Query().Get(key); value != "" {
+			params.Set(key, value)
+		}
+	}
+	if params.Get("prefix") == "" {
+		http.Error(w, "missing prefix parameter", http.StatusBadRequest)
 		return
 	}
 
-	if len(result.ProductIDs) == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("[]"))
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://search_service:8085/suggest?"+params.Encode(), nil)
+	if err != nil {
+		http.Error(w, "failed to create request to search service", http.StatusInternalServerError)
 		return
 	}
 
-	response, err := h.GetInfoFromServices(result.ProductIDs)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		http.Error(w, "failed to get product info: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "error calling search service: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		http.Error(w, strings.TrimSpace(string(message)), http.StatusBadRequest)
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		http.Error(w, fmt.Sprintf("search service returned status %d", resp.StatusCode), http.StatusBadGateway)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
+	io.Copy(w, resp.Body)
+}
+
+// FeedbackHandler передает показы и клики по выдаче в search_service как есть. Адрес клиента
+// уходит в X-Real-IP: по нему search_service ограничивает частоту запросов.
+func (h *Handler) FeedbackHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://search_service:8085/feedback",
+		http.MaxBytesReader(w, r.Body, 64<<10))
+	if err != nil {
+		http.Error(w, "failed to create request to search service", http.StatusInternalServerError)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+	req.Header.Set("Content-Type", "application/json")
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		req.Header.Set("X-Real-IP", host)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		http.Error(w, "error calling search service: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusTooManyRequests {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		http.Error(w, strings.TrimSpace(string(message)), resp.StatusCode)
+		return
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		http.Error(w, fmt.Sprintf("search service returned status %d", resp.StatusCode), http.StatusBadGateway)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
 }
 
 func (h *Handler) GetInfoHandler(w http.ResponseWriter, r *http.Request) {
@@ -101,6 +264,10 @@ func (h *Handler) GetInfoHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to get product info: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if len(response) == 0 {
+		http.Error(w, "product not found", http.StatusNotFound)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -110,22 +277,87 @@ func (h *Handler) GetInfoHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// GetInfoFromServices собирает карточки товаров uuids. Товаров, которых нет в каталоге, в ответе нет.
 func (h *Handler) GetInfoFromServices(uuids []string) ([]dto.ResponseDTO, error) {
+	products, _, err := h.productInfos(context.Background(), uuids)
+	if err != nil {
+		return []dto.ResponseDTO{}, err
+	}
+
 	var response []dto.ResponseDTO
-	for _, uuid := range uuids {
-		product, err := h.productClient.GetProduct(context.Background(), uuid)
+	for _, id := range uuids {
+		if product, found := products[id]; found {
+			response = append(response, product)
+		}
+	}
+
+	return response, nil
+}
+
+// maxBatchSize - сколько id принимают за вызов BatchGetProducts и BatchGetSellers
+const maxBatchSize = 100
+
+// productInfos - карточки товаров ids с контактами продавцов по id из ids. Товары и их продавцы
+// запрашиваются пачками: один BatchGetProducts и один BatchGetSellers на maxBatchSize товаров.
+// missing - id товаров, которых нет в каталоге. Товар продавца, которого нет в sellers_service,
+// показывается без контактов.
+func (h *Handler) productInfos(ctx context.Context, ids []string) (infos map[string]dto.ResponseDTO, missing []string, err error) {
+	infos = make(map[string]dto.ResponseDTO, len(ids))
+	for start := 0; start < len(ids); start += maxBatchSize {
+		batch := ids[start:min(start+maxBatchSize, len(ids))]
+		batchMissing, err := h.productInfoBatch(ctx, batch, infos)
 		if err != nil {
-			return []dto.ResponseDTO{}, fmt.Errorf("failed to get product %s: %w", uuid, err)
+			return nil, nil, err
 		}
+		missing = append(missing, batchMissing...)
+	}
+
+	return infos, missing, nil
+}
+
+func (h *Handler) productInfoBatch(ctx context.Context, ids []string, infos map[string]dto.ResponseDTO) (missing []string, err error) {
+	// id, который не может быть id товара, products_service отклонил бы вместе со всей пачкой,
+	// а такого товара в каталоге все равно нет
+	productIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if productID, ok := canonicalID(id); ok {
+			productIDs = append(productIDs, productID)
+		}
+	}
+	if len(productIDs) == 0 {
+		return ids, nil
+	}
 
-		seller, err := h.sellerClient.GetSeller(context.Background(), product.SellerId)
+	products, err := h.productClient.BatchGetProducts(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	sellerIDs := make([]string, 0, len(products))
+	seen := make(map[string]bool, len(products))
+	for _, product := range products {
+		if sellerID, ok := canonicalID(product.SellerId); ok && !seen[sellerID] {
+			seen[sellerID] = true
+			sellerIDs = append(sellerIDs, sellerID)
+		}
+	}
+	var sellers map[string]*sellerpb.Seller
+	if len(sellerIDs) > 0 {
+		sellers, err = h.sellerClient.BatchGetSellers(ctx, sellerIDs)
 		if err != nil {
-			return []dto.ResponseDTO{}, fmt.Errorf("failed to get seller for product %s: %w", uuid, err)
+			return nil, fmt.Errorf("failed to get sellers: %w", err)
 		}
+	}
 
-		fmt.Println("seller:", seller)
+	for _, id := range ids {
+		productID, _ := canonicalID(id)
+		product, ok := products[productID]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
 
-		response = append(response, dto.ResponseDTO{
+		info := dto.ResponseDTO{
 			ProductID:          product.Id,
 			ProductName:        product.Name,
 			ProductCreatedAt:   product.CreatedAt.String(),
@@ -136,12 +368,24 @@ func (h *Handler) GetInfoFromServices(uuids []string) ([]dto.ResponseDTO, error)
 			ProductComments:    product.Comments,
 			ProductRating:      product.Rating,
 			ProductTags:        product.Tags,
-			SellerEmail:        seller.Email,
-			SellerPhone:        seller.Phone,
-			SellerFullName:     seller.Fullname,
-		})
+		}
+		sellerID, _ := canonicalID(product.SellerId)
+		if seller, ok := sellers[sellerID]; ok {
+			info.SellerEmail = seller.Email
+			info.SellerPhone = seller.Phone
+			info.SellerFullName = seller.Fullname
+		}
+		infos[id] = info
 	}
 
-	fmt.Println(response)
-	return response, nil
+	return missing, nil
+}
+
+// canonicalID - id в том виде, в котором его возвращают products_service и sellers_service
+func canonicalID(id string) (string, bool) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", false
+	}
+	return parsed.String(), true
 }
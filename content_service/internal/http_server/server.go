@@ -29,6 +29,9 @@ func New(cfg *config.ServerConfig, sellerClient *seller_client.SellersClient, pr
 
 func (s *HttpServer) setupRoutes() {
 	s.router.Get("/api/content/search", s.handler.SearchHandler)
+	s.router.Get("/api/content/v2/search", s.handler.SearchV2Handler)
+	s.router.Get("/api/content/suggest", s.handler.SuggestHandler)
+	s.router.Post("/api/content/search/feedback", s.handler.FeedbackHandler)
 	s.router.Get("/api/content/products/{uuid}", s.handler.GetInfoHandler)
 }
 
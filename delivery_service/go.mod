@@ -3,7 +3,9 @@ module dlivery_service/delivery_service
 go 1.24
 
 require (
-	github.com/artemSorokin1/Auth-proto v1.0.3
+	github.com/artemSorokin1/Auth-proto v1.1.0
+	github.com/artemSorokin1/products-grpc-api v1.1.0
+	github.com/artemSorokin1/sellers-grpc-api v0.1.0
 	github.com/golang-jwt/jwt/v5 v5.2.2
 	github.com/golang-migrate/migrate v3.5.4+incompatible
 	github.com/ilyakaznacheev/cleanenv v1.5.0
@@ -14,7 +16,9 @@ require (
 	github.com/redis/go-redis/v9 v9.8.0
 	go.uber.org/zap v1.27.0
 	google.golang.org/grpc v1.72.0
+	google.golang.org/protobuf v1.36.6
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+	grpcsec v0.0.0
 )
 
 require (
@@ -52,7 +56,17 @@ require (
 	golang.org/x/text v0.25.0 // indirect
 	golang.org/x/time v0.8.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
-	google.golang.org/protobuf v1.36.5 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	olympos.io/encoding/edn v0.0.0-20201019073823-d3554ca0b0a3 // indirect
 )
+
+replace grpcsec => ../grpcsec
+
+// api генерируется в репозитории из auth_service/api/auth.proto, см. proto/generate.sh
+replace github.com/artemSorokin1/Auth-proto => ../proto/auth-proto
+
+// api генерируется в репозитории из sellers_service/api/seller.proto, см. proto/generate.sh
+replace github.com/artemSorokin1/sellers-grpc-api => ../proto/sellers-grpc-api
+
+// api генерируется в репозитории из products_service/api/product.proto, см. proto/generate.sh
+replace github.com/artemSorokin1/products-grpc-api => ../proto/products-grpc-api
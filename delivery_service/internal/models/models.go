@@ -6,13 +6,6 @@ import (
 	"github.com/lib/pq"
 )
 
-type User struct {
-	ID       int
-	Username string
-	Email    string
-	Password string
-}
-
 type Product struct {
 	ID          int           `db:"id"`
 	Name        string        `db:"name"`
@@ -45,3 +38,27 @@ type CartInfo struct {
 	ProductId int64 `db:"product_id"`
 	Size      int64 `db:"size"`
 }
+
+// UserData - все данные пользователя в delivery_service для выгрузки по запросу auth_service
+type UserData struct {
+	Cart    []CartItem    `json:"cart"`
+	Orders  []OrderRecord `json:"orders"`
+	Actions []UserAction  `json:"actions"`
+}
+
+type OrderRecord struct {
+	ID         int           `db:"id" json:"id"`
+	ProductIDs pq.Int64Array `db:"productids" json:"productIds"`
+	Sizes      pq.Int64Array `db:"sizes" json:"sizes"`
+	Total      int           `db:"total" json:"total"`
+	Name       string        `db:"name" json:"name"`
+	Address    string        `db:"address" json:"address"`
+	UserPhone  string        `db:"userphone" json:"userPhone"`
+	OrderDate  time.Time     `db:"orderdate" json:"orderDate"`
+}
+
+type UserAction struct {
+	OrderID   int       `db:"order_id" json:"orderId"`
+	Action    string    `db:"action" json:"action"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
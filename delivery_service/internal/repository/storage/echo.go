@@ -74,21 +74,6 @@ func New(config *config.Config, logger *zap.Logger) (*DB, error) {
 	}, nil
 }
 
-func (d *DB) GetUserById(userId int64) (models.User, error) {
-	d.logger.Debug("getting user by id", zap.Int64("userId", userId))
-
-	var user models.User
-	err := d.Db.Get(&user, "SELECT * FROM users WHERE id=$1", userId)
-	if err != nil {
-		d.logger.Error("error getting user by id")
-		return models.User{}, fmt.Errorf("error getting user: %w", err)
-	}
-
-	d.logger.Debug("successfully got user by id", zap.Int64("userId", userId))
-
-	return user, nil
-}
-
 func (d *DB) GetProducts() ([]models.Product, error) {
 	d.logger.Debug("getting products")
 	var products []models.Product
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"dlivery_service/delivery_service/internal/models"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// anonymizedUserId - владелец обезличенных заказов. Заказы нужны для отчетности, поэтому не удаляются.
+const anonymizedUserId = 0
+
+func (d *DB) ExportUserData(userId int64) (models.UserData, error) {
+	d.logger.Debug("exporting user data", zap.Int64("userId", userId))
+
+	cart, err := d.GetCart(userId)
+	if err != nil {
+		return models.UserData{}, err
+	}
+
+	var orders []models.OrderRecord
+	err = d.Db.Select(&orders, `
+		SELECT id, productids, sizes, total, name, address, userphone, orderdate
+		FROM orders WHERE user_id = $1 ORDER BY orderdate`, userId)
+	if err != nil {
+		d.logger.Error("error getting user orders")
+		return models.UserData{}, fmt.Errorf("error getting user orders: %w", err)
+	}
+
+	var actions []models.UserAction
+	err = d.Db.Select(&actions, "SELECT order_id, action, created_at FROM user_actions WHERE user_id = $1 ORDER BY created_at", userId)
+	if err != nil {
+		d.logger.Error("error getting user actions")
+		return models.UserData{}, fmt.Errorf("error getting user actions: %w", err)
+	}
+
+	return models.UserData{
+		Cart:    cart,
+		Orders:  orders,
+		Actions: actions,
+	}, nil
+}
+
+// EraseUserData удаляет корзину и историю действий и обезличивает заказы. Повторный вызов ничего не ломает.
+func (d *DB) EraseUserData(userId int64) (err error) {
+	d.logger.Debug("erasing user data", zap.Int64("userId", userId))
+
+	tx, err := d.Db.Beginx()
+	if err != nil {
+		return fmt.Errorf("error begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.Exec("DELETE FROM cart_items WHERE cart_id IN (SELECT id FROM cart WHERE user_id = $1)", userId)
+	if err != nil {
+		return fmt.Errorf("error deleting cart items: %w", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM cart WHERE user_id = $1", userId)
+	if err != nil {
+		return fmt.Errorf("error deleting cart: %w", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM user_actions WHERE user_id = $1", userId)
+	if err != nil {
+		return fmt.Errorf("error deleting user actions: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE orders SET user_id = $1, name = 'deleted user', address = '', userphone = ''
+		WHERE user_id = $2`, anonymizedUserId, userId)
+	if err != nil {
+		return fmt.Errorf("error anonymizing orders: %w", err)
+	}
+
+	d.logger.Debug("successfully erased user data", zap.Int64("userId", userId))
+
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 	"dlivery_service/delivery_service/internal/repository/storage"
 	"dlivery_service/delivery_service/pkg/auth"
 	"dlivery_service/delivery_service/pkg/inmem"
+	"dlivery_service/delivery_service/pkg/products"
+	"dlivery_service/delivery_service/pkg/sellers"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,10 +22,15 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/lib/pq"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type Handler struct {
 	GRPCClient           *auth.GRPCAuthClient
+	SellersClient        *sellers.GRPCSellersClient
+	ProductsClient       *products.GRPCProductsClient
 	DB                   *storage.DB
 	redisClientForCart   *inmem.RedisClientForCart
 	redisClientForNotify *inmem.RedisClientForNotify
@@ -37,6 +44,8 @@ func New(logger *zap.Logger, db *storage.DB) *Handler {
 
 	return &Handler{
 		GRPCClient:           auth.New(context.Background(), logger, time.Second*1, 3),
+		SellersClient:        sellers.New(context.Background(), logger),
+		ProductsClient:       products.New(context.Background(), logger),
 		DB:                   db,
 		redisClientForNotify: redisClientForNotify,
 		redisClientForCart:   redisClientForCart,
@@ -81,7 +90,10 @@ func (h *Handler) LoginUserHandler(c echo.Context) error {
 	h.logger.Debug("login attempt",
 		zap.String("username", c.FormValue("username")))
 
-	response, err := h.GRPCClient.Api.Login(context.Background(), &grpcauth.LoginRequest{
+	// auth_service ограничивает попытки входа по IP, поэтому передаем адрес клиента
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-real-ip", c.RealIP())
+
+	response, err := h.GRPCClient.Api.Login(ctx, &grpcauth.LoginRequest{
 		Username: c.FormValue("username"),
 		Password: c.FormValue("password"),
 	})
@@ -89,7 +101,16 @@ func (h *Handler) LoginUserHandler(c echo.Context) error {
 		h.logger.Error("login failed",
 			zap.String("username", c.FormValue("username")),
 			zap.Error(err))
-		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	if response.TwoFactorRequired {
+		h.logger.Debug("second factor required",
+			zap.String("username", c.FormValue("username")))
+		return c.JSON(http.StatusOK, map[string]any{
+			"two_factor_required": true,
+			"challenge_token":     response.ChallengeToken,
+		})
 	}
 
 	h.logger.Debug("user logged in successfully",
@@ -99,6 +120,86 @@ func (h *Handler) LoginUserHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"message": "Login successful"})
 }
 
+func (h *Handler) VerifySecondFactorHandler(c echo.Context) error {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-real-ip", c.RealIP())
+
+	response, err := h.GRPCClient.Api.VerifySecondFactor(ctx, &grpcauth.VerifySecondFactorRequest{
+		ChallengeToken: c.FormValue("challenge_token"),
+		Code:           c.FormValue("code"),
+	})
+	if err != nil {
+		h.logger.Warn("second factor verification failed", zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	c.Response().Header().Set("Authorization", "Bearer "+response.AccessToken)
+	return c.JSON(http.StatusOK, map[string]string{"message": "Login successful"})
+}
+
+func (h *Handler) EnrollTwoFactorHandler(c echo.Context) error {
+	userId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	response, err := h.GRPCClient.Api.EnrollTwoFactor(context.Background(), &grpcauth.EnrollTwoFactorRequest{
+		UserId: userId,
+	})
+	if err != nil {
+		h.logger.Error("failed to enroll two-factor authentication",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"secret":      response.Secret,
+		"otpauth_uri": response.OtpauthUri,
+	})
+}
+
+func (h *Handler) ConfirmTwoFactorHandler(c echo.Context) error {
+	userId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	response, err := h.GRPCClient.Api.ConfirmTwoFactor(context.Background(), &grpcauth.ConfirmTwoFactorRequest{
+		UserId: userId,
+		Code:   c.FormValue("code"),
+	})
+	if err != nil {
+		h.logger.Warn("failed to confirm two-factor authentication",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, map[string][]string{"recovery_codes": response.RecoveryCodes})
+}
+
+func (h *Handler) DisableTwoFactorHandler(c echo.Context) error {
+	userId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-real-ip", c.RealIP())
+
+	_, err = h.GRPCClient.Api.DisableTwoFactor(ctx, &grpcauth.DisableTwoFactorRequest{
+		UserId: userId,
+		Code:   c.FormValue("code"),
+	})
+	if err != nil {
+		h.logger.Warn("failed to disable two-factor authentication",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "two-factor authentication disabled"})
+}
+
 func (h *Handler) RegisterUserHandler(c echo.Context) error {
 	response, err := h.GRPCClient.Api.Register(context.Background(), &grpcauth.RegisterRequest{
 		Email:    c.FormValue("email"),
@@ -123,6 +224,253 @@ func (h *Handler) RegisterUserHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"message": "success"})
 }
 
+func (h *Handler) RequestEmailVerificationHandler(c echo.Context) error {
+	userId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	_, err = h.GRPCClient.Api.RequestEmailVerification(context.Background(), &grpcauth.RequestEmailVerificationRequest{
+		UserId: userId,
+	})
+	if err != nil {
+		h.logger.Error("failed to request email verification",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "verification email sent"})
+}
+
+func (h *Handler) ConfirmEmailHandler(c echo.Context) error {
+	response, err := h.GRPCClient.Api.ConfirmEmail(context.Background(), &grpcauth.ConfirmEmailRequest{
+		Token: c.FormValue("token"),
+	})
+	if err != nil {
+		h.logger.Warn("failed to confirm email", zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	h.logger.Debug("email confirmed", zap.Int64("user_id", response.UserId))
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "email confirmed"})
+}
+
+func (h *Handler) ForgotPasswordHandler(c echo.Context) error {
+	_, err := h.GRPCClient.Api.RequestPasswordReset(context.Background(), &grpcauth.RequestPasswordResetRequest{
+		Email: c.FormValue("email"),
+	})
+	if err != nil {
+		h.logger.Error("failed to request password reset", zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "if the email is registered, a reset link has been sent"})
+}
+
+func (h *Handler) ResetPasswordHandler(c echo.Context) error {
+	_, err := h.GRPCClient.Api.ResetPassword(context.Background(), &grpcauth.ResetPasswordRequest{
+		Token:       c.FormValue("token"),
+		NewPassword: c.FormValue("password"),
+	})
+	if err != nil {
+		h.logger.Warn("failed to reset password", zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "password changed"})
+}
+
+// httpStatusFromGRPC переводит код ошибки auth_service в http статус
+func httpStatusFromGRPC(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.FailedPrecondition, codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *Handler) GetProfileHandler(c echo.Context) error {
+	userId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	response, err := h.GRPCClient.Api.GetProfile(context.Background(), &grpcauth.GetProfileRequest{
+		UserId: userId,
+	})
+	if err != nil {
+		h.logger.Error("failed to get profile",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, response.Profile)
+}
+
+func (h *Handler) UpdateProfileHandler(c echo.Context) error {
+	userId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	var req struct {
+		FullName string `json:"fullName"`
+		Phone    string `json:"phone"`
+		Address  string `json:"address"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	response, err := h.GRPCClient.Api.UpdateProfile(context.Background(), &grpcauth.UpdateProfileRequest{
+		UserId:   userId,
+		FullName: req.FullName,
+		Phone:    req.Phone,
+		Address:  req.Address,
+	})
+	if err != nil {
+		h.logger.Warn("failed to update profile",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, response.Profile)
+}
+
+func (h *Handler) ChangePasswordHandler(c echo.Context) error {
+	userId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-real-ip", c.RealIP())
+
+	_, err = h.GRPCClient.Api.ChangePassword(ctx, &grpcauth.ChangePasswordRequest{
+		UserId:      userId,
+		OldPassword: c.FormValue("old_password"),
+		NewPassword: c.FormValue("new_password"),
+	})
+	if err != nil {
+		h.logger.Warn("failed to change password",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "password changed"})
+}
+
+func (h *Handler) ChangeEmailHandler(c echo.Context) error {
+	userId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-real-ip", c.RealIP())
+
+	_, err = h.GRPCClient.Api.ChangeEmail(ctx, &grpcauth.ChangeEmailRequest{
+		UserId:   userId,
+		Password: c.FormValue("password"),
+		NewEmail: c.FormValue("email"),
+	})
+	if err != nil {
+		h.logger.Warn("failed to change email",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "email changed, check your inbox to confirm it"})
+}
+
+func (h *Handler) DeleteAccountHandler(c echo.Context) error {
+	userId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-real-ip", c.RealIP())
+
+	response, err := h.GRPCClient.Api.DeleteAccount(ctx, &grpcauth.DeleteAccountRequest{
+		UserId:   userId,
+		Password: c.FormValue("password"),
+	})
+	if err != nil {
+		h.logger.Warn("failed to delete account",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	h.logger.Info("account deletion requested",
+		zap.Int64("user_id", userId),
+		zap.Int64("request_id", response.RequestId))
+
+	return c.JSON(http.StatusAccepted, map[string]any{
+		"message":    "account deletion started",
+		"request_id": response.RequestId,
+	})
+}
+
+func (h *Handler) RequestDataExportHandler(c echo.Context) error {
+	userId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	response, err := h.GRPCClient.Api.RequestDataExport(context.Background(), &grpcauth.RequestDataExportRequest{
+		UserId: userId,
+	})
+	if err != nil {
+		h.logger.Error("failed to request data export",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]any{
+		"message":    "data export started",
+		"request_id": response.RequestId,
+	})
+}
+
+func (h *Handler) GetPrivacyRequestHandler(c echo.Context) error {
+	userId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	requestId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request id"})
+	}
+
+	response, err := h.GRPCClient.Api.GetPrivacyRequest(context.Background(), &grpcauth.GetPrivacyRequestRequest{
+		UserId:    userId,
+		RequestId: requestId,
+	})
+	if err != nil {
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
 func (h *Handler) GetProductsHandler(c echo.Context) error {
 	h.logger.Info("handling get products request",
 		zap.String("path", c.Path()),
@@ -397,17 +745,19 @@ func (h *Handler) CheckoutHandler(c echo.Context) error {
 	h.logger.Debug("order processed successfully",
 		zap.Int64("user_id", userId),
 		zap.Int("order_id", orderId))
-	user, err := h.DB.GetUserById(userId)
+	profile, err := h.GRPCClient.Api.GetProfile(context.Background(), &grpcauth.GetProfileRequest{
+		UserId: userId,
+	})
 	if err != nil {
-		h.logger.Error("failed to get user by id",
+		h.logger.Error("failed to get user profile",
 			zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
 	}
 
 	h.logger.Debug("sending notification",
-		zap.String("email", user.Email))
+		zap.String("email", profile.Profile.Email))
 
-	go h.redisClientForNotify.Publish(user.Email)
+	go h.redisClientForNotify.Publish(profile.Profile.Email)
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message":  "Order created successfully",
@@ -505,6 +855,32 @@ func (h *Handler) AdminDeleteProductHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"message": "product deleted successfully"})
 }
 
+func (h *Handler) AdminUnlockUserHandler(c echo.Context) error {
+	username := c.Param("username")
+
+	adminId, err := jwt.GetUserIdFromJWTToken(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	_, err = h.GRPCClient.Api.UnlockUser(context.Background(), &grpcauth.UnlockUserRequest{
+		AdminId:  adminId,
+		Username: username,
+	})
+	if err != nil {
+		h.logger.Error("failed to unlock user",
+			zap.String("username", username),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	h.logger.Info("user unlocked by admin",
+		zap.String("username", username),
+		zap.Int64("admin_id", adminId))
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "user unlocked successfully"})
+}
+
 func (h *Handler) AdminMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		userId, err := jwt.GetUserIdFromJWTToken(c)
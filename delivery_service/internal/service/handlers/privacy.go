@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// InternalTokenMiddleware пропускает только запросы auth_service с общим токеном INTERNAL_API_TOKEN
+func (h *Handler) InternalTokenMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	token := os.Getenv("INTERNAL_API_TOKEN")
+
+	return func(c echo.Context) error {
+		got := c.Request().Header.Get("X-Internal-Token")
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			h.logger.Warn("internal request with invalid token", zap.String("path", c.Path()))
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid internal token"})
+		}
+
+		return next(c)
+	}
+}
+
+func (h *Handler) ExportUserDataHandler(c echo.Context) error {
+	userId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+	}
+
+	data, err := h.DB.ExportUserData(userId)
+	if err != nil {
+		h.logger.Error("failed to export user data",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *Handler) EraseUserDataHandler(c echo.Context) error {
+	userId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+	}
+
+	if err := h.DB.EraseUserData(userId); err != nil {
+		h.logger.Error("failed to erase user data",
+			zap.Int64("user_id", userId),
+			zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.redisClientForCart.DeleteCart(userId); err != nil {
+		h.logger.Warn("failed to delete cached cart", zap.Int64("user_id", userId), zap.Error(err))
+	}
+
+	h.logger.Info("user data erased", zap.Int64("user_id", userId))
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "user data erased"})
+}
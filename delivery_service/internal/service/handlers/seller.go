@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"dlivery_service/delivery_service/internal/jwt"
+	"net/http"
+
+	pb "github.com/artemSorokin1/sellers-grpc-api/gen/go/seller"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type sellerCardRequest struct {
+	Phone    string `json:"phone"`
+	Email    string `json:"email"`
+	FullName string `json:"fullName"`
+	City     string `json:"city"`
+}
+
+// sellerContext передает access токен продавца в метаданных authorization. Сервисы продавцов
+// и товаров сами проверяют его подпись и берут продавца из него, а не из тела запроса.
+func sellerContext(c echo.Context) (context.Context, error) {
+	if _, err := jwt.GetUserIdFromJWTToken(c); err != nil {
+		return nil, err
+	}
+
+	return metadata.AppendToOutgoingContext(c.Request().Context(),
+		"authorization", c.Request().Header.Get("Authorization")), nil
+}
+
+func (h *Handler) CreateSellerCardHandler(c echo.Context) error {
+	ctx, err := sellerContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	var req sellerCardRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	response, err := h.SellersClient.Api.CreateSeller(ctx, &pb.CreateSellerRequest{
+		Phone:    req.Phone,
+		Email:    req.Email,
+		Fullname: req.FullName,
+		City:     req.City,
+	})
+	if err != nil {
+		h.logger.Warn("failed to create seller card", zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusCreated, response.Seller)
+}
+
+func (h *Handler) UpdateSellerCardHandler(c echo.Context) error {
+	ctx, err := sellerContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	var req sellerCardRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	response, err := h.SellersClient.Api.UpdateSeller(ctx, &pb.UpdateSellerRequest{
+		Phone:    req.Phone,
+		Email:    req.Email,
+		Fullname: req.FullName,
+		City:     req.City,
+	})
+	if err != nil {
+		h.logger.Warn("failed to update seller card", zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, response.Seller)
+}
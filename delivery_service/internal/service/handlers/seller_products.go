@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+
+	pb "github.com/artemSorokin1/products-grpc-api/gen/go/product"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type sellerProductRequest struct {
+	Name        string         `json:"name"`
+	Price       int32          `json:"price"`
+	ImageURL    string         `json:"imageUrl"`
+	Description string         `json:"description"`
+	Info        map[string]any `json:"info"`
+	Tags        []string       `json:"tags"`
+}
+
+// CreateSellerProductHandler публикует товар продавца из access токена (см. sellerContext)
+func (h *Handler) CreateSellerProductHandler(c echo.Context) error {
+	ctx, err := sellerContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	var req sellerProductRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+	info, err := structpb.NewStruct(req.Info)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid info"})
+	}
+
+	response, err := h.ProductsClient.Api.CreateProduct(ctx, &pb.CreateProductRequest{
+		Name:        req.Name,
+		Price:       req.Price,
+		ImageUrl:    req.ImageURL,
+		Description: req.Description,
+		Info:        info,
+		Tags:        req.Tags,
+	})
+	if err != nil {
+		h.logger.Warn("failed to create product", zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusCreated, response.Product)
+}
+
+func (h *Handler) UpdateSellerProductHandler(c echo.Context) error {
+	ctx, err := sellerContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	var req sellerProductRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+	info, err := structpb.NewStruct(req.Info)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid info"})
+	}
+
+	response, err := h.ProductsClient.Api.UpdateProduct(ctx, &pb.UpdateProductRequest{
+		Id:          c.Param("id"),
+		Name:        req.Name,
+		Price:       req.Price,
+		ImageUrl:    req.ImageURL,
+		Description: req.Description,
+		Info:        info,
+		Tags:        req.Tags,
+	})
+	if err != nil {
+		h.logger.Warn("failed to update product", zap.String("id", c.Param("id")), zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, response.Product)
+}
+
+func (h *Handler) DeleteSellerProductHandler(c echo.Context) error {
+	ctx, err := sellerContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+
+	if _, err := h.ProductsClient.Api.DeleteProduct(ctx, &pb.DeleteProductRequest{Id: c.Param("id")}); err != nil {
+		h.logger.Warn("failed to delete product", zap.String("id", c.Param("id")), zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "product deleted"})
+}
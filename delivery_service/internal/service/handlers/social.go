@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	grpcauth "github.com/artemSorokin1/Auth-proto/protos/gen/protos/proto"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	socialStateCookie = "social_login_state"
+	socialStateMaxAge = 10 * time.Minute
+)
+
+// SocialLoginHandler отправляет пользователя на страницу входа провайдера. state кладется в cookie,
+// чтобы callback можно было завершить только в том же браузере, где вход начинался.
+func (h *Handler) SocialLoginHandler(c echo.Context) error {
+	provider := c.Param("provider")
+
+	response, err := h.GRPCClient.Api.StartSocialLogin(context.Background(), &grpcauth.StartSocialLoginRequest{
+		Provider: provider,
+	})
+	if err != nil {
+		h.logger.Error("failed to start social login",
+			zap.String("provider", provider),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     socialStateCookie,
+		Value:    response.State,
+		Path:     "/api/auth/social/",
+		MaxAge:   int(socialStateMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return c.Redirect(http.StatusFound, response.AuthorizationUrl)
+}
+
+func (h *Handler) SocialLoginCallbackHandler(c echo.Context) error {
+	provider := c.Param("provider")
+
+	if providerErr := c.QueryParam("error"); providerErr != "" {
+		h.logger.Warn("social login denied by provider",
+			zap.String("provider", provider),
+			zap.String("error", providerErr))
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "login was cancelled at the identity provider"})
+	}
+
+	state := c.QueryParam("state")
+	cookie, err := c.Cookie(socialStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		h.logger.Warn("social login state mismatch", zap.String("provider", provider))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid login state"})
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:   socialStateCookie,
+		Path:   "/api/auth/social/",
+		MaxAge: -1,
+	})
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-real-ip", c.RealIP())
+
+	response, err := h.GRPCClient.Api.CompleteSocialLogin(ctx, &grpcauth.CompleteSocialLoginRequest{
+		Provider: provider,
+		Code:     c.QueryParam("code"),
+		State:    state,
+	})
+	if err != nil {
+		h.logger.Error("social login failed",
+			zap.String("provider", provider),
+			zap.Error(err))
+		return c.JSON(httpStatusFromGRPC(err), map[string]string{"error": status.Convert(err).Message()})
+	}
+
+	if response.TwoFactorRequired {
+		return c.JSON(http.StatusOK, map[string]any{
+			"two_factor_required": true,
+			"challenge_token":     response.ChallengeToken,
+		})
+	}
+
+	c.Response().Header().Set("Authorization", "Bearer "+response.AccessToken)
+	return c.JSON(http.StatusOK, map[string]any{
+		"message":     "Login successful",
+		"is_new_user": response.IsNewUser,
+	})
+}
@@ -2,13 +2,14 @@ package auth
 
 import (
 	"context"
+	"grpcsec"
+	"net"
 	"os"
 	"time"
 
 	grpcauth "github.com/artemSorokin1/Auth-proto/protos/gen/protos/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type GRPCAuthClient struct {
@@ -22,7 +23,21 @@ func New(ctx context.Context,
 	retriesCount int) *GRPCAuthClient {
 
 	grpcAddres := os.Getenv("GRPC_AUTH_ADDRESS")
-	cc, err := grpc.NewClient(grpcAddres, grpc.WithTransportCredentials(insecure.NewCredentials()))
+
+	// имя сервера в сертификате совпадает с именем хоста auth_service
+	serverName, _, err := net.SplitHostPort(grpcAddres)
+	if err != nil {
+		logger.Error("invalid auth service address", zap.String("address", grpcAddres), zap.Error(err))
+		return nil
+	}
+
+	transport, err := grpcsec.DialOption(ctx, grpcsec.ConfigFromEnv(), serverName)
+	if err != nil {
+		logger.Error("failed to configure grpc tls", zap.Error(err))
+		return nil
+	}
+
+	cc, err := grpc.NewClient(grpcAddres, transport)
 	if err != nil {
 		logger.Error("failed to create grpc client", zap.Error(err))
 		return nil
@@ -88,3 +88,13 @@ func (r *RedisClientForCart) GetCart(userID int64) ([]models.CartItem, error) {
 
 	return cart, nil
 }
+
+func (r *RedisClientForCart) DeleteCart(userID int64) error {
+	err := r.client.Del(context.Background(), strconv.FormatInt(userID, 10)).Err()
+	if err != nil {
+		slog.Error("Error deleting cart from Redis", slog.String("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package products
+
+import (
+	"context"
+	"grpcsec"
+	"net"
+	"os"
+
+	pb "github.com/artemSorokin1/products-grpc-api/gen/go/product"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// GRPCProductsClient - клиент products_service, через него продавец публикует и меняет свои товары
+type GRPCProductsClient struct {
+	Api    pb.ProductsServiceClient
+	logger *zap.Logger
+}
+
+func New(ctx context.Context, logger *zap.Logger) *GRPCProductsClient {
+	grpcAddres := os.Getenv("GRPC_PRODUCTS_ADDRESS")
+
+	// имя сервера в сертификате совпадает с именем хоста products_service
+	serverName, _, err := net.SplitHostPort(grpcAddres)
+	if err != nil {
+		logger.Error("invalid products service address", zap.String("address", grpcAddres), zap.Error(err))
+		return nil
+	}
+
+	transport, err := grpcsec.DialOption(ctx, grpcsec.ConfigFromEnv(), serverName)
+	if err != nil {
+		logger.Error("failed to configure grpc tls", zap.Error(err))
+		return nil
+	}
+
+	cc, err := grpc.NewClient(grpcAddres, transport)
+	if err != nil {
+		logger.Error("failed to create grpc client", zap.Error(err))
+		return nil
+	}
+
+	return &GRPCProductsClient{
+		Api:    pb.NewProductsServiceClient(cc),
+		logger: logger,
+	}
+}
@@ -0,0 +1,46 @@
+package sellers
+
+import (
+	"context"
+	"grpcsec"
+	"net"
+	"os"
+
+	pb "github.com/artemSorokin1/sellers-grpc-api/gen/go/seller"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// GRPCSellersClient - клиент sellers_service, через него продавец меняет свою карточку
+type GRPCSellersClient struct {
+	Api    pb.SellersServiceClient
+	logger *zap.Logger
+}
+
+func New(ctx context.Context, logger *zap.Logger) *GRPCSellersClient {
+	grpcAddres := os.Getenv("GRPC_SELLERS_ADDRESS")
+
+	// имя сервера в сертификате совпадает с именем хоста sellers_service
+	serverName, _, err := net.SplitHostPort(grpcAddres)
+	if err != nil {
+		logger.Error("invalid sellers service address", zap.String("address", grpcAddres), zap.Error(err))
+		return nil
+	}
+
+	transport, err := grpcsec.DialOption(ctx, grpcsec.ConfigFromEnv(), serverName)
+	if err != nil {
+		logger.Error("failed to configure grpc tls", zap.Error(err))
+		return nil
+	}
+
+	cc, err := grpc.NewClient(grpcAddres, transport)
+	if err != nil {
+		logger.Error("failed to create grpc client", zap.Error(err))
+		return nil
+	}
+
+	return &GRPCSellersClient{
+		Api:    pb.NewSellersServiceClient(cc),
+		logger: logger,
+	}
+}
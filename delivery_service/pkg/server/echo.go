@@ -7,7 +7,10 @@ import (
 	"dlivery_service/delivery_service/internal/service/handlers"
 	"dlivery_service/delivery_service/pkg/metrics"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -22,13 +25,41 @@ type EchoServer struct {
 }
 
 func New(ctx context.Context, db *storage.DB, logger *zap.Logger) *EchoServer {
+	server := echo.New()
+	server.IPExtractor = ipExtractor(logger)
+
 	return &EchoServer{
 		logger:  logger,
-		server:  echo.New(),
+		server:  server,
 		handler: handlers.New(logger, db),
 	}
 }
 
+// ipExtractor - откуда брать адрес клиента для c.RealIP(). Он уходит в auth_service как x-real-ip
+// и участвует в лимите попыток входа, поэтому X-Forwarded-For читаем только от прокси из
+// TRUSTED_PROXIES (CIDR через запятую). Без него берем адрес соединения.
+func ipExtractor(logger *zap.Logger) echo.IPExtractor {
+	proxies := os.Getenv("TRUSTED_PROXIES")
+	if proxies == "" {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range strings.Split(proxies, ",") {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			logger.Fatal("invalid TRUSTED_PROXIES", zap.String("cidr", cidr), zap.Error(err))
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
 func (e *EchoServer) MustRun(cfg *config.Config) {
 	e.logger.Info("starting server")
 	e.server.Use(middleware.CORSWithConfig(middleware.CORSConfig{
@@ -58,6 +89,37 @@ func (e *EchoServer) setHandlers() {
 		auth.POST("/register", e.handler.RegisterUserHandler)
 		auth.POST("/login", e.handler.LoginUserHandler)
 		auth.POST("/logout", e.handler.LogoutUserHandler)
+		auth.POST("/verify-email/request", e.handler.RequestEmailVerificationHandler)
+		auth.POST("/verify-email/confirm", e.handler.ConfirmEmailHandler)
+		auth.POST("/password/forgot", e.handler.ForgotPasswordHandler)
+		auth.POST("/password/reset", e.handler.ResetPasswordHandler)
+		auth.POST("/2fa/verify", e.handler.VerifySecondFactorHandler)
+		auth.POST("/2fa/enroll", e.handler.EnrollTwoFactorHandler)
+		auth.POST("/2fa/confirm", e.handler.ConfirmTwoFactorHandler)
+		auth.POST("/2fa/disable", e.handler.DisableTwoFactorHandler)
+		auth.GET("/social/:provider", e.handler.SocialLoginHandler)
+		auth.GET("/social/:provider/callback", e.handler.SocialLoginCallbackHandler)
+	}
+
+	profile := e.server.Group("/api/profile")
+	{
+		profile.GET("", e.handler.GetProfileHandler)
+		profile.PUT("", e.handler.UpdateProfileHandler)
+		profile.DELETE("", e.handler.DeleteAccountHandler)
+		profile.POST("/password", e.handler.ChangePasswordHandler)
+		profile.POST("/email", e.handler.ChangeEmailHandler)
+		profile.POST("/export", e.handler.RequestDataExportHandler)
+		profile.GET("/privacy-requests/:id", e.handler.GetPrivacyRequestHandler)
+	}
+
+	// карточку и товары меняет продавец из access токена, его проверяют sellers_service и products_service
+	seller := e.server.Group("/api/seller")
+	{
+		seller.POST("/card", e.handler.CreateSellerCardHandler)
+		seller.PUT("/card", e.handler.UpdateSellerCardHandler)
+		seller.POST("/products", e.handler.CreateSellerProductHandler)
+		seller.PUT("/products/:id", e.handler.UpdateSellerProductHandler)
+		seller.DELETE("/products/:id", e.handler.DeleteSellerProductHandler)
 	}
 
 	pm := metrics.NewProductsMetrics()
@@ -80,9 +142,16 @@ func (e *EchoServer) setHandlers() {
 	{
 		admin.POST("/products", e.handler.AdminAddProductHandler)
 		admin.DELETE("/products/:id", e.handler.AdminDeleteProductHandler)
+		admin.POST("/users/:username/unlock", e.handler.AdminUnlockUserHandler)
 	}
 
 	e.server.POST("/checkout", e.handler.CheckoutHandler)
 
+	internal := e.server.Group("/internal/users", e.handler.InternalTokenMiddleware)
+	{
+		internal.GET("/:id/export", e.handler.ExportUserDataHandler)
+		internal.POST("/:id/erase", e.handler.EraseUserDataHandler)
+	}
+
 	e.server.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
 }
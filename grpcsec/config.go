@@ -0,0 +1,37 @@
+package grpcsec
+
+import (
+	"os"
+	"time"
+)
+
+const defaultReloadInterval = time.Minute
+
+// Config - пути к сертификатам сервиса. Сертификаты выпускает общий CA (см. gen-certs.sh),
+// CommonName сертификата - имя сервиса, по нему сервер решает, какие методы можно вызывать.
+type Config struct {
+	CAFile         string
+	CertFile       string
+	KeyFile        string
+	ReloadInterval time.Duration
+	// Insecure отключает TLS и проверку вызывающего. Только для локальной разработки.
+	Insecure bool
+}
+
+// ConfigFromEnv читает GRPC_TLS_CA_FILE, GRPC_TLS_CERT_FILE, GRPC_TLS_KEY_FILE,
+// GRPC_TLS_RELOAD_INTERVAL и GRPC_INSECURE
+func ConfigFromEnv() Config {
+	cfg := Config{
+		CAFile:         os.Getenv("GRPC_TLS_CA_FILE"),
+		CertFile:       os.Getenv("GRPC_TLS_CERT_FILE"),
+		KeyFile:        os.Getenv("GRPC_TLS_KEY_FILE"),
+		ReloadInterval: defaultReloadInterval,
+		Insecure:       os.Getenv("GRPC_INSECURE") == "true",
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("GRPC_TLS_RELOAD_INTERVAL")); err == nil && interval > 0 {
+		cfg.ReloadInterval = interval
+	}
+
+	return cfg
+}
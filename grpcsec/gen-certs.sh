@@ -0,0 +1,42 @@
+#!/bin/sh
+# Выпускает CA и сертификаты сервисов для mTLS между grpc сервисами.
+# CommonName сертификата - имя сервиса, его же проверяют политики на серверах;
+# DNS имя совпадает с именем контейнера в docker-compose.
+#
+#   ./grpcsec/gen-certs.sh [каталог]   по умолчанию ./certs
+#
+# Повторный запуск перевыпускает сертификаты сервисов тем же CA; сервисы подхватят их без перезапуска.
+set -e
+
+out=${1:-./certs}
+days=365
+services="auth_service delivery_service content_service products_service sellers_service search_service"
+
+mkdir -p "$out"
+
+if [ ! -f "$out/ca.key" ]; then
+  openssl req -x509 -newkey rsa:4096 -nodes -days 3650 \
+    -subj "/CN=web-shop internal ca" \
+    -keyout "$out/ca.key" -out "$out/ca.crt"
+fi
+
+for svc in $services; do
+  openssl req -newkey rsa:2048 -nodes \
+    -subj "/CN=$svc" \
+    -keyout "$out/$svc.key" -out "$out/$svc.csr"
+
+  cat > "$out/$svc.ext" <<EXT
+basicConstraints = CA:FALSE
+keyUsage = digitalSignature, keyEncipherment
+extendedKeyUsage = serverAuth, clientAuth
+subjectAltName = DNS:$svc, DNS:localhost
+EXT
+
+  openssl x509 -req -in "$out/$svc.csr" -days $days \
+    -CA "$out/ca.crt" -CAkey "$out/ca.key" -CAcreateserial \
+    -extfile "$out/$svc.ext" -out "$out/$svc.crt"
+
+  rm "$out/$svc.csr" "$out/$svc.ext"
+done
+
+echo "certificates written to $out"
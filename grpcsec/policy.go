@@ -0,0 +1,106 @@
+package grpcsec
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Policy - какие сервисы могут вызывать метод. Ключ - полное имя метода ("/api.AuthService/IsAdmin"),
+// все методы сервиса ("/api.AuthService/*") или любой метод ("*"); значение - имена сервисов
+// из CommonName их сертификатов. Точное имя метода важнее маски. Метод без правила запрещен.
+//
+// Методы записи и администрирования стоит перечислять явно: тогда сервис, добавленный
+// в маску ради чтения, не получает к ним доступ.
+type Policy map[string][]string
+
+type identityKey struct{}
+
+// IdentityFromContext возвращает имя сервиса, который вызвал метод
+func IdentityFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(identityKey{}).(string)
+	return identity, ok
+}
+
+func (p Policy) allowed(method, identity string) bool {
+	if callers, ok := p[method]; ok {
+		return slices.Contains(callers, identity)
+	}
+
+	if i := strings.LastIndex(method, "/"); i > 0 {
+		if callers, ok := p[method[:i]+"/*"]; ok {
+			return slices.Contains(callers, identity)
+		}
+	}
+
+	return slices.Contains(p["*"], identity)
+}
+
+func (p Policy) authorize(ctx context.Context, method string) (context.Context, error) {
+	identity, ok := peerIdentity(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "client certificate is required")
+	}
+
+	if !p.allowed(method, identity) {
+		slog.Warn("grpc call denied", slog.String("method", method), slog.String("caller", identity))
+		return nil, status.Errorf(codes.PermissionDenied, "%s is not allowed to call %s", identity, method)
+	}
+
+	return context.WithValue(ctx, identityKey{}, identity), nil
+}
+
+func (p Policy) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := p.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (p Policy) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := p.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
+
+// peerIdentity - CommonName проверенного клиентского сертификата
+func peerIdentity(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+
+	identity := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+
+	return identity, identity != ""
+}
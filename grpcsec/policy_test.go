@@ -0,0 +1,101 @@
+package grpcsec
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var testPolicy = Policy{
+	"/shop.Products/*":     {"content_service", "search_service"},
+	"/shop.Products/Write": {"delivery_service"},
+	"*":                    {"admin_service"},
+}
+
+func TestPolicyAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		identity string
+		want     bool
+	}{
+		{"service mask", "/shop.Products/Get", "content_service", true},
+		{"second caller of mask", "/shop.Products/Get", "search_service", true},
+		{"caller outside mask", "/shop.Products/Get", "delivery_service", false},
+		{"exact method", "/shop.Products/Write", "delivery_service", true},
+		{"exact method beats mask", "/shop.Products/Write", "content_service", false},
+		{"any method", "/shop.Orders/Get", "admin_service", true},
+		{"any method is not checked when mask matches", "/shop.Products/Get", "admin_service", false},
+		{"method without rule", "/shop.Orders/Get", "content_service", false},
+		{"empty identity", "/shop.Products/Get", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testPolicy.allowed(tt.method, tt.identity); got != tt.want {
+				t.Errorf("allowed(%q, %q) = %v, want %v", tt.method, tt.identity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		wantCode codes.Code
+	}{
+		{"no peer", context.Background(), "/shop.Products/Get", codes.Unauthenticated},
+		{"no client certificate", peerContext(credentials.TLSInfo{}), "/shop.Products/Get", codes.Unauthenticated},
+		{"empty common name", certContext(""), "/shop.Products/Get", codes.Unauthenticated},
+		{"denied", certContext("content_service"), "/shop.Products/Write", codes.PermissionDenied},
+		{"allowed", certContext("content_service"), "/shop.Products/Get", codes.OK},
+	}
+
+	interceptor := testPolicy.UnaryServerInterceptor()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var identity string
+			handler := func(ctx context.Context, req any) (any, error) {
+				identity, _ = IdentityFromContext(ctx)
+				return "ok", nil
+			}
+
+			_, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v, want %v (err: %v)", code, tt.wantCode, err)
+			}
+
+			if tt.wantCode == codes.OK && identity != "content_service" {
+				t.Errorf("identity in handler = %q, want content_service", identity)
+			}
+		})
+	}
+}
+
+func peerContext(authInfo credentials.AuthInfo) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 50000},
+		AuthInfo: authInfo,
+	})
+}
+
+// certContext - контекст вызова с проверенным клиентским сертификатом commonName
+func certContext(commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+
+	return peerContext(credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	})
+}
@@ -0,0 +1,142 @@
+package grpcsec
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader держит текущие сертификат сервиса и пул CA и перечитывает их с диска,
+// когда файлы меняются. Уже открытые соединения продолжают работать со старым сертификатом,
+// новые рукопожатия используют новый.
+type Reloader struct {
+	cfg Config
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	roots    *x509.CertPool
+	modTimes []time.Time
+}
+
+// Load загружает сертификаты один раз; ошибка означает, что сервис запускать нельзя
+func Load(cfg Config) (*Reloader, error) {
+	if cfg.CAFile == "" || cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("grpc tls is not configured: set GRPC_TLS_CA_FILE, GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE or GRPC_INSECURE=true")
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = defaultReloadInterval
+	}
+
+	r := &Reloader{cfg: cfg}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload перечитывает сертификат, ключ и CA. При ошибке остаются прежние.
+func (r *Reloader) Reload() error {
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("error loading certificate: %w", err)
+	}
+
+	caPEM, err := os.ReadFile(r.cfg.CAFile)
+	if err != nil {
+		return fmt.Errorf("error reading ca: %w", err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("no certificates found in %s", r.cfg.CAFile)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.roots = roots
+	r.modTimes = modTimes
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Run раз в ReloadInterval проверяет время изменения файлов и перечитывает их при изменении
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				slog.Error("error reloading grpc certificates", slog.String("error", err.Error()))
+				continue
+			}
+			slog.Info("grpc certificates reloaded", slog.String("cert", r.cfg.CertFile))
+		}
+	}
+}
+
+func (r *Reloader) changed() bool {
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		slog.Warn("error checking grpc certificates", slog.String("error", err.Error()))
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := range modTimes {
+		if !modTimes[i].Equal(r.modTimes[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Reloader) fileModTimes() ([]time.Time, error) {
+	files := []string{r.cfg.CAFile, r.cfg.CertFile, r.cfg.KeyFile}
+	modTimes := make([]time.Time, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+
+	return modTimes, nil
+}
+
+func (r *Reloader) certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert
+}
+
+func (r *Reloader) rootPool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.roots
+}
@@ -0,0 +1,181 @@
+package grpcsec
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReloaderPicksUpRotatedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	cfg := Config{
+		CAFile:         filepath.Join(dir, "ca.crt"),
+		CertFile:       filepath.Join(dir, "service.crt"),
+		KeyFile:        filepath.Join(dir, "service.key"),
+		ReloadInterval: 10 * time.Millisecond,
+	}
+
+	writePEM(t, cfg.CAFile, "CERTIFICATE", ca.cert.Raw)
+	ca.issue(t, cfg, "products_service", time.Now())
+
+	r, err := Load(cfg)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cn := leafCommonName(t, r); cn != "products_service" {
+		t.Fatalf("common name = %q, want products_service", cn)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+
+	// время изменения сдвигаем явно, иначе на грубых файловых системах перевыпуск не заметен
+	ca.issue(t, cfg, "products_service_v2", time.Now().Add(time.Minute))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for leafCommonName(t, r) != "products_service_v2" {
+		if time.Now().After(deadline) {
+			t.Fatal("rotated certificate was not loaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReloaderKeepsCertificateOnBrokenFiles(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	cfg := Config{
+		CAFile:   filepath.Join(dir, "ca.crt"),
+		CertFile: filepath.Join(dir, "service.crt"),
+		KeyFile:  filepath.Join(dir, "service.key"),
+	}
+
+	writePEM(t, cfg.CAFile, "CERTIFICATE", ca.cert.Raw)
+	ca.issue(t, cfg, "products_service", time.Now())
+
+	r, err := Load(cfg)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if err := os.WriteFile(cfg.CertFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Reload(); err == nil {
+		t.Fatal("Reload with a broken certificate succeeded")
+	}
+	if cn := leafCommonName(t, r); cn != "products_service" {
+		t.Fatalf("common name after failed reload = %q, want products_service", cn)
+	}
+}
+
+func TestLoadRequiresAllFiles(t *testing.T) {
+	if _, err := Load(Config{CAFile: "ca.crt", CertFile: "service.crt"}); err == nil {
+		t.Fatal("Load without key file succeeded")
+	}
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCA{cert: cert, key: key}
+}
+
+// issue выпускает сертификат сервиса commonName в файлы cfg и ставит им время изменения modTime
+func (ca *testCA) issue(t *testing.T, cfg Config, commonName string, modTime time.Time) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writePEM(t, cfg.CertFile, "CERTIFICATE", der)
+	writePEM(t, cfg.KeyFile, "EC PRIVATE KEY", keyDER)
+
+	for _, file := range []string{cfg.CertFile, cfg.KeyFile} {
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func leafCommonName(t *testing.T, r *Reloader) string {
+	t.Helper()
+
+	leaf, err := x509.ParseCertificate(r.certificate().Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return leaf.Subject.CommonName
+}
@@ -0,0 +1,78 @@
+// Package sellerauth определяет продавца, от имени которого шлюз delivery_service меняет
+// товары и данные продавца в products_service и sellers_service.
+// Шлюз передает access токен продавца, выданный auth_service, в метаданных authorization,
+// а сервис сам проверяет его подпись: id продавца из тела запроса без токена не принимается.
+package sellerauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+const roleSeller = "seller"
+
+var (
+	ErrUnauthenticated = errors.New("seller access token is missing or invalid")
+	ErrNotSeller       = errors.New("access token does not belong to a seller")
+)
+
+// accessClaims - поля access токена auth_service, которые нужны сервису
+type accessClaims struct {
+	Role    string `json:"role"`
+	UUID    string `json:"uuid"`
+	Purpose string `json:"purpose,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type Verifier struct {
+	secret []byte
+}
+
+// New создает проверку токенов с ключом ACCESS_TOKEN_SECRET auth_service. С пустым ключом
+// подходил бы токен, подписанный кем угодно, поэтому он обязателен.
+func New(secret string) (*Verifier, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("access token secret is not set")
+	}
+
+	return &Verifier{secret: []byte(secret)}, nil
+}
+
+// SellerID возвращает uuid продавца из access токена в метаданных вызова
+func (v *Verifier) SellerID(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) != 1 {
+		return "", ErrUnauthenticated
+	}
+
+	tokenString, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+
+	var claims accessClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		return v.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || claims.Purpose != "" {
+		return "", ErrUnauthenticated
+	}
+
+	if claims.Role != roleSeller {
+		return "", ErrNotSeller
+	}
+
+	id, err := uuid.Parse(claims.UUID)
+	if err != nil {
+		return "", ErrUnauthenticated
+	}
+
+	return id.String(), nil
+}
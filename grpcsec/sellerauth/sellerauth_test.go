@@ -0,0 +1,105 @@
+package sellerauth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	testSecret   = "access-secret"
+	testSellerID = "0b0e7a43-4a77-4a4c-9a8e-4c3f3cc3b1a1"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, secret string, claims accessClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func sellerClaims(modify func(*accessClaims)) accessClaims {
+	claims := accessClaims{
+		Role: roleSeller,
+		UUID: testSellerID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	if modify != nil {
+		modify(&claims)
+	}
+	return claims
+}
+
+func TestVerifierSellerID(t *testing.T) {
+	valid := signToken(t, jwt.SigningMethodHS256, testSecret, sellerClaims(nil))
+
+	tests := []struct {
+		name    string
+		md      metadata.MD
+		want    string
+		wantErr error
+	}{
+		{"seller token", metadata.Pairs("authorization", "Bearer "+valid), testSellerID, nil},
+		{"no metadata", nil, "", ErrUnauthenticated},
+		{"without bearer prefix", metadata.Pairs("authorization", valid), "", ErrUnauthenticated},
+		{"two tokens", metadata.Pairs("authorization", "Bearer "+valid, "authorization", "Bearer "+valid), "", ErrUnauthenticated},
+		{"foreign secret", metadata.Pairs("authorization", "Bearer "+
+			signToken(t, jwt.SigningMethodHS256, "other-secret", sellerClaims(nil))), "", ErrUnauthenticated},
+		{"other signing method", metadata.Pairs("authorization", "Bearer "+
+			signToken(t, jwt.SigningMethodHS512, testSecret, sellerClaims(nil))), "", ErrUnauthenticated},
+		{"expired", metadata.Pairs("authorization", "Bearer "+
+			signToken(t, jwt.SigningMethodHS256, testSecret, sellerClaims(func(c *accessClaims) {
+				c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			}))), "", ErrUnauthenticated},
+		{"without expiration", metadata.Pairs("authorization", "Bearer "+
+			signToken(t, jwt.SigningMethodHS256, testSecret, sellerClaims(func(c *accessClaims) {
+				c.ExpiresAt = nil
+			}))), "", ErrUnauthenticated},
+		{"purpose token", metadata.Pairs("authorization", "Bearer "+
+			signToken(t, jwt.SigningMethodHS256, testSecret, sellerClaims(func(c *accessClaims) {
+				c.Purpose = "password_reset"
+			}))), "", ErrUnauthenticated},
+		{"customer token", metadata.Pairs("authorization", "Bearer "+
+			signToken(t, jwt.SigningMethodHS256, testSecret, sellerClaims(func(c *accessClaims) {
+				c.Role = "customer"
+			}))), "", ErrNotSeller},
+		{"invalid uuid", metadata.Pairs("authorization", "Bearer "+
+			signToken(t, jwt.SigningMethodHS256, testSecret, sellerClaims(func(c *accessClaims) {
+				c.UUID = "42"
+			}))), "", ErrUnauthenticated},
+	}
+
+	verifier, err := New(testSecret)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+			got, err := verifier.SellerID(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SellerID() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SellerID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRequiresSecret(t *testing.T) {
+	if _, err := New(""); err == nil {
+		t.Error("New accepted an empty secret")
+	}
+}
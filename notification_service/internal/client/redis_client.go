@@ -26,12 +26,18 @@ func NewRedisClient(cfg *config.RedisConfig) *RedisSubscriber {
 func (r *RedisSubscriber) ListenAndServe(emailSenderCfg *config.NotifyConfig) {
 	emailSeneder := notifiction.New(emailSenderCfg)
 
-	sub := r.client.Subscribe(context.Background(), r.config.ChanelName)
+	sub := r.client.Subscribe(context.Background(), r.config.ChanelName, r.config.AuthChanelName)
 	defer sub.Close()
 
 	ch := sub.Channel()
 
 	for msg := range ch {
+		if msg.Channel == r.config.AuthChanelName {
+			slog.Info("auth event received", slog.String("channel", msg.Channel))
+			go notifiction.NotifyAuthEvent(emailSeneder, msg.Payload)
+			continue
+		}
+
 		slog.Info("payment message received", slog.String("message", msg.Payload))
 		go notifiction.NotifyUser(emailSeneder, msg.String())
 	}
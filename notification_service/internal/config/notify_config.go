@@ -10,6 +10,8 @@ type NotifyConfig struct {
 	EmailFrom string `yml:"email_from"`
 	AppName   string `yml:"app_name"`
 	Password  string `yml:"password"`
+	// AppURL - адрес фронтенда, на который ведут ссылки из писем
+	AppURL string `yaml:"app_url" env-default:"http://localhost:3000"`
 }
 
 func NewNotifyConfig() *NotifyConfig {
@@ -10,6 +10,8 @@ type RedisConfig struct {
 	Host       string `yml:"host"`
 	Port       string `yml:"port"`
 	ChanelName string `yml:"chanel_name"`
+	// AuthChanelName - канал событий auth_service: подтверждение почты и сброс пароля
+	AuthChanelName string `yaml:"auth_chanel_name" env-default:"auth_events"`
 }
 
 func NewRedisConfig() *RedisConfig {
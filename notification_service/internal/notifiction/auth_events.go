@@ -0,0 +1,62 @@
+package notifiction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/nikoksr/notify"
+)
+
+const (
+	authEventEmailVerification = "email_verification"
+	authEventPasswordReset     = "password_reset"
+)
+
+// authEvent - событие из канала auth_service, формат задан в auth_service/pkg/events
+type authEvent struct {
+	Type      string    `json:"type"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NotifyAuthEvent(s *EmailSender, payload string) {
+	var event authEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		slog.Warn("Error unmarshalling auth event", slog.String("error", err.Error()))
+		return
+	}
+
+	var subject, path string
+	switch event.Type {
+	case authEventEmailVerification:
+		subject = "Подтверждение почты"
+		path = "/verify-email"
+	case authEventPasswordReset:
+		subject = "Восстановление пароля"
+		path = "/reset-password"
+	default:
+		slog.Warn("Unknown auth event type", slog.String("type", event.Type))
+		return
+	}
+
+	link := fmt.Sprintf("%s%s?token=%s", s.cfg.AppURL, path, url.QueryEscape(event.Token))
+	body := fmt.Sprintf("Здравствуйте, %s!\n\nПерейдите по ссылке: %s\nСсылка действует до %s.",
+		event.Username, link, event.ExpiresAt.Format("02.01.2006 15:04"))
+
+	noti := notify.New()
+	noti.UseServices(s.mailTo(event.Email))
+
+	err := noti.Send(context.Background(), subject, body)
+	if err != nil {
+		slog.Warn("Error sending email", slog.String("email", event.Email), slog.String("error", err.Error()))
+		return
+	}
+
+	slog.Info("Email sent successfully", slog.String("email", event.Email), slog.String("type", event.Type))
+}
@@ -11,29 +11,32 @@ import (
 )
 
 type EmailSender struct {
-	cfg          *config.NotifyConfig
-	emailService *mail.Mail
+	cfg *config.NotifyConfig
 }
 
 func New(cfg *config.NotifyConfig) *EmailSender {
-	emailService := mail.New(cfg.AppName, cfg.AppName)
-
-	emailService.AuthenticateSMTP("", cfg.EmailFrom, cfg.Password, "smtp.yandex.ru")
-
 	return &EmailSender{
-		cfg:          cfg,
-		emailService: emailService,
+		cfg: cfg,
 	}
 }
 
+// mailTo - отдельное письмо на каждое уведомление: уведомления отправляются параллельно,
+// а получатели mail.Mail накапливаются, поэтому общий экземпляр использовать нельзя
+func (s *EmailSender) mailTo(email string) *mail.Mail {
+	emailService := mail.New(s.cfg.AppName, s.cfg.AppName)
+	emailService.AuthenticateSMTP("", s.cfg.EmailFrom, s.cfg.Password, "smtp.yandex.ru")
+	emailService.AddReceivers(email)
+
+	return emailService
+}
+
 // msg - сообщение об успешной оплате вида: payment sucess email: "email"
 func NotifyUser(s *EmailSender, msg string) {
 	splitMsg := strings.Fields(msg)
 	emailTo := splitMsg[len(msg)-1]
 
 	noti := notify.New()
-	noti.UseServices(s.emailService)
-	s.emailService.AddReceivers(emailTo)
+	noti.UseServices(s.mailTo(emailTo))
 
 	err := noti.Send(context.Background(), fmt.Sprintf("Поздравляем! Ваша оплата прошла успешно. (%s)"), msg)
 	if err != nil {
@@ -2,11 +2,44 @@ package main
 
 import (
 	"Web-shop/products_service/pkg/api"
+	"Web-shop/products_service/pkg/config"
+	"Web-shop/products_service/pkg/events"
+	"Web-shop/products_service/pkg/outbox"
+	"Web-shop/products_service/pkg/repository"
+	"Web-shop/products_service/pkg/sellers"
+	"context"
+	"grpcsec/sellerauth"
 	"log"
 )
 
 func main() {
-	if err := api.RunProductsServer("0.0.0.0:50051"); err != nil {
+	cfg := config.New()
+
+	verifier, err := sellerauth.New(cfg.AccessTokenSecret)
+	if err != nil {
+		log.Fatalf("Failed to init seller token verification: %v", err)
+	}
+
+	repo, err := repository.New(cfg.DB)
+	if err != nil {
+		log.Fatalf("Failed to init products storage: %v", err)
+	}
+	defer repo.Close()
+
+	// kafka может подняться позже сервиса: producer подключается при отправке, события ждут в outbox
+	producer := events.NewProducer(cfg.Kafka.Broker, cfg.Kafka.Topic)
+	defer producer.Close()
+
+	// события об изменениях товаров уходят в kafka из outbox, даже если запись прошла, пока kafka была недоступна
+	go outbox.NewRelay(repo, producer, cfg.Outbox).Run(context.Background())
+
+	sellersClient, err := sellers.New(cfg.AuthAddr)
+	if err != nil {
+		log.Fatalf("Failed to create auth_service client: %v", err)
+	}
+	defer sellersClient.Close()
+
+	if err := api.RunProductsServer(cfg.GRPCAddr, api.NewServer(repo, sellersClient, verifier)); err != nil {
 		log.Fatalf("Failed to start ProductsService: %v", err)
 	}
 }
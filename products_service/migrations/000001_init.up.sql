@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS products (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    seller_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    price INTEGER NOT NULL CHECK (price > 0),
+    image_url TEXT DEFAULT '' NOT NULL,
+    description TEXT DEFAULT '' NOT NULL,
+    info JSONB DEFAULT '{}' NOT NULL,
+    tags TEXT[] DEFAULT '{}' NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS products_created_at_idx ON products (created_at DESC, id);
+CREATE INDEX IF NOT EXISTS products_seller_id_idx ON products (seller_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS products_tags_idx ON products USING GIN (tags);
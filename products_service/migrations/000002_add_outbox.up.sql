@@ -0,0 +1,19 @@
+-- версия товара растет с каждым изменением, по ней потребители отбрасывают устаревшие события
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version BIGINT DEFAULT 1 NOT NULL;
+
+-- события об изменениях товаров пишутся в той же транзакции, что и сами товары,
+-- и отправляются в kafka отдельным процессом (outbox.Relay) в порядке id
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    version BIGINT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP,
+    attempts INTEGER DEFAULT 0 NOT NULL,
+    last_error TEXT DEFAULT '' NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unsent_idx ON outbox (id) WHERE sent_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_sent_at_idx ON outbox (sent_at) WHERE sent_at IS NOT NULL;
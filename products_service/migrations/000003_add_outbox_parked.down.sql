@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS outbox_parked_idx;
+DROP INDEX IF EXISTS outbox_unsent_idx;
+CREATE INDEX IF NOT EXISTS outbox_unsent_idx ON outbox (id) WHERE sent_at IS NULL;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS parked_at;
@@ -0,0 +1,7 @@
+-- parked_at - событие, которое kafka отказалась принять OUTBOX_MAX_ATTEMPTS раз. Оно больше не
+-- задерживает очередь; вернуть его в отправку: UPDATE outbox SET parked_at = NULL, attempts = 0 WHERE id = ...
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS parked_at TIMESTAMP;
+
+DROP INDEX IF EXISTS outbox_unsent_idx;
+CREATE INDEX IF NOT EXISTS outbox_unsent_idx ON outbox (id) WHERE sent_at IS NULL AND parked_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_parked_idx ON outbox (parked_at) WHERE parked_at IS NOT NULL;
@@ -1,64 +1,433 @@
 package api
 
 import (
+	"Web-shop/products_service/pkg/models"
+	"Web-shop/products_service/pkg/repository"
+	"Web-shop/products_service/pkg/sellers"
 	"context"
+	"encoding/json"
+	"errors"
+	"grpcsec"
+	"grpcsec/sellerauth"
 	"log"
 	"net"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/artemSorokin1/products-grpc-api/gen/go/product"
 )
 
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+	maxBatchSize     = 100
+	defaultScanLimit = 500
+	maxScanLimit     = 1000
+	maxNameLen       = 255
+	maxDescLen       = 5000
+	maxTags          = 20
+	maxTagLen        = 50
+)
+
 // server реализует сгенерированный интерфейс ProductsServiceServer.
 type server struct {
 	pb.UnimplementedProductsServiceServer
-	// сюда можно поместить, например, подключение к вашей БД
+	repo    *repository.Repository
+	sellers *sellers.Client
+	callers *sellerauth.Verifier
 }
 
-// NewServer возвращает экземпляр нашего сервера.
-func NewServer() *server {
-	return &server{}
+// NewServer возвращает экземпляр нашего сервера. События об изменениях товаров
+// репозиторий пишет в outbox, отправляет их outbox.Relay.
+func NewServer(repo *repository.Repository, sellers *sellers.Client, callers *sellerauth.Verifier) *server {
+	return &server{repo: repo, sellers: sellers, callers: callers}
 }
 
 // GetProduct — реализация RPC GetProduct(GetProductRequest) → (GetProductResponse)
 func (s *server) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.GetProductResponse, error) {
-	log.Printf("ProductsService.GetProduct: запрос id=%s", req.Id)
-
-	info, _ := structpb.NewStruct(map[string]interface{}{
-		"color": "red",
-		"size":  "L",
-	})
-
-	product := &pb.Product{
-		Id:          req.Id,
-		Name:        "Тестовый товар",
-		Price:       1234,
-		ImageUrl:    "https://example.com/img.png",
-		Description: "Описание тестового товара",
-		Info:        info,
-		CreatedAt:   timestamppb.Now(),
-		UpdatedAt:   timestamppb.Now(),
-		SellerId:    "123e4567-e89b-12d3-a456-426614174001",
-		Comments:    []string{"gg", "ff", "hh"},
-		Tags:        []string{"тест", "товар", "продукт"},
-		Rating:      4.5,
+	log.Printf("ProductsService.GetProduct: запрос id=%s", req.GetId())
+
+	id, err := parseID(req.GetId(), "product")
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, repoError("GetProduct", err)
+	}
+
+	return &pb.GetProductResponse{Product: toProto(product)}, nil
+}
+
+// CreateProduct публикует товар продавца, от имени которого вызывает шлюз
+func (s *server) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.CreateProductResponse, error) {
+	log.Printf("ProductsService.CreateProduct: запрос seller_id=%s", req.GetSellerId())
+
+	sellerId, err := s.callerSeller(ctx, req.GetSellerId())
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := newProduct("", sellerId, req.GetName(), req.GetPrice(), req.GetImageUrl(),
+		req.GetDescription(), req.GetInfo(), req.GetTags())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCanPublish(ctx, product.SellerID); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, product)
+	if err != nil {
+		return nil, repoError("CreateProduct", err)
+	}
+
+	return &pb.CreateProductResponse{Product: toProto(created)}, nil
+}
+
+func (s *server) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.UpdateProductResponse, error) {
+	log.Printf("ProductsService.UpdateProduct: запрос id=%s seller_id=%s", req.GetId(), req.GetSellerId())
+
+	sellerId, err := s.callerSeller(ctx, req.GetSellerId())
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID(req.GetId(), "product")
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := newProduct(id, sellerId, req.GetName(), req.GetPrice(), req.GetImageUrl(),
+		req.GetDescription(), req.GetInfo(), req.GetTags())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCanPublish(ctx, product.SellerID); err != nil {
+		return nil, err
 	}
 
-	return &pb.GetProductResponse{
-		Product: product,
+	updated, err := s.repo.Update(ctx, product)
+	if err != nil {
+		return nil, repoError("UpdateProduct", err)
+	}
+
+	return &pb.UpdateProductResponse{Product: toProto(updated)}, nil
+}
+
+// DeleteProduct удаляет товар. Снять товар с продажи продавец может и без одобренной анкеты.
+func (s *server) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	log.Printf("ProductsService.DeleteProduct: запрос id=%s seller_id=%s", req.GetId(), req.GetSellerId())
+
+	sellerId, err := s.callerSeller(ctx, req.GetSellerId())
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID(req.GetId(), "product")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Delete(ctx, id, sellerId); err != nil {
+		return nil, repoError("DeleteProduct", err)
+	}
+
+	return &pb.DeleteProductResponse{}, nil
+}
+
+func (s *server) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit < 0 || limit > maxListLimit {
+		return nil, status.Error(codes.InvalidArgument, "limit must be between 1 and 100")
+	}
+	if req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+	}
+
+	sellerId := req.GetSellerId()
+	if sellerId != "" {
+		var err error
+		if sellerId, err = parseID(sellerId, "seller"); err != nil {
+			return nil, err
+		}
+	}
+
+	products, total, err := s.repo.List(ctx, sellerId, strings.TrimSpace(req.GetTag()), limit, int(req.GetOffset()))
+	if err != nil {
+		return nil, repoError("ListProducts", err)
+	}
+
+	resp := &pb.ListProductsResponse{
+		Products: make([]*pb.Product, 0, len(products)),
+		Total:    total,
+	}
+	for _, product := range products {
+		resp.Products = append(resp.Products, toProto(product))
+	}
+
+	return resp, nil
+}
+
+// BatchGetProducts - товары для выдачи поиска одним запросом
+func (s *server) BatchGetProducts(ctx context.Context, req *pb.BatchGetProductsRequest) (*pb.BatchGetProductsResponse, error) {
+	ids := make([]string, 0, len(req.GetIds()))
+	seen := make(map[string]bool, len(req.GetIds()))
+	for _, rawId := range req.GetIds() {
+		id, err := parseID(rawId, "product")
+		if err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > maxBatchSize {
+		return nil, status.Error(codes.InvalidArgument, "too many product ids, max 100")
+	}
+
+	products, err := s.repo.BatchGet(ctx, ids)
+	if err != nil {
+		return nil, repoError("BatchGetProducts", err)
+	}
+
+	found := make(map[string]models.Product, len(products))
+	for _, product := range products {
+		found[product.ID] = product
+	}
+
+	resp := &pb.BatchGetProductsResponse{Products: make([]*pb.Product, 0, len(ids))}
+	for _, id := range ids {
+		product, ok := found[id]
+		if !ok {
+			resp.MissingIds = append(resp.MissingIds, id)
+			continue
+		}
+		resp.Products = append(resp.Products, toProto(product))
+	}
+
+	return resp, nil
+}
+
+// ScanProducts - страница полного обхода каталога для переиндексации поиска
+func (s *server) ScanProducts(ctx context.Context, req *pb.ScanProductsRequest) (*pb.ScanProductsResponse, error) {
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultScanLimit
+	}
+	if limit < 0 || limit > maxScanLimit {
+		return nil, status.Error(codes.InvalidArgument, "limit must be between 1 and 1000")
+	}
+
+	afterId := req.GetAfterId()
+	if afterId != "" {
+		var err error
+		if afterId, err = parseID(afterId, "product"); err != nil {
+			return nil, err
+		}
+	}
+
+	products, err := s.repo.Scan(ctx, afterId, limit)
+	if err != nil {
+		return nil, repoError("ScanProducts", err)
+	}
+
+	resp := &pb.ScanProductsResponse{Products: make([]*pb.Product, 0, len(products))}
+	if afterId == "" {
+		if resp.Total, err = s.repo.Count(ctx); err != nil {
+			return nil, repoError("ScanProducts", err)
+		}
+	}
+	for _, product := range products {
+		resp.Products = append(resp.Products, toProto(product))
+	}
+	if len(products) == limit {
+		resp.NextAfterId = products[len(products)-1].ID
+	}
+
+	return resp, nil
+}
+
+// callerSeller возвращает продавца из access токена вызова. seller_id из запроса необязателен,
+// но если указан, должен совпадать с продавцом из токена.
+func (s *server) callerSeller(ctx context.Context, requestSellerId string) (string, error) {
+	sellerId, err := s.callers.SellerID(ctx)
+	switch {
+	case errors.Is(err, sellerauth.ErrNotSeller):
+		return "", status.Error(codes.PermissionDenied, "only sellers can change products")
+	case err != nil:
+		return "", status.Error(codes.Unauthenticated, "seller access token is required")
+	}
+
+	if requestSellerId != "" {
+		if parsed, err := uuid.Parse(requestSellerId); err != nil || parsed.String() != sellerId {
+			return "", status.Error(codes.PermissionDenied, "seller can change only own products")
+		}
+	}
+
+	return sellerId, nil
+}
+
+func (s *server) checkCanPublish(ctx context.Context, sellerId string) error {
+	err := s.sellers.CheckCanPublish(ctx, sellerId)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sellers.ErrSellerNotFound):
+		return status.Error(codes.NotFound, "seller not found")
+	case errors.Is(err, sellers.ErrNotApproved):
+		return status.Error(codes.PermissionDenied, "only approved sellers can publish products")
+	default:
+		log.Printf("ProductsService: ошибка проверки продавца %s: %v", sellerId, err)
+		return status.Error(codes.Unavailable, "unable to check seller status")
+	}
+}
+
+func newProduct(id, sellerId, name string, price int32, imageURL, description string, info *structpb.Struct, tags []string) (models.Product, error) {
+	sellerId, err := parseID(sellerId, "seller")
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLen {
+		return models.Product{}, status.Error(codes.InvalidArgument, "name is required, max 255 characters")
+	}
+	if price <= 0 {
+		return models.Product{}, status.Error(codes.InvalidArgument, "price must be positive")
+	}
+	if utf8.RuneCountInString(description) > maxDescLen {
+		return models.Product{}, status.Error(codes.InvalidArgument, "description is too long, max 5000 characters")
+	}
+
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	infoJSON := types.JSONText("{}")
+	if info != nil {
+		data, err := json.Marshal(info.AsMap())
+		if err != nil {
+			return models.Product{}, status.Error(codes.InvalidArgument, "invalid info")
+		}
+		infoJSON = data
+	}
+
+	return models.Product{
+		ID:          id,
+		SellerID:    sellerId,
+		Name:        name,
+		Price:       price,
+		ImageURL:    strings.TrimSpace(imageURL),
+		Description: strings.TrimSpace(description),
+		Info:        infoJSON,
+		Tags:        normalized,
 	}, nil
 }
 
-func RunProductsServer(listenAddr string) error {
+// normalizeTags приводит теги к нижнему регистру и убирает пустые и повторяющиеся
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLen {
+			return nil, status.Error(codes.InvalidArgument, "tag is too long, max 50 characters")
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > maxTags {
+		return nil, status.Error(codes.InvalidArgument, "too many tags, max 20")
+	}
+
+	return normalized, nil
+}
+
+// parseID проверяет uuid и приводит его к каноническому виду, в котором он хранится в базе
+func parseID(id, kind string) (string, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid %s id", kind)
+	}
+
+	return parsed.String(), nil
+}
+
+func repoError(method string, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrProductNotFound):
+		return status.Error(codes.NotFound, "product not found")
+	case errors.Is(err, repository.ErrNotOwner):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		log.Printf("ProductsService.%s: ошибка базы: %v", method, err)
+		return status.Error(codes.Internal, "unable to process product")
+	}
+}
+
+func toProto(product models.Product) *pb.Product {
+	var info *structpb.Struct
+	var fields map[string]interface{}
+	if err := json.Unmarshal(product.Info, &fields); err == nil {
+		info, _ = structpb.NewStruct(fields)
+	}
+
+	return &pb.Product{
+		Id:          product.ID,
+		Name:        product.Name,
+		Price:       product.Price,
+		ImageUrl:    product.ImageURL,
+		Description: product.Description,
+		Info:        info,
+		CreatedAt:   timestamppb.New(product.CreatedAt),
+		UpdatedAt:   timestamppb.New(product.UpdatedAt),
+		SellerId:    product.SellerID,
+		Tags:        product.Tags,
+		Version:     product.Version,
+	}
+}
+
+// callersPolicy - товары читает content_service, search_service обходит каталог при переиндексации.
+// Менять их может только шлюз delivery_service от имени продавца, продавца определяет его access токен
+// (см. sellerauth).
+var callersPolicy = grpcsec.Policy{
+	"/" + pb.ProductsService_ServiceDesc.ServiceName + "/*": {"content_service", "search_service"},
+	pb.ProductsService_CreateProduct_FullMethodName:         {"delivery_service"},
+	pb.ProductsService_UpdateProduct_FullMethodName:         {"delivery_service"},
+	pb.ProductsService_DeleteProduct_FullMethodName:         {"delivery_service"},
+}
+
+func RunProductsServer(listenAddr string, srv *server) error {
 	lis, err := net.Listen("tcp", listenAddr)
 	if err != nil {
 		return err
 	}
-	grpcServer := grpc.NewServer()
-	pb.RegisterProductsServiceServer(grpcServer, NewServer())
+	opts, err := grpcsec.ServerOptions(context.Background(), grpcsec.ConfigFromEnv(), callersPolicy)
+	if err != nil {
+		return err
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterProductsServiceServer(grpcServer, srv)
 
 	log.Printf("ProductsService gRPC запущен на %s", listenAddr)
 	return grpcServer.Serve(lis)
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config собирается из переменных окружения
+type Config struct {
+	GRPCAddr string
+	// AuthAddr - адрес auth_service, у него сервис спрашивает статус проверки продавца
+	AuthAddr string
+	// AccessTokenSecret - ключ access токенов auth_service, по нему проверяется продавец,
+	// от имени которого шлюз меняет товары
+	AccessTokenSecret string
+	Kafka             KafkaConfig
+	Outbox            OutboxConfig
+	DB                DBConfig
+}
+
+// KafkaConfig - куда публикуются изменения товаров. Топик читает search_service.
+type KafkaConfig struct {
+	Broker string
+	Topic  string
+}
+
+// OutboxConfig - как часто и какими пачками outbox.Relay отправляет события, сколько хранит
+// уже отправленные и после скольких отказов kafka принять событие откладывает его
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Retention    time.Duration
+	MaxAttempts  int
+}
+
+type DBConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+}
+
+func New() Config {
+	return Config{
+		GRPCAddr:          getenv("GRPC_ADDR", "0.0.0.0:50051"),
+		AuthAddr:          getenv("GRPC_AUTH_ADDRESS", "auth_service:50052"),
+		AccessTokenSecret: os.Getenv("ACCESS_TOKEN_SECRET"),
+		Kafka: KafkaConfig{
+			Broker: getenv("KAFKA_BROKER", "kafka:9092"),
+			Topic:  getenv("KAFKA_TOPIC", "products"),
+		},
+		Outbox: OutboxConfig{
+			PollInterval: getduration("OUTBOX_POLL_INTERVAL", time.Second),
+			BatchSize:    getint("OUTBOX_BATCH_SIZE", 100),
+			Retention:    getduration("OUTBOX_RETENTION", 7*24*time.Hour),
+			MaxAttempts:  getint("OUTBOX_MAX_ATTEMPTS", 5),
+		},
+		DB: DBConfig{
+			Host:     getenv("DB_HOST", "products_db"),
+			Port:     getenv("DB_PORT", "5432"),
+			User:     os.Getenv("DB_USER"),
+			Password: os.Getenv("DB_PASSWORD"),
+			Name:     getenv("DB_NAME", "products"),
+		},
+	}
+}
+
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+		c.Host, c.User, c.Password, c.Name, c.Port)
+}
+
+func getenv(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return def
+}
+
+func getint(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("config: некорректное значение %s=%q, используется %d", key, value, def)
+		return def
+	}
+
+	return n
+}
+
+func getduration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("config: некорректное значение %s=%q, используется %s", key, value, def)
+		return def
+	}
+
+	return d
+}
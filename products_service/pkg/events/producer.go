@@ -0,0 +1,187 @@
+// Package events описывает события об изменениях товаров и отправляет их в kafka. Топик читает
+// search_service: по событиям он обновляет поисковый индекс, а content_service получает из поиска id товаров.
+// События пишутся в outbox в транзакции изменения товара, в kafka их отправляет outbox.Relay.
+package events
+
+import (
+	"Web-shop/products_service/pkg/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// События об изменениях товаров, так они записываются в outbox
+const (
+	ProductCreated = "product.created"
+	ProductUpdated = "product.updated"
+	ProductDeleted = "product.deleted"
+)
+
+// Типы сообщений топика: upsert заменяет документ товара целиком, patch меняет только
+// переданные поля, delete удаляет товар из поиска
+const (
+	TypeUpsert = "upsert"
+	TypePatch  = "patch"
+	TypeDelete = "delete"
+)
+
+// Event - сообщение топика. Version растет с каждым изменением товара: событие с версией
+// не больше уже обработанной устарело, и search_service его отбрасывает.
+type Event struct {
+	Type       string    `json:"type"`
+	ProductID  string    `json:"product_id"`
+	Version    int64     `json:"version"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Product заполнен только для upsert
+	Product *Product `json:"product,omitempty"`
+	// Patch заполнен только для patch, ключи - поля Product
+	Patch map[string]any `json:"patch,omitempty"`
+}
+
+// Product - товар в том виде, в котором его видит поиск. Поля name, description, tags, seller,
+// price, created_at и sizes из info search_service индексирует.
+type Product struct {
+	ProductID   string          `json:"product_id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Tags        []string        `json:"tags"`
+	Seller      string          `json:"seller"`
+	Price       int32           `json:"price"`
+	ImageURL    string          `json:"image_url"`
+	Info        json.RawMessage `json:"info,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Marshal собирает сообщение топика о событии товара. Товар продавец всегда меняет целиком,
+// поэтому созданный и измененный товар уходят как upsert.
+func Marshal(event string, product models.Product) ([]byte, error) {
+	msg := Event{
+		Type:       TypeUpsert,
+		ProductID:  product.ID,
+		Version:    product.Version,
+		OccurredAt: product.UpdatedAt,
+	}
+
+	switch event {
+	case ProductCreated, ProductUpdated:
+		tags := []string(product.Tags)
+		if tags == nil {
+			tags = []string{}
+		}
+		msg.Product = &Product{
+			ProductID:   product.ID,
+			Name:        product.Name,
+			Description: product.Description,
+			Tags:        tags,
+			Seller:      product.SellerID,
+			Price:       product.Price,
+			ImageURL:    product.ImageURL,
+			Info:        json.RawMessage(product.Info),
+			CreatedAt:   product.CreatedAt,
+			UpdatedAt:   product.UpdatedAt,
+		}
+	case ProductDeleted:
+		msg.Type = TypeDelete
+		msg.OccurredAt = time.Now().UTC()
+	default:
+		return nil, fmt.Errorf("unknown product event %q", event)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling product event: %w", err)
+	}
+
+	return data, nil
+}
+
+// ErrRejected - kafka отказалась принять само сообщение (слишком большое, некорректное).
+// Повтор такого сообщения не поможет, в отличие от недоступности брокера.
+var ErrRejected = errors.New("kafka rejected the message")
+
+// Producer подключается к kafka при первой отправке, а после ошибки подключения - при следующей:
+// сервис стартует и принимает изменения товаров, даже пока kafka недоступна, события ждут в outbox.
+type Producer struct {
+	broker string
+	topic  string
+	config *sarama.Config
+
+	mu       sync.Mutex
+	producer sarama.SyncProducer
+}
+
+func NewProducer(broker, topic string) *Producer {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Return.Successes = true
+
+	return &Producer{broker: broker, topic: topic, config: config}
+}
+
+// Send отправляет готовое сообщение. Ключ сообщения - id товара, поэтому события
+// одного товара попадают в одну партицию и читаются по порядку.
+func (p *Producer) Send(key string, value []byte) error {
+	producer, err := p.connect()
+	if err != nil {
+		return err
+	}
+
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(value),
+	})
+	if err != nil {
+		if rejected(err) {
+			return fmt.Errorf("error sending product event: %w: %w", ErrRejected, err)
+		}
+		return fmt.Errorf("error sending product event: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Producer) connect() (sarama.SyncProducer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.producer != nil {
+		return p.producer, nil
+	}
+
+	producer, err := sarama.NewSyncProducer([]string{p.broker}, p.config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating kafka producer: %w", err)
+	}
+	p.producer = producer
+
+	return producer, nil
+}
+
+func rejected(err error) bool {
+	var configErr sarama.ConfigurationError
+	if errors.As(err, &configErr) {
+		return true
+	}
+
+	return errors.Is(err, sarama.ErrMessageSizeTooLarge) ||
+		errors.Is(err, sarama.ErrInvalidMessage) ||
+		errors.Is(err, sarama.ErrInvalidRecord)
+}
+
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.producer == nil {
+		return nil
+	}
+
+	return p.producer.Close()
+}
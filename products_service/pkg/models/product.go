@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+)
+
+// Product - товар каталога. SellerID - uuid продавца из auth_service, Info - произвольные
+// характеристики товара в JSON.
+type Product struct {
+	ID          string         `db:"id"`
+	SellerID    string         `db:"seller_id"`
+	Name        string         `db:"name"`
+	Price       int32          `db:"price"`
+	ImageURL    string         `db:"image_url"`
+	Description string         `db:"description"`
+	Info        types.JSONText `db:"info"`
+	Tags        pq.StringArray `db:"tags"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+	Version     int64          `db:"version"`
+}
+
+// OutboxMessage - событие, записанное вместе с изменением товара и ожидающее отправки в kafka
+type OutboxMessage struct {
+	ID          int64          `db:"id"`
+	AggregateID string         `db:"aggregate_id"`
+	EventType   string         `db:"event_type"`
+	Version     int64          `db:"version"`
+	Payload     types.JSONText `db:"payload"`
+	CreatedAt   time.Time      `db:"created_at"`
+	SentAt      *time.Time     `db:"sent_at"`
+	Attempts    int            `db:"attempts"`
+	LastError   string         `db:"last_error"`
+	// ParkedAt - когда событие отложено после OutboxConfig.MaxAttempts отказов kafka принять его
+	ParkedAt *time.Time `db:"parked_at"`
+}
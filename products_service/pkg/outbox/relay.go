@@ -0,0 +1,105 @@
+// Package outbox отправляет в kafka события, которые репозиторий записал в таблицу outbox
+// в одной транзакции с изменением товара. Событие не теряется, даже если kafka была недоступна
+// в момент записи, но может уйти повторно - потребители отбрасывают его по версии.
+package outbox
+
+import (
+	"Web-shop/products_service/pkg/config"
+	"Web-shop/products_service/pkg/models"
+	"Web-shop/products_service/pkg/repository"
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	maxBackoff      = time.Minute
+	cleanupInterval = time.Hour
+)
+
+// Store - таблица outbox, ее реализует repository.Repository
+type Store interface {
+	ProcessOutbox(ctx context.Context, limit, maxAttempts int, send func(models.OutboxMessage) error) (repository.OutboxResult, error)
+	DeleteSentOutbox(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Producer - отправка события в kafka, ее реализует events.Producer
+type Producer interface {
+	Send(key string, value []byte) error
+}
+
+type Relay struct {
+	repo     Store
+	producer Producer
+	cfg      config.OutboxConfig
+}
+
+func NewRelay(repo Store, producer Producer, cfg config.OutboxConfig) *Relay {
+	return &Relay{repo: repo, producer: producer, cfg: cfg}
+}
+
+// Run отправляет события, пока не отменен ctx. Полная пачка сразу забирает следующую,
+// после ошибки повтор откладывается экспоненциально, но не дольше maxBackoff.
+func (r *Relay) Run(ctx context.Context) {
+	log.Printf("outbox relay запущен, пачка %d, опрос раз в %s", r.cfg.BatchSize, r.cfg.PollInterval)
+
+	failures := 0
+	lastCleanup := time.Time{}
+	for {
+		delay := r.cfg.PollInterval
+
+		result, err := r.repo.ProcessOutbox(ctx, r.cfg.BatchSize, r.cfg.MaxAttempts, r.send)
+		for _, message := range result.Parked {
+			log.Printf("outbox relay: событие %d (%s товара %s) отложено после %d попыток: %s",
+				message.ID, message.EventType, message.AggregateID, message.Attempts, message.LastError)
+		}
+
+		switch processed := result.Sent + len(result.Parked); {
+		case err != nil:
+			failures++
+			delay = backoff(r.cfg.PollInterval, failures)
+			log.Printf("outbox relay: отправлено %d, ошибка (попытка %d, повтор через %s): %v", result.Sent, failures, delay, err)
+		case processed == r.cfg.BatchSize:
+			failures = 0
+			delay = 0
+		default:
+			failures = 0
+		}
+
+		if time.Since(lastCleanup) > cleanupInterval {
+			lastCleanup = time.Now()
+			r.cleanup(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("outbox relay остановлен")
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (r *Relay) send(message models.OutboxMessage) error {
+	return r.producer.Send(message.AggregateID, message.Payload)
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	deleted, err := r.repo.DeleteSentOutbox(ctx, time.Now().Add(-r.cfg.Retention))
+	if err != nil {
+		log.Printf("outbox relay: ошибка очистки отправленных событий: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("outbox relay: удалено %d отправленных событий", deleted)
+	}
+}
+
+func backoff(base time.Duration, failures int) time.Duration {
+	delay := base
+	for i := 1; i < failures && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
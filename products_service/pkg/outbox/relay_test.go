@@ -0,0 +1,162 @@
+package outbox
+
+import (
+	"Web-shop/products_service/pkg/config"
+	"Web-shop/products_service/pkg/models"
+	"Web-shop/products_service/pkg/repository"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProducer запоминает доставленные события и отвечает ошибкой на первые fail[key] отправки
+type fakeProducer struct {
+	mu        sync.Mutex
+	fail      map[string]int
+	delivered []string
+	attempts  []string
+}
+
+func (p *fakeProducer) Send(key string, value []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.attempts = append(p.attempts, key)
+	if p.fail[key] > 0 {
+		p.fail[key]--
+		return errors.New("kafka: client has run out of available brokers")
+	}
+	p.delivered = append(p.delivered, key+":"+string(value))
+	return nil
+}
+
+// fakeStore - очередь outbox в памяти: отдает до limit неотправленных событий по порядку
+// и останавливается на первой ошибке, как repository.ProcessOutbox. Когда очередь пустеет,
+// вызывает drained.
+type fakeStore struct {
+	mu       sync.Mutex
+	queue    []models.OutboxMessage
+	batches  []int
+	cleanups []time.Time
+	drained  func()
+}
+
+func (s *fakeStore) ProcessOutbox(_ context.Context, limit, _ int, send func(models.OutboxMessage) error) (repository.OutboxResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result repository.OutboxResult
+	var err error
+	for len(s.queue) > 0 && result.Sent < limit {
+		if err = send(s.queue[0]); err != nil {
+			break
+		}
+		s.queue = s.queue[1:]
+		result.Sent++
+	}
+	s.batches = append(s.batches, result.Sent)
+	if len(s.queue) == 0 {
+		s.drained()
+	}
+	return result, err
+}
+
+func (s *fakeStore) DeleteSentOutbox(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanups = append(s.cleanups, before)
+	return 0, nil
+}
+
+func testMessages(count int) []models.OutboxMessage {
+	messages := make([]models.OutboxMessage, 0, count)
+	for i := 1; i <= count; i++ {
+		messages = append(messages, models.OutboxMessage{
+			ID:          int64(i),
+			AggregateID: fmt.Sprintf("p%d", i),
+			Payload:     []byte(fmt.Sprintf(`{"version":%d}`, i)),
+		})
+	}
+	return messages
+}
+
+// runRelay запускает Run и ждет, пока fakeStore не отправит всю очередь
+func runRelay(t *testing.T, store *fakeStore, producer *fakeProducer, cfg config.OutboxConfig) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store.drained = cancel
+
+	done := make(chan struct{})
+	go func() {
+		NewRelay(store, producer, cfg).Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay did not send the queue")
+	}
+}
+
+func TestRelayRun(t *testing.T) {
+	store := &fakeStore{queue: testMessages(5)}
+	producer := &fakeProducer{}
+	// полная пачка сразу забирает следующую, поэтому час опроса тест не ждет
+	cfg := config.OutboxConfig{PollInterval: time.Hour, BatchSize: 2, Retention: 24 * time.Hour, MaxAttempts: 3}
+	start := time.Now()
+	runRelay(t, store, producer, cfg)
+
+	want := []string{`p1:{"version":1}`, `p2:{"version":2}`, `p3:{"version":3}`, `p4:{"version":4}`, `p5:{"version":5}`}
+	if !slices.Equal(producer.delivered, want) {
+		t.Errorf("delivered = %v, want %v", producer.delivered, want)
+	}
+	if !slices.Equal(store.batches, []int{2, 2, 1}) {
+		t.Errorf("batches = %v, want [2 2 1]", store.batches)
+	}
+	if len(store.cleanups) != 1 || store.cleanups[0].Before(start.Add(-cfg.Retention)) {
+		t.Errorf("cleanups = %v, want one with retention %s", store.cleanups, cfg.Retention)
+	}
+}
+
+func TestRelayRetriesInOrder(t *testing.T) {
+	store := &fakeStore{queue: testMessages(3)}
+	producer := &fakeProducer{fail: map[string]int{"p2": 2}}
+	cfg := config.OutboxConfig{PollInterval: time.Millisecond, BatchSize: 10, Retention: time.Hour, MaxAttempts: 3}
+	runRelay(t, store, producer, cfg)
+
+	// пока kafka не приняла p2, p3 не отправляется
+	if want := []string{"p1", "p2", "p2", "p2", "p3"}; !slices.Equal(producer.attempts, want) {
+		t.Errorf("attempts = %v, want %v", producer.attempts, want)
+	}
+	if !slices.Equal(store.batches, []int{1, 0, 2}) {
+		t.Errorf("batches = %v, want [1 0 2]", store.batches)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		base     time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{time.Second, 1, time.Second},
+		{time.Second, 2, 2 * time.Second},
+		{time.Second, 4, 8 * time.Second},
+		{time.Second, 7, maxBackoff},
+		{time.Second, 100, maxBackoff},
+		{2 * time.Minute, 1, maxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := backoff(tt.base, tt.failures); got != tt.want {
+			t.Errorf("backoff(%s, %d) = %s, want %s", tt.base, tt.failures, got, tt.want)
+		}
+	}
+}
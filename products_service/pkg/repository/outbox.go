@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"Web-shop/products_service/pkg/events"
+	"Web-shop/products_service/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// outboxLockKey - ключ advisory lock, под которым работает outbox.Relay. Пока одна реплика
+// сервиса отправляет пачку событий, остальные ее пропускают, поэтому порядок не нарушается.
+const outboxLockKey = 0x6f7574626f78
+
+func addOutbox(ctx context.Context, tx *sqlx.Tx, event string, product models.Product) error {
+	payload, err := events.Marshal(event, product)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+    INSERT INTO outbox (aggregate_id, event_type, version, payload)
+    VALUES ($1, $2, $3, $4)`,
+		product.ID, event, product.Version, payload,
+	)
+	if err != nil {
+		return fmt.Errorf("error writing outbox: %w", err)
+	}
+
+	return nil
+}
+
+// OutboxResult - итог обработки пачки outbox
+type OutboxResult struct {
+	Sent int
+	// Parked - события, которые kafka отказалась принять maxAttempts раз и которые отложены
+	Parked []models.OutboxMessage
+}
+
+// ProcessOutbox передает send до limit неотправленных событий по порядку и отмечает отправленные.
+// На первой ошибке send пачка останавливается: у события увеличивается attempts и сохраняется
+// last_error, а следующие остаются в очереди, чтобы не обогнать его. Событие, которое kafka
+// отвергла (events.ErrRejected) maxAttempts раз, откладывается (parked_at) и больше не держит
+// очередь; недоступность kafka событие не откладывает, сколько бы она ни длилась.
+// Если пачку уже обрабатывает другая реплика, возвращает пустой результат без ошибки.
+func (r *Repository) ProcessOutbox(ctx context.Context, limit, maxAttempts int, send func(models.OutboxMessage) error) (OutboxResult, error) {
+	var result OutboxResult
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("error begin transaction %v", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.GetContext(ctx, &locked, "SELECT pg_try_advisory_xact_lock($1)", outboxLockKey); err != nil {
+		return result, err
+	}
+	if !locked {
+		return result, nil
+	}
+
+	messages := []models.OutboxMessage{}
+	err = tx.SelectContext(ctx, &messages, `
+    SELECT * FROM outbox
+    WHERE sent_at IS NULL AND parked_at IS NULL
+    ORDER BY id
+    LIMIT $1`, limit)
+	if err != nil {
+		return result, err
+	}
+
+	sent, failures, sendErr := sendOutbox(messages, maxAttempts, send)
+	for _, failure := range failures {
+		_, err = tx.ExecContext(ctx, `
+        UPDATE outbox SET attempts = $2, last_error = $3,
+            parked_at = CASE WHEN $4 THEN CURRENT_TIMESTAMP END
+        WHERE id = $1`, failure.message.ID, failure.message.Attempts, failure.message.LastError, failure.park)
+		if err != nil {
+			return OutboxResult{}, err
+		}
+		if failure.park {
+			result.Parked = append(result.Parked, failure.message)
+		}
+	}
+
+	if len(sent) > 0 {
+		_, err = tx.ExecContext(ctx, "UPDATE outbox SET sent_at = CURRENT_TIMESTAMP WHERE id = ANY($1)", pq.Array(sent))
+		if err != nil {
+			return OutboxResult{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return OutboxResult{}, err
+	}
+
+	result.Sent = len(sent)
+	return result, sendErr
+}
+
+// outboxFailure - неудачная попытка отправить событие: message с увеличенным attempts и last_error
+type outboxFailure struct {
+	message models.OutboxMessage
+	park    bool
+}
+
+// sendOutbox передает события send по порядку и останавливается на первой ошибке, после которой
+// событие не откладывается. Возвращает id отправленных событий, неудачные попытки и эту ошибку.
+func sendOutbox(messages []models.OutboxMessage, maxAttempts int, send func(models.OutboxMessage) error) ([]int64, []outboxFailure, error) {
+	sent := make([]int64, 0, len(messages))
+	var failures []outboxFailure
+	for _, message := range messages {
+		err := send(message)
+		if err == nil {
+			sent = append(sent, message.ID)
+			continue
+		}
+
+		message.Attempts++
+		message.LastError = err.Error()
+		park := errors.Is(err, events.ErrRejected) && message.Attempts >= maxAttempts
+		failures = append(failures, outboxFailure{message: message, park: park})
+		if !park {
+			return sent, failures, err
+		}
+	}
+
+	return sent, failures, nil
+}
+
+// DeleteSentOutbox удаляет события, отправленные раньше before
+func (r *Repository) DeleteSentOutbox(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM outbox WHERE sent_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"Web-shop/products_service/pkg/config"
+	"Web-shop/products_service/pkg/events"
+	"Web-shop/products_service/pkg/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/golang-migrate/migrate"
+	"github.com/golang-migrate/migrate/database/postgres"
+	_ "github.com/golang-migrate/migrate/source/file"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrNotOwner        = errors.New("product belongs to another seller")
+)
+
+type Repository struct {
+	db *sqlx.DB
+}
+
+// New подключается к базе и применяет миграции из каталога migrations
+func New(cfg config.DBConfig) (*Repository, error) {
+	db, err := sqlx.Connect("postgres", cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to db: %w", err)
+	}
+
+	if _, err := db.Conn(context.Background()); err != nil {
+		return nil, fmt.Errorf("unable to connect to db: %w", err)
+	}
+
+	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("error creating postgres driver: %w", err)
+	}
+
+	m, err := migrate.NewWithDatabaseInstance("file://migrations", "postgres", driver)
+	if err != nil {
+		return nil, fmt.Errorf("error creating migration instance: %w", err)
+	}
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return nil, fmt.Errorf("error running migrations: %w", err)
+	}
+
+	return &Repository{db: db}, nil
+}
+
+func (r *Repository) Close() error {
+	return r.db.Close()
+}
+
+// Create сохраняет товар и событие product.created в одной транзакции
+func (r *Repository) Create(ctx context.Context, product models.Product) (created models.Product, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Product{}, fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	err = tx.GetContext(ctx, &created, `
+    INSERT INTO products (seller_id, name, price, image_url, description, info, tags)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING *`,
+		product.SellerID, product.Name, product.Price, product.ImageURL, product.Description,
+		product.Info, product.Tags,
+	)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	if err = addOutbox(ctx, tx, events.ProductCreated, created); err != nil {
+		return models.Product{}, err
+	}
+
+	return created, nil
+}
+
+func (r *Repository) Get(ctx context.Context, id string) (models.Product, error) {
+	var product models.Product
+	err := r.db.GetContext(ctx, &product, "SELECT * FROM products WHERE id = $1", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Product{}, ErrProductNotFound
+		}
+		return models.Product{}, err
+	}
+
+	return product, nil
+}
+
+// Update заменяет поля товара, если он принадлежит продавцу product.SellerID, увеличивает
+// его версию и пишет событие product.updated в той же транзакции
+func (r *Repository) Update(ctx context.Context, product models.Product) (updated models.Product, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Product{}, fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if err = lockOwned(ctx, tx, product.ID, product.SellerID); err != nil {
+		return models.Product{}, err
+	}
+
+	err = tx.GetContext(ctx, &updated, `
+    UPDATE products SET
+        name = $2,
+        price = $3,
+        image_url = $4,
+        description = $5,
+        info = $6,
+        tags = $7,
+        updated_at = CURRENT_TIMESTAMP,
+        version = version + 1
+    WHERE id = $1
+    RETURNING *`,
+		product.ID, product.Name, product.Price, product.ImageURL, product.Description,
+		product.Info, product.Tags,
+	)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	if err = addOutbox(ctx, tx, events.ProductUpdated, updated); err != nil {
+		return models.Product{}, err
+	}
+
+	return updated, nil
+}
+
+// Delete удаляет товар продавца sellerId и возвращает удаленную запись. Удаление - тоже
+// изменение товара: событие product.deleted получает следующую версию.
+func (r *Repository) Delete(ctx context.Context, id, sellerId string) (deleted models.Product, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Product{}, fmt.Errorf("error begin transaction %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			errRb := tx.Rollback()
+			if errRb != nil {
+				return
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if err = lockOwned(ctx, tx, id, sellerId); err != nil {
+		return models.Product{}, err
+	}
+
+	err = tx.GetContext(ctx, &deleted, "DELETE FROM products WHERE id = $1 RETURNING *", id)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	deleted.Version++
+	if err = addOutbox(ctx, tx, events.ProductDeleted, deleted); err != nil {
+		return models.Product{}, err
+	}
+
+	return deleted, nil
+}
+
+// List - страница товаров, сначала новые, и общее число подходящих. Пустые sellerId и tag - без фильтра.
+func (r *Repository) List(ctx context.Context, sellerId, tag string, limit, offset int) ([]models.Product, int64, error) {
+	const filter = `
+    FROM products
+    WHERE ($1 = '' OR seller_id::text = $1)
+      AND ($2 = '' OR tags @> ARRAY[$2])`
+
+	var total int64
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*)"+filter, sellerId, tag); err != nil {
+		return nil, 0, err
+	}
+
+	products := []models.Product{}
+	err := r.db.SelectContext(ctx, &products, "SELECT *"+filter+`
+    ORDER BY created_at DESC, id
+    LIMIT $3 OFFSET $4`,
+		sellerId, tag, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+// Scan - следующие limit товаров по возрастанию id после afterId. Пустой afterId - с начала.
+func (r *Repository) Scan(ctx context.Context, afterId string, limit int) ([]models.Product, error) {
+	products := []models.Product{}
+	err := r.db.SelectContext(ctx, &products, `
+    SELECT * FROM products
+    WHERE ($1 = '' OR id > $1::uuid)
+    ORDER BY id
+    LIMIT $2`,
+		afterId, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *Repository) Count(ctx context.Context) (int64, error) {
+	var total int64
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM products"); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// BatchGet возвращает найденные товары в произвольном порядке
+func (r *Repository) BatchGet(ctx context.Context, ids []string) ([]models.Product, error) {
+	products := []models.Product{}
+	err := r.db.SelectContext(ctx, &products, "SELECT * FROM products WHERE id = ANY($1::uuid[])", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func lockOwned(ctx context.Context, tx *sqlx.Tx, id, sellerId string) error {
+	var owner string
+	err := tx.GetContext(ctx, &owner, "SELECT seller_id FROM products WHERE id = $1 FOR UPDATE", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrProductNotFound
+		}
+		return err
+	}
+
+	if owner != sellerId {
+		return ErrNotOwner
+	}
+
+	return nil
+}
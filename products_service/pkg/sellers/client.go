@@ -0,0 +1,68 @@
+// Package sellers спрашивает у auth_service статус проверки продавца: публиковать товары
+// может только продавец с одобренной анкетой.
+package sellers
+
+import (
+	"context"
+	"errors"
+	"grpcsec"
+	"time"
+
+	pb "github.com/artemSorokin1/sellers-grpc-api/gen/go/seller"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	requestTimeout = 5 * time.Second
+	statusApproved = "approved"
+)
+
+var (
+	ErrSellerNotFound = errors.New("seller not found")
+	ErrNotApproved    = errors.New("seller is not approved")
+)
+
+type Client struct {
+	conn   *grpc.ClientConn
+	client pb.SellersServiceClient
+}
+
+func New(addr string) (*Client, error) {
+	transport, err := grpcsec.DialOption(context.Background(), grpcsec.ConfigFromEnv(), "auth_service")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(addr, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{conn: conn, client: pb.NewSellersServiceClient(conn)}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// CheckCanPublish возвращает ErrNotApproved, если анкета продавца еще не одобрена
+func (c *Client) CheckCanPublish(ctx context.Context, sellerId string) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	resp, err := c.client.GetSeller(ctx, &pb.GetSellerRequest{Id: sellerId})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrSellerNotFound
+		}
+		return err
+	}
+
+	if resp.GetSeller().GetVerificationStatus() != statusApproved {
+		return ErrNotApproved
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+#!/bin/sh
+# Генерирует go код grpc api сервисов из .proto в каталоги модулей proto/<модуль>.
+# Сервисы подключают их через replace в go.mod, поэтому сгенерированный код меняется
+# в том же коммите, что и .proto.
+#
+#   ./proto/generate.sh
+#
+# Нужны protoc, protoc-gen-go v1.36.6 и protoc-gen-go-grpc v1.5.1.
+set -e
+
+cd "$(dirname "$0")/.."
+
+gen() {
+  dir=$1; module=$2; src=$3
+  protoc -I "$(dirname "$src")" \
+    --go_out="proto/$dir" --go_opt=module="$module" \
+    --go-grpc_out="proto/$dir" --go-grpc_opt=module="$module" \
+    "$(basename "$src")"
+}
+
+gen auth-proto github.com/artemSorokin1/Auth-proto auth_service/api/auth.proto
+gen sellers-grpc-api github.com/artemSorokin1/sellers-grpc-api sellers_service/api/seller.proto
+gen products-grpc-api github.com/artemSorokin1/products-grpc-api products_service/api/product.proto
@@ -0,0 +1,799 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: seller.proto
+
+package seller
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Seller struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Phone     string                 `protobuf:"bytes,2,opt,name=phone,proto3" json:"phone,omitempty"`
+	Email     string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Fullname  string                 `protobuf:"bytes,4,opt,name=fullname,proto3" json:"fullname,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// статус проверки анкеты: draft, submitted, under_review, approved, rejected.
+	// Публиковать товары может только продавец со статусом approved. Заполняет только auth_service.
+	VerificationStatus string                 `protobuf:"bytes,6,opt,name=verification_status,json=verificationStatus,proto3" json:"verification_status,omitempty"`
+	City               string                 `protobuf:"bytes,7,opt,name=city,proto3" json:"city,omitempty"`
+	UpdatedAt          *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Seller) Reset() {
+	*x = Seller{}
+	mi := &file_seller_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Seller) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Seller) ProtoMessage() {}
+
+func (x *Seller) ProtoReflect() protoreflect.Message {
+	mi := &file_seller_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Seller.ProtoReflect.Descriptor instead.
+func (*Seller) Descriptor() ([]byte, []int) {
+	return file_seller_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Seller) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Seller) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *Seller) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Seller) GetFullname() string {
+	if x != nil {
+		return x.Fullname
+	}
+	return ""
+}
+
+func (x *Seller) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Seller) GetVerificationStatus() string {
+	if x != nil {
+		return x.VerificationStatus
+	}
+	return ""
+}
+
+func (x *Seller) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Seller) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type GetSellerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSellerRequest) Reset() {
+	*x = GetSellerRequest{}
+	mi := &file_seller_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSellerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSellerRequest) ProtoMessage() {}
+
+func (x *GetSellerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_seller_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSellerRequest.ProtoReflect.Descriptor instead.
+func (*GetSellerRequest) Descriptor() ([]byte, []int) {
+	return file_seller_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetSellerRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetSellerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seller        *Seller                `protobuf:"bytes,1,opt,name=seller,proto3" json:"seller,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSellerResponse) Reset() {
+	*x = GetSellerResponse{}
+	mi := &file_seller_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSellerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSellerResponse) ProtoMessage() {}
+
+func (x *GetSellerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_seller_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSellerResponse.ProtoReflect.Descriptor instead.
+func (*GetSellerResponse) Descriptor() ([]byte, []int) {
+	return file_seller_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetSellerResponse) GetSeller() *Seller {
+	if x != nil {
+		return x.Seller
+	}
+	return nil
+}
+
+// CreateSellerRequest и UpdateSellerRequest шлюз передает вместе с access токеном продавца
+// в метаданных authorization: карточка создается и меняется только продавцу из токена.
+// id - uuid аккаунта продавца в auth_service, необязателен, но должен совпадать с токеном.
+type CreateSellerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Phone         string                 `protobuf:"bytes,2,opt,name=phone,proto3" json:"phone,omitempty"`
+	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Fullname      string                 `protobuf:"bytes,4,opt,name=fullname,proto3" json:"fullname,omitempty"`
+	City          string                 `protobuf:"bytes,5,opt,name=city,proto3" json:"city,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSellerRequest) Reset() {
+	*x = CreateSellerRequest{}
+	mi := &file_seller_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSellerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSellerRequest) ProtoMessage() {}
+
+func (x *CreateSellerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_seller_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSellerRequest.ProtoReflect.Descriptor instead.
+func (*CreateSellerRequest) Descriptor() ([]byte, []int) {
+	return file_seller_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateSellerRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CreateSellerRequest) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *CreateSellerRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CreateSellerRequest) GetFullname() string {
+	if x != nil {
+		return x.Fullname
+	}
+	return ""
+}
+
+func (x *CreateSellerRequest) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+type CreateSellerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seller        *Seller                `protobuf:"bytes,1,opt,name=seller,proto3" json:"seller,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSellerResponse) Reset() {
+	*x = CreateSellerResponse{}
+	mi := &file_seller_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSellerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSellerResponse) ProtoMessage() {}
+
+func (x *CreateSellerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_seller_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSellerResponse.ProtoReflect.Descriptor instead.
+func (*CreateSellerResponse) Descriptor() ([]byte, []int) {
+	return file_seller_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateSellerResponse) GetSeller() *Seller {
+	if x != nil {
+		return x.Seller
+	}
+	return nil
+}
+
+// UpdateSellerRequest заменяет все контакты продавца
+type UpdateSellerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Phone         string                 `protobuf:"bytes,2,opt,name=phone,proto3" json:"phone,omitempty"`
+	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Fullname      string                 `protobuf:"bytes,4,opt,name=fullname,proto3" json:"fullname,omitempty"`
+	City          string                 `protobuf:"bytes,5,opt,name=city,proto3" json:"city,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSellerRequest) Reset() {
+	*x = UpdateSellerRequest{}
+	mi := &file_seller_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSellerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSellerRequest) ProtoMessage() {}
+
+func (x *UpdateSellerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_seller_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSellerRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSellerRequest) Descriptor() ([]byte, []int) {
+	return file_seller_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateSellerRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateSellerRequest) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *UpdateSellerRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UpdateSellerRequest) GetFullname() string {
+	if x != nil {
+		return x.Fullname
+	}
+	return ""
+}
+
+func (x *UpdateSellerRequest) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+type UpdateSellerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seller        *Seller                `protobuf:"bytes,1,opt,name=seller,proto3" json:"seller,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSellerResponse) Reset() {
+	*x = UpdateSellerResponse{}
+	mi := &file_seller_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSellerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSellerResponse) ProtoMessage() {}
+
+func (x *UpdateSellerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_seller_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSellerResponse.ProtoReflect.Descriptor instead.
+func (*UpdateSellerResponse) Descriptor() ([]byte, []int) {
+	return file_seller_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateSellerResponse) GetSeller() *Seller {
+	if x != nil {
+		return x.Seller
+	}
+	return nil
+}
+
+// ListSellersRequest - страница продавцов, сначала новые. Пустой city - любой город.
+type ListSellersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	City          string                 `protobuf:"bytes,3,opt,name=city,proto3" json:"city,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSellersRequest) Reset() {
+	*x = ListSellersRequest{}
+	mi := &file_seller_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSellersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSellersRequest) ProtoMessage() {}
+
+func (x *ListSellersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_seller_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSellersRequest.ProtoReflect.Descriptor instead.
+func (*ListSellersRequest) Descriptor() ([]byte, []int) {
+	return file_seller_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListSellersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListSellersRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListSellersRequest) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+type ListSellersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sellers       []*Seller              `protobuf:"bytes,1,rep,name=sellers,proto3" json:"sellers,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSellersResponse) Reset() {
+	*x = ListSellersResponse{}
+	mi := &file_seller_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSellersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSellersResponse) ProtoMessage() {}
+
+func (x *ListSellersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_seller_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSellersResponse.ProtoReflect.Descriptor instead.
+func (*ListSellersResponse) Descriptor() ([]byte, []int) {
+	return file_seller_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListSellersResponse) GetSellers() []*Seller {
+	if x != nil {
+		return x.Sellers
+	}
+	return nil
+}
+
+func (x *ListSellersResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type BatchGetSellersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetSellersRequest) Reset() {
+	*x = BatchGetSellersRequest{}
+	mi := &file_seller_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetSellersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetSellersRequest) ProtoMessage() {}
+
+func (x *BatchGetSellersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_seller_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetSellersRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetSellersRequest) Descriptor() ([]byte, []int) {
+	return file_seller_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BatchGetSellersRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+// BatchGetSellersResponse - найденные продавцы в порядке запроса, ненайденные id - в missing_ids
+type BatchGetSellersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sellers       []*Seller              `protobuf:"bytes,1,rep,name=sellers,proto3" json:"sellers,omitempty"`
+	MissingIds    []string               `protobuf:"bytes,2,rep,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetSellersResponse) Reset() {
+	*x = BatchGetSellersResponse{}
+	mi := &file_seller_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetSellersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetSellersResponse) ProtoMessage() {}
+
+func (x *BatchGetSellersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_seller_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetSellersResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetSellersResponse) Descriptor() ([]byte, []int) {
+	return file_seller_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BatchGetSellersResponse) GetSellers() []*Seller {
+	if x != nil {
+		return x.Sellers
+	}
+	return nil
+}
+
+func (x *BatchGetSellersResponse) GetMissingIds() []string {
+	if x != nil {
+		return x.MissingIds
+	}
+	return nil
+}
+
+var File_seller_proto protoreflect.FileDescriptor
+
+const file_seller_proto_rawDesc = "" +
+	"\n" +
+	"\fseller.proto\x12\x06seller\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9b\x02\n" +
+	"\x06Seller\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05phone\x18\x02 \x01(\tR\x05phone\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x1a\n" +
+	"\bfullname\x18\x04 \x01(\tR\bfullname\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12/\n" +
+	"\x13verification_status\x18\x06 \x01(\tR\x12verificationStatus\x12\x12\n" +
+	"\x04city\x18\a \x01(\tR\x04city\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\"\n" +
+	"\x10GetSellerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\";\n" +
+	"\x11GetSellerResponse\x12&\n" +
+	"\x06seller\x18\x01 \x01(\v2\x0e.seller.SellerR\x06seller\"\x81\x01\n" +
+	"\x13CreateSellerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05phone\x18\x02 \x01(\tR\x05phone\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x1a\n" +
+	"\bfullname\x18\x04 \x01(\tR\bfullname\x12\x12\n" +
+	"\x04city\x18\x05 \x01(\tR\x04city\">\n" +
+	"\x14CreateSellerResponse\x12&\n" +
+	"\x06seller\x18\x01 \x01(\v2\x0e.seller.SellerR\x06seller\"\x81\x01\n" +
+	"\x13UpdateSellerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05phone\x18\x02 \x01(\tR\x05phone\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x1a\n" +
+	"\bfullname\x18\x04 \x01(\tR\bfullname\x12\x12\n" +
+	"\x04city\x18\x05 \x01(\tR\x04city\">\n" +
+	"\x14UpdateSellerResponse\x12&\n" +
+	"\x06seller\x18\x01 \x01(\v2\x0e.seller.SellerR\x06seller\"V\n" +
+	"\x12ListSellersRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\x12\x12\n" +
+	"\x04city\x18\x03 \x01(\tR\x04city\"U\n" +
+	"\x13ListSellersResponse\x12(\n" +
+	"\asellers\x18\x01 \x03(\v2\x0e.seller.SellerR\asellers\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\"*\n" +
+	"\x16BatchGetSellersRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"d\n" +
+	"\x17BatchGetSellersResponse\x12(\n" +
+	"\asellers\x18\x01 \x03(\v2\x0e.seller.SellerR\asellers\x12\x1f\n" +
+	"\vmissing_ids\x18\x02 \x03(\tR\n" +
+	"missingIds2\x8e\x03\n" +
+	"\x0eSellersService\x12B\n" +
+	"\tGetSeller\x12\x18.seller.GetSellerRequest\x1a\x19.seller.GetSellerResponse\"\x00\x12K\n" +
+	"\fCreateSeller\x12\x1b.seller.CreateSellerRequest\x1a\x1c.seller.CreateSellerResponse\"\x00\x12K\n" +
+	"\fUpdateSeller\x12\x1b.seller.UpdateSellerRequest\x1a\x1c.seller.UpdateSellerResponse\"\x00\x12H\n" +
+	"\vListSellers\x12\x1a.seller.ListSellersRequest\x1a\x1b.seller.ListSellersResponse\"\x00\x12T\n" +
+	"\x0fBatchGetSellers\x12\x1e.seller.BatchGetSellersRequest\x1a\x1f.seller.BatchGetSellersResponse\"\x00B9Z7github.com/artemSorokin1/sellers-grpc-api/gen/go/sellerb\x06proto3"
+
+var (
+	file_seller_proto_rawDescOnce sync.Once
+	file_seller_proto_rawDescData []byte
+)
+
+func file_seller_proto_rawDescGZIP() []byte {
+	file_seller_proto_rawDescOnce.Do(func() {
+		file_seller_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_seller_proto_rawDesc), len(file_seller_proto_rawDesc)))
+	})
+	return file_seller_proto_rawDescData
+}
+
+var file_seller_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_seller_proto_goTypes = []any{
+	(*Seller)(nil),                  // 0: seller.Seller
+	(*GetSellerRequest)(nil),        // 1: seller.GetSellerRequest
+	(*GetSellerResponse)(nil),       // 2: seller.GetSellerResponse
+	(*CreateSellerRequest)(nil),     // 3: seller.CreateSellerRequest
+	(*CreateSellerResponse)(nil),    // 4: seller.CreateSellerResponse
+	(*UpdateSellerRequest)(nil),     // 5: seller.UpdateSellerRequest
+	(*UpdateSellerResponse)(nil),    // 6: seller.UpdateSellerResponse
+	(*ListSellersRequest)(nil),      // 7: seller.ListSellersRequest
+	(*ListSellersResponse)(nil),     // 8: seller.ListSellersResponse
+	(*BatchGetSellersRequest)(nil),  // 9: seller.BatchGetSellersRequest
+	(*BatchGetSellersResponse)(nil), // 10: seller.BatchGetSellersResponse
+	(*timestamppb.Timestamp)(nil),   // 11: google.protobuf.Timestamp
+}
+var file_seller_proto_depIdxs = []int32{
+	11, // 0: seller.Seller.created_at:type_name -> google.protobuf.Timestamp
+	11, // 1: seller.Seller.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: seller.GetSellerResponse.seller:type_name -> seller.Seller
+	0,  // 3: seller.CreateSellerResponse.seller:type_name -> seller.Seller
+	0,  // 4: seller.UpdateSellerResponse.seller:type_name -> seller.Seller
+	0,  // 5: seller.ListSellersResponse.sellers:type_name -> seller.Seller
+	0,  // 6: seller.BatchGetSellersResponse.sellers:type_name -> seller.Seller
+	1,  // 7: seller.SellersService.GetSeller:input_type -> seller.GetSellerRequest
+	3,  // 8: seller.SellersService.CreateSeller:input_type -> seller.CreateSellerRequest
+	5,  // 9: seller.SellersService.UpdateSeller:input_type -> seller.UpdateSellerRequest
+	7,  // 10: seller.SellersService.ListSellers:input_type -> seller.ListSellersRequest
+	9,  // 11: seller.SellersService.BatchGetSellers:input_type -> seller.BatchGetSellersRequest
+	2,  // 12: seller.SellersService.GetSeller:output_type -> seller.GetSellerResponse
+	4,  // 13: seller.SellersService.CreateSeller:output_type -> seller.CreateSellerResponse
+	6,  // 14: seller.SellersService.UpdateSeller:output_type -> seller.UpdateSellerResponse
+	8,  // 15: seller.SellersService.ListSellers:output_type -> seller.ListSellersResponse
+	10, // 16: seller.SellersService.BatchGetSellers:output_type -> seller.BatchGetSellersResponse
+	12, // [12:17] is the sub-list for method output_type
+	7,  // [7:12] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_seller_proto_init() }
+func file_seller_proto_init() {
+	if File_seller_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_seller_proto_rawDesc), len(file_seller_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_seller_proto_goTypes,
+		DependencyIndexes: file_seller_proto_depIdxs,
+		MessageInfos:      file_seller_proto_msgTypes,
+	}.Build()
+	File_seller_proto = out.File
+	file_seller_proto_goTypes = nil
+	file_seller_proto_depIdxs = nil
+}
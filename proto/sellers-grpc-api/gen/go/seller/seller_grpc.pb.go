@@ -0,0 +1,283 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: seller.proto
+
+package seller
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SellersService_GetSeller_FullMethodName       = "/seller.SellersService/GetSeller"
+	SellersService_CreateSeller_FullMethodName    = "/seller.SellersService/CreateSeller"
+	SellersService_UpdateSeller_FullMethodName    = "/seller.SellersService/UpdateSeller"
+	SellersService_ListSellers_FullMethodName     = "/seller.SellersService/ListSellers"
+	SellersService_BatchGetSellers_FullMethodName = "/seller.SellersService/BatchGetSellers"
+)
+
+// SellersServiceClient is the client API for SellersService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SellersService реализуют два сервиса:
+//   - sellers_service хранит карточки продавцов (контакты для покупателей) и реализует все методы;
+//   - auth_service отдает только GetSeller по аккаунту продавца, из него sellers_service
+//     подтягивает карточку продавца, которого у себя еще не знает.
+type SellersServiceClient interface {
+	GetSeller(ctx context.Context, in *GetSellerRequest, opts ...grpc.CallOption) (*GetSellerResponse, error)
+	CreateSeller(ctx context.Context, in *CreateSellerRequest, opts ...grpc.CallOption) (*CreateSellerResponse, error)
+	UpdateSeller(ctx context.Context, in *UpdateSellerRequest, opts ...grpc.CallOption) (*UpdateSellerResponse, error)
+	ListSellers(ctx context.Context, in *ListSellersRequest, opts ...grpc.CallOption) (*ListSellersResponse, error)
+	BatchGetSellers(ctx context.Context, in *BatchGetSellersRequest, opts ...grpc.CallOption) (*BatchGetSellersResponse, error)
+}
+
+type sellersServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSellersServiceClient(cc grpc.ClientConnInterface) SellersServiceClient {
+	return &sellersServiceClient{cc}
+}
+
+func (c *sellersServiceClient) GetSeller(ctx context.Context, in *GetSellerRequest, opts ...grpc.CallOption) (*GetSellerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSellerResponse)
+	err := c.cc.Invoke(ctx, SellersService_GetSeller_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sellersServiceClient) CreateSeller(ctx context.Context, in *CreateSellerRequest, opts ...grpc.CallOption) (*CreateSellerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateSellerResponse)
+	err := c.cc.Invoke(ctx, SellersService_CreateSeller_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sellersServiceClient) UpdateSeller(ctx context.Context, in *UpdateSellerRequest, opts ...grpc.CallOption) (*UpdateSellerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateSellerResponse)
+	err := c.cc.Invoke(ctx, SellersService_UpdateSeller_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sellersServiceClient) ListSellers(ctx context.Context, in *ListSellersRequest, opts ...grpc.CallOption) (*ListSellersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSellersResponse)
+	err := c.cc.Invoke(ctx, SellersService_ListSellers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sellersServiceClient) BatchGetSellers(ctx context.Context, in *BatchGetSellersRequest, opts ...grpc.CallOption) (*BatchGetSellersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetSellersResponse)
+	err := c.cc.Invoke(ctx, SellersService_BatchGetSellers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SellersServiceServer is the server API for SellersService service.
+// All implementations must embed UnimplementedSellersServiceServer
+// for forward compatibility.
+//
+// SellersService реализуют два сервиса:
+//   - sellers_service хранит карточки продавцов (контакты для покупателей) и реализует все методы;
+//   - auth_service отдает только GetSeller по аккаунту продавца, из него sellers_service
+//     подтягивает карточку продавца, которого у себя еще не знает.
+type SellersServiceServer interface {
+	GetSeller(context.Context, *GetSellerRequest) (*GetSellerResponse, error)
+	CreateSeller(context.Context, *CreateSellerRequest) (*CreateSellerResponse, error)
+	UpdateSeller(context.Context, *UpdateSellerRequest) (*UpdateSellerResponse, error)
+	ListSellers(context.Context, *ListSellersRequest) (*ListSellersResponse, error)
+	BatchGetSellers(context.Context, *BatchGetSellersRequest) (*BatchGetSellersResponse, error)
+	mustEmbedUnimplementedSellersServiceServer()
+}
+
+// UnimplementedSellersServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSellersServiceServer struct{}
+
+func (UnimplementedSellersServiceServer) GetSeller(context.Context, *GetSellerRequest) (*GetSellerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSeller not implemented")
+}
+func (UnimplementedSellersServiceServer) CreateSeller(context.Context, *CreateSellerRequest) (*CreateSellerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSeller not implemented")
+}
+func (UnimplementedSellersServiceServer) UpdateSeller(context.Context, *UpdateSellerRequest) (*UpdateSellerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSeller not implemented")
+}
+func (UnimplementedSellersServiceServer) ListSellers(context.Context, *ListSellersRequest) (*ListSellersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSellers not implemented")
+}
+func (UnimplementedSellersServiceServer) BatchGetSellers(context.Context, *BatchGetSellersRequest) (*BatchGetSellersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetSellers not implemented")
+}
+func (UnimplementedSellersServiceServer) mustEmbedUnimplementedSellersServiceServer() {}
+func (UnimplementedSellersServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeSellersServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SellersServiceServer will
+// result in compilation errors.
+type UnsafeSellersServiceServer interface {
+	mustEmbedUnimplementedSellersServiceServer()
+}
+
+func RegisterSellersServiceServer(s grpc.ServiceRegistrar, srv SellersServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSellersServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SellersService_ServiceDesc, srv)
+}
+
+func _SellersService_GetSeller_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSellerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SellersServiceServer).GetSeller(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SellersService_GetSeller_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SellersServiceServer).GetSeller(ctx, req.(*GetSellerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SellersService_CreateSeller_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSellerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SellersServiceServer).CreateSeller(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SellersService_CreateSeller_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SellersServiceServer).CreateSeller(ctx, req.(*CreateSellerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SellersService_UpdateSeller_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSellerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SellersServiceServer).UpdateSeller(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SellersService_UpdateSeller_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SellersServiceServer).UpdateSeller(ctx, req.(*UpdateSellerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SellersService_ListSellers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSellersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SellersServiceServer).ListSellers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SellersService_ListSellers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SellersServiceServer).ListSellers(ctx, req.(*ListSellersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SellersService_BatchGetSellers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetSellersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SellersServiceServer).BatchGetSellers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SellersService_BatchGetSellers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SellersServiceServer).BatchGetSellers(ctx, req.(*BatchGetSellersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SellersService_ServiceDesc is the grpc.ServiceDesc for SellersService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SellersService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "seller.SellersService",
+	HandlerType: (*SellersServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSeller",
+			Handler:    _SellersService_GetSeller_Handler,
+		},
+		{
+			MethodName: "CreateSeller",
+			Handler:    _SellersService_CreateSeller_Handler,
+		},
+		{
+			MethodName: "UpdateSeller",
+			Handler:    _SellersService_UpdateSeller_Handler,
+		},
+		{
+			MethodName: "ListSellers",
+			Handler:    _SellersService_ListSellers_Handler,
+		},
+		{
+			MethodName: "BatchGetSellers",
+			Handler:    _SellersService_BatchGetSellers_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "seller.proto",
+}
@@ -0,0 +1,28 @@
+// healthcheck - проверка готовности для HEALTHCHECK docker: в образе distroless нет curl
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	url := "http://localhost:8085/readyz"
+	if len(os.Args) > 1 {
+		url = os.Args[1]
+	}
+
+	client := &http.Client{Timeout: 3 * time.Second}
+	res, err := client.Get(url)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "%s returned %s\n", url, res.Status)
+		os.Exit(1)
+	}
+}
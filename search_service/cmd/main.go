@@ -2,16 +2,30 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
+	"searchservice/internal/catalog"
 	"searchservice/internal/handler"
+	"searchservice/internal/metrics"
+	"searchservice/internal/models"
 	"searchservice/internal/repository"
 	"searchservice/internal/service"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	httpShutdownTimeout = 10 * time.Second
+	// консьюмеру нужно дописать пачку (до 10 секунд) и выйти из группы
+	consumerShutdownTimeout = 15 * time.Second
 )
 
 func main() {
@@ -20,41 +34,176 @@ func main() {
 	kafkaTopic := os.Getenv("KAFKA_TOPIC")
 	groupID := os.Getenv("KAFKA_GROUP_ID")
 	esURL := os.Getenv("ES_URL")
+	// memory - индекс в памяти процесса для разработки без elasticsearch
+	backend := getenv("SEARCH_BACKEND", "elasticsearch")
+	dlqTopic := getenv("KAFKA_DLQ_TOPIC", kafkaTopic+".dlq")
+	maxAttempts, err := strconv.Atoi(getenv("INDEX_MAX_ATTEMPTS", "5"))
+	if err != nil || maxAttempts <= 0 {
+		log.Fatal("INDEX_MAX_ATTEMPTS must be a positive number")
+	}
+	batchSize, err := strconv.Atoi(getenv("INDEX_BATCH_SIZE", "500"))
+	if err != nil || batchSize <= 0 {
+		log.Fatal("INDEX_BATCH_SIZE must be a positive number")
+	}
+	flushInterval, err := time.ParseDuration(getenv("INDEX_FLUSH_INTERVAL", "1s"))
+	if err != nil || flushInterval <= 0 {
+		log.Fatal("INDEX_FLUSH_INTERVAL must be a positive duration")
+	}
+	queryLogInterval, err := time.ParseDuration(getenv("QUERY_LOG_FLUSH_INTERVAL", "10s"))
+	if err != nil || queryLogInterval <= 0 {
+		log.Fatal("QUERY_LOG_FLUSH_INTERVAL must be a positive duration")
+	}
+	// популярный запрос подсказывается, когда его искали столько разных сессий
+	suggestMinSessions, err := strconv.Atoi(getenv("SUGGEST_MIN_SESSIONS", "3"))
+	if err != nil || suggestMinSessions <= 0 || suggestMinSessions > models.MaxQuerySessions {
+		log.Fatalf("SUGGEST_MIN_SESSIONS must be a number between 1 and %d", models.MaxQuerySessions)
+	}
+	// слова, с которыми запросы не подсказываются; без файла список пустой
+	blocklist, err := service.LoadBlocklist(os.Getenv("SUGGEST_BLOCKLIST"))
+	if err != nil {
+		log.Fatalf("Failed to load suggest blocklist: %s", err)
+	}
+	feedbackTopic := getenv("KAFKA_FEEDBACK_TOPIC", "search.feedback")
+	// сколько запросов /feedback в минуту принимается с одного адреса и от одной сессии
+	feedbackRate, err := strconv.Atoi(getenv("FEEDBACK_RATE_LIMIT", "60"))
+	if err != nil || feedbackRate <= 0 {
+		log.Fatal("FEEDBACK_RATE_LIMIT must be a positive number")
+	}
+	// от кого принимается X-Real-IP: шлюз content_service или подсети прокси через запятую
+	feedbackProxies, err := handler.ParseTrustedProxies(getenv("FEEDBACK_TRUSTED_PROXIES", "content_service"))
+	if err != nil {
+		log.Fatalf("FEEDBACK_TRUSTED_PROXIES: %s", err)
+	}
+	// сколько кликов и показов одной сессии учитывается за FEEDBACK_WINDOW
+	feedbackLimits := service.FeedbackLimits{}
+	if feedbackLimits.Window, err = time.ParseDuration(getenv("FEEDBACK_WINDOW", "1h")); err != nil || feedbackLimits.Window <= 0 {
+		log.Fatal("FEEDBACK_WINDOW must be a positive duration")
+	}
+	if feedbackLimits.SessionClicks, err = strconv.Atoi(getenv("FEEDBACK_SESSION_CLICKS", "30")); err != nil || feedbackLimits.SessionClicks <= 0 {
+		log.Fatal("FEEDBACK_SESSION_CLICKS must be a positive number")
+	}
+	if feedbackLimits.SessionImpressions, err = strconv.Atoi(getenv("FEEDBACK_SESSION_IMPRESSIONS", "1000")); err != nil || feedbackLimits.SessionImpressions <= 0 {
+		log.Fatal("FEEDBACK_SESSION_IMPRESSIONS must be a positive number")
+	}
+	// процент сессий, выдача которых переранжируется по кликам; 0 - выключено
+	boostShare, err := strconv.Atoi(getenv("RANKING_BOOST_SHARE", "0"))
+	if err != nil || boostShare < 0 || boostShare > 100 {
+		log.Fatal("RANKING_BOOST_SHARE must be a number between 0 and 100")
+	}
+	// без токена повтор DLQ по HTTP выключен
+	adminToken := os.Getenv("ADMIN_TOKEN")
 
-	if kafkaBroker == "" || kafkaTopic == "" || groupID == "" || esURL == "" {
-		log.Fatal("Environment variables KAFKA_BROKER, KAFKA_TOPIC, KAFKA_GROUP_ID and ES_URL must be set")
+	if kafkaBroker == "" || kafkaTopic == "" || groupID == "" {
+		log.Fatal("Environment variables KAFKA_BROKER, KAFKA_TOPIC and KAFKA_GROUP_ID must be set")
 	}
 
-	// 2. Создаём репозиторий Elasticsearch
-	esRepo, err := repository.NewElasticRepository(esURL)
+	// 2. Создаём поисковый индекс
+	var searchRepo repository.SearchRepository
+	var feedbackRepo repository.FeedbackRepository
+	switch backend {
+	case "elasticsearch":
+		if esURL == "" {
+			log.Fatal("Environment variable ES_URL must be set")
+		}
+		esRepo, err := repository.NewElasticRepository(esURL)
+		if err != nil {
+			log.Fatalf("Failed to create Elasticsearch repository: %s", err)
+		}
+		searchRepo, feedbackRepo = esRepo, esRepo
+	case "memory":
+		// индекс пустой после каждого запуска, поэтому топик читается с начала отдельной группой
+		memoryRepo := repository.NewMemoryRepository()
+		searchRepo, feedbackRepo = memoryRepo, memoryRepo
+		groupID = fmt.Sprintf("%s-memory-%d", groupID, time.Now().UnixNano())
+		log.Printf("Using in-memory search index, consuming topic from the beginning as group %s", groupID)
+	default:
+		log.Fatal("SEARCH_BACKEND must be elasticsearch or memory")
+	}
+
+	// 3. Создаём бизнес-сервис. Журнал запросов и счетчики кликов останавливаются последними,
+	// чтобы записать то, что накопилось за время остановки HTTP.
+	statsCtx, stopStats := context.WithCancel(context.Background())
+	defer stopStats()
+	var statsWG sync.WaitGroup
+
+	queryLog := service.NewQueryLog(searchRepo, queryLogInterval, suggestMinSessions, blocklist)
+	statsWG.Add(1)
+	go func() {
+		defer statsWG.Done()
+		queryLog.Run(statsCtx)
+	}()
+
+	feedbackProducer, err := repository.NewFeedbackTopic(kafkaBroker, feedbackTopic)
 	if err != nil {
-		log.Fatalf("Failed to create Elasticsearch repository: %s", err)
+		log.Fatalf("Failed to create feedback producer: %s", err)
 	}
+	defer feedbackProducer.Close()
+	// счетчики показов и кликов пишутся с тем же интервалом, что и журнал запросов
+	feedback := service.NewFeedback(feedbackRepo, feedbackProducer, queryLogInterval, feedbackLimits)
+	statsWG.Add(1)
+	go func() {
+		defer statsWG.Done()
+		feedback.Run(statsCtx)
+	}()
 
-	// 3. Создаём бизнес-сервис
-	prodService := service.NewProductService(esRepo)
+	prodService := service.NewProductService(searchRepo, queryLog, service.NewRanker(feedbackRepo, boostShare))
 
 	// 4. Запускаем Kafka-консьюмер в отдельной горутине
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	dlq, err := repository.NewDeadLetterQueue(kafkaBroker, dlqTopic)
+	if err != nil {
+		log.Fatalf("Failed to create DLQ producer: %s", err)
+	}
+	defer dlq.Close()
+
+	// повтор DLQ сверяет товары с каталогом, поэтому клиент products_service нужен только вместе с ним
+	var productsCatalog repository.Catalog
+	if adminToken != "" {
+		catalogClient, err := catalog.New(getenv("GRPC_PRODUCTS_ADDRESS", "products_service:50051"))
+		if err != nil {
+			log.Fatalf("Failed to create products_service client: %s", err)
+		}
+		defer catalogClient.Close()
+		productsCatalog = catalogClient
+	}
 
 	kafkaConsumer := repository.NewKafkaConsumer(
 		kafkaBroker,
 		kafkaTopic,
 		groupID,
-		prodService.HandleNewProduct, // callback
+		prodService.HandleProductEvents, // callback
+		dlq,
+		productsCatalog,
+		repository.RetryPolicy{MaxAttempts: maxAttempts, Backoff: 500 * time.Millisecond, MaxBackoff: 10 * time.Second},
+		repository.BatchConfig{Size: batchSize, FlushInterval: flushInterval},
+		metrics.NewIndexerMetrics(),
 	)
 
+	consumerCtx, stopConsumer := context.WithCancel(context.Background())
+	defer stopConsumer()
+	consumerDone := make(chan struct{})
 	go func() {
-		if err := kafkaConsumer.Start(ctx); err != nil {
+		defer close(consumerDone)
+		if err := kafkaConsumer.Start(consumerCtx); err != nil {
 			log.Printf("Kafka consumer stopped with error: %s", err)
 		}
 	}()
 
 	// 5. Настраиваем HTTP-сервер и роутинг
 	searchHandler := handler.NewSearchHandler(prodService)
+	health := handler.NewHealthHandler(searchRepo, kafkaConsumer)
 	mux := http.NewServeMux()
 	mux.Handle("/search", searchHandler)
+	mux.Handle("/suggest", handler.NewSuggestHandler(prodService))
+	mux.Handle("/feedback", handler.NewFeedbackHandler(feedback, feedbackRate, feedbackProxies))
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", health.Live)
+	mux.HandleFunc("/readyz", health.Ready)
+	mux.HandleFunc("/readyz/indexer", health.Indexer)
+	if adminToken != "" {
+		mux.Handle("/admin/dlq/replay", handler.NewDLQHandler(kafkaConsumer, adminToken))
+	} else {
+		log.Println("ADMIN_TOKEN is not set, DLQ replay endpoint is disabled")
+	}
 
 	server := &http.Server{
 		Addr:         ":8085",
@@ -64,18 +213,48 @@ func main() {
 		IdleTimeout:  120 * time.Second,
 	}
 
-	shutdownCh := make(chan os.Signal, 1)
-	signal.Notify(shutdownCh, os.Interrupt, syscall.SIGTERM)
+	serverErr := make(chan error, 1)
 	go func() {
-		<-shutdownCh
-		log.Println("Shutting down HTTP server...")
-		server.Close()
-		cancel()
+		log.Println("HTTP server listening on :8085")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
 	}()
 
-	log.Println("HTTP server listening on :8085")
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("HTTP server error: %s", err)
+	shutdownCh := make(chan os.Signal, 1)
+	signal.Notify(shutdownCh, os.Interrupt, syscall.SIGTERM)
+	select {
+	case <-shutdownCh:
+	case err := <-serverErr:
+		log.Printf("HTTP server error: %s", err)
+	}
+
+	// 6. Останавливаемся по порядку: HTTP дорабатывает начатые запросы, консьюмер индексирует
+	// накопленные пачки и фиксирует offsets, затем записываются журнал запросов и счетчики
+	log.Println("Shutting down HTTP server...")
+	health.Drain()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server shutdown: %s", err)
 	}
+
+	log.Println("Stopping Kafka consumer...")
+	stopConsumer()
+	select {
+	case <-consumerDone:
+	case <-time.After(consumerShutdownTimeout):
+		log.Println("Kafka consumer did not stop in time, uncommitted messages will be read again")
+	}
+
+	stopStats()
+	statsWG.Wait()
 	log.Println("Server stopped")
 }
+
+func getenv(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
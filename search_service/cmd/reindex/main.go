@@ -0,0 +1,78 @@
+// reindex заливает товары в новую версию поискового индекса и переключает на нее алиас products:
+//
+//	reindex -source=kafka           заливка из топика с самого начала (только если kafka хранит его целиком)
+//	reindex -source=catalog         заливка из каталога products_service
+//	reindex -source=kafka -delete-old   удалить предыдущую версию после переключения
+//	reindex -min-ratio=0            переключить алиас, даже если товаров стало заметно меньше
+//	reindex -rollback               вернуть алиас на предыдущую версию
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"searchservice/internal/catalog"
+	"searchservice/internal/reindex"
+	"searchservice/internal/repository"
+)
+
+func main() {
+	source := flag.String("source", reindex.SourceKafka, "where to take products from: kafka or catalog")
+	rollback := flag.Bool("rollback", false, "switch the alias back to the previous index version")
+	deleteOld := flag.Bool("delete-old", false, "delete the previous index version after switching")
+	batchSize := flag.Int("batch", 1000, "documents per bulk request")
+	minRatio := flag.Float64("min-ratio", 0.9, "switch the alias only if the new index has at least this share of the current documents")
+	flag.Parse()
+
+	kafkaBroker := os.Getenv("KAFKA_BROKER")
+	kafkaTopic := os.Getenv("KAFKA_TOPIC")
+	esURL := os.Getenv("ES_URL")
+	if kafkaBroker == "" || kafkaTopic == "" || esURL == "" {
+		log.Fatal("Environment variables KAFKA_BROKER, KAFKA_TOPIC and ES_URL must be set")
+	}
+
+	esRepo, err := repository.NewElasticRepository(esURL)
+	if err != nil {
+		log.Fatalf("Failed to create Elasticsearch repository: %s", err)
+	}
+
+	topic, err := repository.NewTopicReader(kafkaBroker, kafkaTopic)
+	if err != nil {
+		log.Fatalf("Failed to create Kafka reader: %s", err)
+	}
+	defer topic.Close()
+
+	var catalogClient *catalog.Client
+	if *source == reindex.SourceCatalog && !*rollback {
+		catalogClient, err = catalog.New(getenv("GRPC_PRODUCTS_ADDRESS", "products_service:50051"))
+		if err != nil {
+			log.Fatalf("Failed to create products_service client: %s", err)
+		}
+		defer catalogClient.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reindexer := reindex.New(esRepo, topic, catalogClient, *batchSize, *minRatio)
+	if *rollback {
+		err = reindexer.Rollback(ctx)
+	} else {
+		err = reindexer.Run(ctx, *source, *deleteOld)
+	}
+	if err != nil {
+		log.Fatalf("Reindex failed: %s", err)
+	}
+	log.Println("Reindex finished")
+}
+
+func getenv(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
@@ -0,0 +1,105 @@
+// Package catalog обходит каталог products_service - источник истины о товарах - для
+// полной переиндексации поиска и сверяет с ним товары при повторе DLQ
+package catalog
+
+import (
+	"context"
+	"grpcsec"
+	"time"
+
+	"searchservice/internal/models"
+
+	pb "github.com/artemSorokin1/products-grpc-api/gen/go/product"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const scanTimeout = 30 * time.Second
+
+type Client struct {
+	conn *grpc.ClientConn
+	api  pb.ProductsServiceClient
+}
+
+// New подключается к products_service по mTLS с сертификатом search_service
+func New(addr string) (*Client, error) {
+	transport, err := grpcsec.DialOption(context.Background(), grpcsec.ConfigFromEnv(), "products_service")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(addr, transport)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, api: pb.NewProductsServiceClient(conn)}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Page - страница обхода каталога. Next пустой, когда каталог пройден, Total заполнен
+// только для первой страницы.
+type Page struct {
+	Events []*models.ProductEvent
+	Next   string
+	Total  int64
+}
+
+// Scan возвращает следующую после afterID страницу каталога в виде событий upsert
+// с версиями товаров
+func (c *Client) Scan(ctx context.Context, afterID string, limit int) (Page, error) {
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	resp, err := c.api.ScanProducts(ctx, &pb.ScanProductsRequest{AfterId: afterID, Limit: int32(limit)})
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{
+		Events: make([]*models.ProductEvent, 0, len(resp.GetProducts())),
+		Next:   resp.GetNextAfterId(),
+		Total:  resp.GetTotal(),
+	}
+	for _, product := range resp.GetProducts() {
+		updatedAt := product.GetUpdatedAt().AsTime()
+		page.Events = append(page.Events, &models.ProductEvent{
+			Type:       models.EventUpsert,
+			ProductID:  product.GetId(),
+			Version:    max(product.GetVersion(), 1),
+			OccurredAt: updatedAt,
+			Product: &models.Product{
+				ProductID:   product.GetId(),
+				Name:        product.GetName(),
+				Description: product.GetDescription(),
+				Tags:        product.GetTags(),
+				Sizes:       models.SizesFromInfo(product.GetInfo().AsMap()),
+				Seller:      product.GetSellerId(),
+				Price:       product.GetPrice(),
+				CreatedAt:   product.GetCreatedAt().AsTime(),
+				UpdatedAt:   updatedAt,
+			},
+		})
+	}
+	return page, nil
+}
+
+// Version возвращает текущую версию товара id. found = false, если товара в каталоге нет:
+// он удален или id вообще не может быть id товара.
+func (c *Client) Version(ctx context.Context, id string) (version int64, found bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	resp, err := c.api.GetProduct(ctx, &pb.GetProductRequest{Id: id})
+	switch status.Code(err) {
+	case codes.OK:
+		return max(resp.GetProduct().GetVersion(), 1), true, nil
+	case codes.NotFound, codes.InvalidArgument:
+		return 0, false, nil
+	default:
+		return 0, false, err
+	}
+}
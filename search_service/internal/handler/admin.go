@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"searchservice/internal/repository"
+)
+
+const (
+	defaultReplayLimit = 100
+	maxReplayLimit     = 1000
+	replayWriteTimeout = 2 * time.Minute
+)
+
+// DeadLetterReplayer повторно обрабатывает сообщения из DLQ
+type DeadLetterReplayer interface {
+	ReplayDeadLetters(ctx context.Context, limit int) (repository.ReplayResult, error)
+}
+
+type DLQHandler struct {
+	replayer DeadLetterReplayer
+	token    string
+}
+
+// NewDLQHandler - token сверяется с заголовком X-Admin-Token
+func NewDLQHandler(replayer DeadLetterReplayer, token string) *DLQHandler {
+	return &DLQHandler{replayer: replayer, token: token}
+}
+
+// ServeHTTP обрабатывает POST /admin/dlq/replay?limit=…
+func (h *DLQHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Token")), []byte(h.token)) != 1 {
+		http.Error(w, "Invalid admin token", http.StatusUnauthorized)
+		return
+	}
+
+	limit := defaultReplayLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > maxReplayLimit {
+			http.Error(w, "Query parameter 'limit' must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	// повтор идет дольше общего WriteTimeout сервера
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(replayWriteTimeout)); err != nil {
+		log.Printf("Unable to extend write deadline for DLQ replay: %s", err)
+	}
+
+	result, err := h.replayer.ReplayDeadLetters(r.Context(), limit)
+	if errors.Is(err, repository.ErrReplayInProgress) {
+		http.Error(w, "DLQ replay already in progress", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("DLQ replay failed: %s", err)
+		http.Error(w, "Internal server error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
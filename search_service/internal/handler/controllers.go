@@ -1,9 +1,22 @@
 package handler
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"searchservice/internal/models"
 	"searchservice/internal/service"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSearchSize = 20
+	maxSearchSize     = 100
+	// maxSearchWindow - index.max_result_window elasticsearch, дальше листают через search_after
+	maxSearchWindow = 10000
 )
 
 type SearchHandler struct {
@@ -14,25 +27,162 @@ func NewSearchHandler(svc *service.ProductService) *SearchHandler {
 	return &SearchHandler{service: svc}
 }
 
-// ServeHTTP обрабатывает GET /search?q=…
+type searchResponse struct {
+	Total           int64              `json:"total"`
+	ProductIDs      []string           `json:"product_ids"`
+	Hits            []models.SearchHit `json:"hits"`
+	Facets          models.Facets      `json:"facets"`
+	NextSearchAfter string             `json:"next_search_after,omitempty"`
+	// Ranking нужно вернуть в событиях /feedback, чтобы сравнить варианты ранжирования
+	Ranking string `json:"ranking"`
+}
+
+// ServeHTTP обрабатывает GET /search?q=…&price_min=…&price_max=…&tag=…&product_size=…&seller=…&sort=…&from=…&size=…&search_after=…&session=…
+//
+// tag и product_size можно передать несколько раз: нужны все теги и любой из размеров.
+// size - размер страницы, поэтому фильтр по размеру товара называется product_size. sort - relevance (по умолчанию), price_asc, price_desc, newest.
+// search_after - значение next_search_after из предыдущего ответа, с ним from не используется.
+// session - идентификатор клиента, по нему выбирается вариант ранжирования.
 func (h *SearchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	q := r.URL.Query().Get("q")
-	if q == "" {
-		http.Error(w, "Query parameter 'q' is required", http.StatusBadRequest)
+	params, err := parseSearchParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	resultIDs, err := h.service.Search(q)
+	result, err := h.service.Search(params)
 	if err != nil {
 		http.Error(w, "Internal server error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	resp := searchResponse{
+		Total:      result.Total,
+		ProductIDs: make([]string, 0, len(result.Hits)),
+		Hits:       result.Hits,
+		Facets:     result.Facets,
+		Ranking:    result.Ranking,
+	}
+	for _, hit := range result.Hits {
+		resp.ProductIDs = append(resp.ProductIDs, hit.ProductID)
+	}
+	if len(result.Hits) == params.Size {
+		resp.NextSearchAfter = encodeSearchAfter(result.LastSort)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{"product_ids": resultIDs})
+	json.NewEncoder(w).Encode(resp)
+}
+
+func parseSearchParams(query url.Values) (models.SearchParams, error) {
+	params := models.SearchParams{
+		Query:   strings.TrimSpace(query.Get("q")),
+		Seller:  strings.TrimSpace(query.Get("seller")),
+		Session: query.Get("session"),
+		Sort:    query.Get("sort"),
+		Size:    defaultSearchSize,
+	}
+
+	for _, tag := range query["tag"] {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			params.Tags = append(params.Tags, tag)
+		}
+	}
+
+	for _, size := range query["product_size"] {
+		if size = models.NormalizeSize(size); size != "" {
+			params.Sizes = append(params.Sizes, size)
+		}
+	}
+
+	switch params.Sort {
+	case "":
+		params.Sort = models.SortRelevance
+	case models.SortRelevance, models.SortPriceAsc, models.SortPriceDesc, models.SortNewest:
+	default:
+		return params, fmt.Errorf("Query parameter 'sort' must be one of relevance, price_asc, price_desc, newest")
+	}
+	if params.Query == "" && params.Sort == models.SortRelevance && len(params.Tags) == 0 && len(params.Sizes) == 0 && params.Seller == "" &&
+		query.Get("price_min") == "" && query.Get("price_max") == "" {
+		return params, fmt.Errorf("Query parameter 'q' or a filter is required")
+	}
+
+	var err error
+	if params.PriceMin, err = optionalInt(query, "price_min"); err != nil {
+		return params, err
+	}
+	if params.PriceMax, err = optionalInt(query, "price_max"); err != nil {
+		return params, err
+	}
+	if params.PriceMin != nil && params.PriceMax != nil && *params.PriceMin > *params.PriceMax {
+		return params, fmt.Errorf("Query parameter 'price_min' must not exceed 'price_max'")
+	}
+
+	if value := query.Get("size"); value != "" {
+		if params.Size, err = strconv.Atoi(value); err != nil || params.Size <= 0 || params.Size > maxSearchSize {
+			return params, fmt.Errorf("Query parameter 'size' must be between 1 and %d", maxSearchSize)
+		}
+	}
+
+	if value := query.Get("search_after"); value != "" {
+		if params.SearchAfter, err = decodeSearchAfter(value); err != nil {
+			return params, fmt.Errorf("Query parameter 'search_after' is invalid")
+		}
+		return params, nil
+	}
+
+	if value := query.Get("from"); value != "" {
+		if params.From, err = strconv.Atoi(value); err != nil || params.From < 0 {
+			return params, fmt.Errorf("Query parameter 'from' must be a non-negative number")
+		}
+	}
+	if params.From+params.Size > maxSearchWindow {
+		return params, fmt.Errorf("Query parameters 'from' + 'size' must not exceed %d, use 'search_after'", maxSearchWindow)
+	}
+
+	return params, nil
+}
+
+func optionalInt(query url.Values, key string) (*int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("Query parameter '%s' must be a non-negative number", key)
+	}
+	return &n, nil
+}
+
+// search_after передается клиенту непрозрачной строкой: base64 от JSON значений сортировки
+func encodeSearchAfter(sort []any) string {
+	data, err := json.Marshal(sort)
+	if err != nil || len(sort) == 0 {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSearchAfter(token string) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var sort []any
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&sort); err != nil {
+		return nil, err
+	}
+	if len(sort) == 0 {
+		return nil, fmt.Errorf("empty search_after")
+	}
+	return sort, nil
 }
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"searchservice/internal/models"
+	"searchservice/internal/service"
+)
+
+const (
+	maxFeedbackEvents   = 100
+	maxFeedbackBodySize = 64 << 10
+)
+
+type FeedbackHandler struct {
+	feedback *service.Feedback
+	limiter  *rateLimiter
+	proxies  *TrustedProxies
+}
+
+// NewFeedbackHandler принимает не больше ratePerMinute запросов в минуту с одного адреса
+// и столько же от одной сессии. Адрес клиента из X-Real-IP берется только от proxies.
+func NewFeedbackHandler(feedback *service.Feedback, ratePerMinute int, proxies *TrustedProxies) *FeedbackHandler {
+	return &FeedbackHandler{feedback: feedback, limiter: newRateLimiter(ratePerMinute, time.Minute), proxies: proxies}
+}
+
+type feedbackItem struct {
+	ProductID string `json:"product_id"`
+	Position  int    `json:"position"`
+	Type      string `json:"type"`
+}
+
+type feedbackRequest struct {
+	Query   string         `json:"query"`
+	Session string         `json:"session"`
+	Ranking string         `json:"ranking"`
+	Events  []feedbackItem `json:"events"`
+}
+
+// ServeHTTP обрабатывает POST /feedback с показами и кликами по выдаче одного запроса:
+//
+//	{"query": "…", "session": "…", "ranking": "boosted", "events": [{"product_id": "…", "position": 1, "type": "click"}]}
+//
+// ranking - значение из ответа /search, type - impression или click, session обязателен.
+// Повторы и события сверх лимитов сессии принимаются, но не учитываются.
+func (h *FeedbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	now := time.Now()
+	if !h.limiter.allow("addr:"+clientAddr(r, h.proxies, now), now) {
+		http.Error(w, "Too many feedback requests", http.StatusTooManyRequests)
+		return
+	}
+
+	var req feedbackRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFeedbackBodySize)).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Events) == 0 || len(req.Events) > maxFeedbackEvents {
+		http.Error(w, fmt.Sprintf("Request must contain between 1 and %d events", maxFeedbackEvents), http.StatusBadRequest)
+		return
+	}
+	if req.Session != "" && !h.limiter.allow("session:"+req.Session, now) {
+		http.Error(w, "Too many feedback requests", http.StatusTooManyRequests)
+		return
+	}
+
+	events := make([]models.FeedbackEvent, 0, len(req.Events))
+	for _, item := range req.Events {
+		events = append(events, models.FeedbackEvent{
+			Query:     req.Query,
+			ProductID: item.ProductID,
+			Position:  item.Position,
+			Type:      item.Type,
+			Session:   req.Session,
+			Ranking:   req.Ranking,
+		})
+	}
+
+	if err := h.feedback.Record(events); err != nil {
+		if errors.Is(err, models.ErrInvalidFeedback) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Service unavailable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
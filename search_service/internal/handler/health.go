@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// Pinger - зависимость, без которой сервис не может отвечать на поиск
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// GroupMember - консьюмер, который состоит или не состоит в группе Kafka
+type GroupMember interface {
+	Member() bool
+}
+
+type HealthHandler struct {
+	index    Pinger
+	consumer GroupMember
+	draining atomic.Bool
+}
+
+func NewHealthHandler(index Pinger, consumer GroupMember) *HealthHandler {
+	return &HealthHandler{index: index, consumer: consumer}
+}
+
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Drain переводит /readyz в 503 перед остановкой, чтобы на сервис перестали слать запросы
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// Live обрабатывает GET /healthz: процесс жив и отвечает на HTTP
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// Ready обрабатывает GET /readyz: доступен поисковый индекс и сервис не останавливается.
+// Индексатор вне группы консьюмеров (перебалансировка, недоступная kafka) поиск не останавливает:
+// ответ 200 со статусом degraded, выдача только отстает от каталога. Индексатор отдельно
+// проверяет Indexer.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	resp := readinessResponse{Status: "ready", Checks: map[string]string{}}
+	check := func(name string, err string) {
+		if err == "" {
+			resp.Checks[name] = "ok"
+			return
+		}
+		resp.Checks[name] = err
+		resp.Status = "not_ready"
+	}
+
+	if h.draining.Load() {
+		check("shutdown", "shutting down")
+	}
+	if err := h.index.Ping(ctx); err != nil {
+		check("search_index", err.Error())
+	} else {
+		check("search_index", "")
+	}
+	if h.consumer.Member() {
+		resp.Checks["kafka_consumer"] = "ok"
+	} else {
+		resp.Checks["kafka_consumer"] = "not a member of the consumer group"
+		if resp.Status == "ready" {
+			resp.Status = "degraded"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status == "not_ready" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Indexer обрабатывает GET /readyz/indexer: индексатор состоит в группе консьюмеров.
+// Для мониторинга отставания индекса, не для балансировки запросов поиска.
+func (h *HealthHandler) Indexer(w http.ResponseWriter, r *http.Request) {
+	resp := readinessResponse{Status: "ready", Checks: map[string]string{"kafka_consumer": "ok"}}
+	if !h.consumer.Member() {
+		resp.Status = "not_ready"
+		resp.Checks["kafka_consumer"] = "not a member of the consumer group"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != "ready" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
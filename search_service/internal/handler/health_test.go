@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakePinger struct{ err error }
+
+func (p fakePinger) Ping(context.Context) error { return p.err }
+
+type fakeMember bool
+
+func (m fakeMember) Member() bool { return bool(m) }
+
+func TestHealthReadiness(t *testing.T) {
+	const outOfGroup = "not a member of the consumer group"
+
+	tests := []struct {
+		name     string
+		indexErr error
+		member   bool
+		draining bool
+		path     string
+		wantCode int
+		want     readinessResponse
+	}{
+		{
+			name: "ready", member: true, path: "/readyz",
+			wantCode: http.StatusOK,
+			want:     readinessResponse{"ready", map[string]string{"search_index": "ok", "kafka_consumer": "ok"}},
+		},
+		{
+			name: "draining", member: true, draining: true, path: "/readyz",
+			wantCode: http.StatusServiceUnavailable,
+			want:     readinessResponse{"not_ready", map[string]string{"shutdown": "shutting down", "search_index": "ok", "kafka_consumer": "ok"}},
+		},
+		{
+			name: "index unavailable", indexErr: errors.New("connection refused"), member: true, path: "/readyz",
+			wantCode: http.StatusServiceUnavailable,
+			want:     readinessResponse{"not_ready", map[string]string{"search_index": "connection refused", "kafka_consumer": "ok"}},
+		},
+		{
+			// поиск продолжает работать, пока индексатор вне группы
+			name: "indexer out of group", path: "/readyz",
+			wantCode: http.StatusOK,
+			want:     readinessResponse{"degraded", map[string]string{"search_index": "ok", "kafka_consumer": outOfGroup}},
+		},
+		{
+			name: "draining indexer out of group", draining: true, path: "/readyz",
+			wantCode: http.StatusServiceUnavailable,
+			want:     readinessResponse{"not_ready", map[string]string{"shutdown": "shutting down", "search_index": "ok", "kafka_consumer": outOfGroup}},
+		},
+		{
+			name: "indexer ready", member: true, path: "/readyz/indexer",
+			wantCode: http.StatusOK,
+			want:     readinessResponse{"ready", map[string]string{"kafka_consumer": "ok"}},
+		},
+		{
+			name: "indexer out of group on indexer check", path: "/readyz/indexer",
+			wantCode: http.StatusServiceUnavailable,
+			want:     readinessResponse{"not_ready", map[string]string{"kafka_consumer": outOfGroup}},
+		},
+		{
+			// остановка и недоступный индекс индексатор не касаются
+			name: "indexer check while draining", indexErr: errors.New("connection refused"), member: true, draining: true, path: "/readyz/indexer",
+			wantCode: http.StatusOK,
+			want:     readinessResponse{"ready", map[string]string{"kafka_consumer": "ok"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandler(fakePinger{tt.indexErr}, fakeMember(tt.member))
+			if tt.draining {
+				h.Drain()
+			}
+			mux := http.NewServeMux()
+			mux.HandleFunc("/readyz", h.Ready)
+			mux.HandleFunc("/readyz/indexer", h.Indexer)
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("content type = %q", ct)
+			}
+			var got readinessResponse
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.want.Status || !maps.Equal(got.Checks, tt.want.Checks) {
+				t.Errorf("response = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// proxyResolveInterval - как часто перерезолвятся имена доверенных прокси:
+// контейнер шлюза после перезапуска может получить другой адрес
+const proxyResolveInterval = time.Minute
+
+// TrustedProxies - адреса, от которых принимается X-Real-IP: подсети и имена хостов
+// (content_service). От остальных заголовок игнорируется, иначе лимит по адресу
+// обходится подменой X-Real-IP.
+type TrustedProxies struct {
+	nets  []*net.IPNet
+	hosts []string
+	// lookup подменяется в тестах
+	lookup func(host string) ([]net.IP, error)
+
+	mu         sync.Mutex
+	resolved   map[string][]net.IP
+	resolvedAt time.Time
+}
+
+// ParseTrustedProxies разбирает список через запятую: подсети в CIDR, адреса и имена хостов
+func ParseTrustedProxies(list string) (*TrustedProxies, error) {
+	proxies := &TrustedProxies{lookup: net.LookupIP, resolved: make(map[string][]net.IP)}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.Contains(item, "/") {
+			_, ipNet, err := net.ParseCIDR(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy network %q: %w", item, err)
+			}
+			proxies.nets = append(proxies.nets, ipNet)
+			continue
+		}
+		if ip := net.ParseIP(item); ip != nil {
+			proxies.nets = append(proxies.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		proxies.hosts = append(proxies.hosts, item)
+	}
+	return proxies, nil
+}
+
+// trusted сообщает, можно ли верить заголовкам запроса с адреса ip
+func (p *TrustedProxies) trusted(ip net.IP, now time.Time) bool {
+	if p == nil || ip == nil {
+		return false
+	}
+	for _, ipNet := range p.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	if len(p.hosts) == 0 {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if now.Sub(p.resolvedAt) >= proxyResolveInterval {
+		p.resolvedAt = now
+		for _, host := range p.hosts {
+			ips, err := p.lookup(host)
+			if err != nil {
+				// остаются адреса прошлого резолва: шлюз мог просто перезапускаться
+				log.Printf("Failed to resolve trusted proxy %s: %s", host, err)
+				continue
+			}
+			p.resolved[host] = ips
+		}
+	}
+	for _, ips := range p.resolved {
+		for _, proxyIP := range ips {
+			if proxyIP.Equal(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// clientAddr - адрес клиента. X-Real-IP берется только от доверенного прокси,
+// через который приходят запросы клиентов, иначе - адрес соединения.
+func clientAddr(r *http.Request, proxies *TrustedProxies, now time.Time) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !proxies.trusted(net.ParseIP(host), now) {
+		return host
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	return host
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientAddr(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.1.0.0/16, 192.168.5.5, content_service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxies.lookup = func(host string) ([]net.IP, error) {
+		if host != "content_service" {
+			return nil, errors.New("unknown host")
+		}
+		return []net.IP{net.ParseIP("172.18.0.7")}, nil
+	}
+
+	tests := []struct {
+		name       string
+		proxies    *TrustedProxies
+		remoteAddr string
+		realIP     string
+		want       string
+	}{
+		{"direct client", proxies, "203.0.113.9:5123", "", "203.0.113.9"},
+		{"spoofed header from client", proxies, "203.0.113.9:5123", "198.51.100.1", "203.0.113.9"},
+		{"gateway by host name", proxies, "172.18.0.7:40000", "198.51.100.1", "198.51.100.1"},
+		{"proxy network", proxies, "10.1.4.2:40000", "198.51.100.2", "198.51.100.2"},
+		{"proxy address", proxies, "192.168.5.5:40000", " 198.51.100.3 ", "198.51.100.3"},
+		{"gateway without header", proxies, "172.18.0.7:40000", "", "172.18.0.7"},
+		{"no trusted proxies", nil, "172.18.0.7:40000", "198.51.100.1", "172.18.0.7"},
+		{"remote addr without port", proxies, "203.0.113.9", "198.51.100.1", "203.0.113.9"},
+	}
+
+	now := time.Now()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/feedback", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := clientAddr(r, tt.proxies, now); got != tt.want {
+				t.Errorf("clientAddr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrustedProxiesResolve(t *testing.T) {
+	proxies, err := ParseTrustedProxies("content_service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway := net.ParseIP("172.18.0.7")
+	lookups := 0
+	proxies.lookup = func(string) ([]net.IP, error) {
+		lookups++
+		if lookups == 2 {
+			return nil, errors.New("temporary failure")
+		}
+		return []net.IP{gateway}, nil
+	}
+
+	now := time.Now()
+	if !proxies.trusted(gateway, now) {
+		t.Fatal("gateway is not trusted after the first lookup")
+	}
+	proxies.trusted(gateway, now.Add(time.Second))
+	if lookups != 1 {
+		t.Fatalf("lookups = %d within the resolve interval, want 1", lookups)
+	}
+
+	// ошибка резолва не сбрасывает известный адрес шлюза
+	if !proxies.trusted(gateway, now.Add(proxyResolveInterval)) {
+		t.Error("gateway is not trusted after a failed lookup")
+	}
+
+	gateway = net.ParseIP("172.18.0.9")
+	if !proxies.trusted(gateway, now.Add(2*proxyResolveInterval)) {
+		t.Error("gateway is not trusted after its address changed")
+	}
+	if proxies.trusted(net.ParseIP("172.18.0.7"), now.Add(2*proxyResolveInterval)) {
+		t.Error("old gateway address is still trusted")
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	if _, err := ParseTrustedProxies("10.0.0.0/33"); err == nil {
+		t.Error("invalid network is accepted")
+	}
+	proxies, err := ParseTrustedProxies("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proxies.trusted(net.ParseIP("127.0.0.1"), time.Now()) {
+		t.Error("empty list trusts loopback")
+	}
+}
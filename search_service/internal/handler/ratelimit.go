@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"sync"
+	"time"
+)
+
+// maxRateLimitKeys - после стольких ключей лимитер при следующем запросе убирает истекшие окна
+const maxRateLimitKeys = 10000
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// rateLimiter пропускает не больше limit запросов с одного ключа за window
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[string]*rateWindow
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, windows: make(map[string]*rateWindow)}
+}
+
+func (l *rateLimiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.windows) >= maxRateLimitKeys {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.window {
+				delete(l.windows, k)
+			}
+		}
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	if w.count >= l.limit {
+		return false
+	}
+	w.count++
+	return true
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	type call struct {
+		key  string
+		at   time.Duration
+		want bool
+	}
+	tests := []struct {
+		name  string
+		calls []call
+	}{
+		{"limit per key", []call{
+			{"addr:a", 0, true},
+			{"addr:a", time.Second, true},
+			{"addr:a", 2 * time.Second, true},
+			{"addr:a", 3 * time.Second, false},
+		}},
+		{"keys are independent", []call{
+			{"addr:a", 0, true},
+			{"addr:a", 0, true},
+			{"addr:a", 0, true},
+			{"addr:b", 0, true},
+			{"session:a", 0, true},
+			{"addr:a", 0, false},
+		}},
+		{"window resets", []call{
+			{"addr:a", 0, true},
+			{"addr:a", 0, true},
+			{"addr:a", 0, true},
+			{"addr:a", 59 * time.Second, false},
+			{"addr:a", time.Minute, true},
+			{"addr:a", time.Minute, true},
+		}},
+		{"rejected requests do not extend the window", []call{
+			{"addr:a", 0, true},
+			{"addr:a", 0, true},
+			{"addr:a", 0, true},
+			{"addr:a", 30 * time.Second, false},
+			{"addr:a", 50 * time.Second, false},
+			{"addr:a", 60 * time.Second, true},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newRateLimiter(3, time.Minute)
+			for i, c := range tt.calls {
+				if got := limiter.allow(c.key, start.Add(c.at)); got != c.want {
+					t.Fatalf("call %d: allow(%q, +%s) = %v, want %v", i, c.key, c.at, got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterDropsExpiredWindows(t *testing.T) {
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(1, time.Minute)
+	for i := 0; i < maxRateLimitKeys; i++ {
+		limiter.allow(fmt.Sprintf("addr:%d", i), start)
+	}
+	limiter.allow("addr:fresh", start.Add(30*time.Second))
+	if got := len(limiter.windows); got != maxRateLimitKeys+1 {
+		t.Fatalf("windows = %d before expiry, want %d", got, maxRateLimitKeys+1)
+	}
+
+	limiter.allow("addr:late", start.Add(time.Minute))
+	if got := len(limiter.windows); got != 2 {
+		t.Errorf("windows = %d after expiry, want 2", got)
+	}
+	if limiter.allow("addr:fresh", start.Add(time.Minute)) {
+		t.Error("live window was dropped with expired ones")
+	}
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"searchservice/internal/models"
+	"searchservice/internal/service"
+)
+
+const (
+	defaultSuggestSize = 5
+	maxSuggestSize     = 10
+	maxPrefixLen       = 100
+)
+
+type SuggestHandler struct {
+	service *service.ProductService
+}
+
+func NewSuggestHandler(svc *service.ProductService) *SuggestHandler {
+	return &SuggestHandler{service: svc}
+}
+
+// ServeHTTP обрабатывает GET /suggest?prefix=…&size=…
+//
+// size - сколько подсказок каждого вида вернуть: названий товаров, тегов и популярных запросов.
+func (h *SuggestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params, err := parseSuggestParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	suggestions, err := h.service.Suggest(params)
+	if err != nil {
+		http.Error(w, "Internal server error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+func parseSuggestParams(r *http.Request) (models.SuggestParams, error) {
+	query := r.URL.Query()
+	params := models.SuggestParams{
+		Prefix: strings.TrimSpace(query.Get("prefix")),
+		Size:   defaultSuggestSize,
+	}
+
+	if params.Prefix == "" {
+		return params, fmt.Errorf("Query parameter 'prefix' is required")
+	}
+	if utf8.RuneCountInString(params.Prefix) > maxPrefixLen {
+		return params, fmt.Errorf("Query parameter 'prefix' must not exceed %d characters", maxPrefixLen)
+	}
+
+	if value := query.Get("size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 || size > maxSuggestSize {
+			return params, fmt.Errorf("Query parameter 'size' must be between 1 and %d", maxSuggestSize)
+		}
+		params.Size = size
+	}
+
+	return params, nil
+}
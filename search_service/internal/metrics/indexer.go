@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// IndexerMetrics - пропускная способность индексатора: сколько сообщений обработано,
+// какими пачками и как долго пачка уходит в elasticsearch, и насколько он отстает от топика
+type IndexerMetrics struct {
+	messages      *prometheus.CounterVec
+	retries       prometheus.Counter
+	batchSize     prometheus.Histogram
+	flushDuration prometheus.Histogram
+	lag           *prometheus.GaugeVec
+	member        prometheus.Gauge
+}
+
+func NewIndexerMetrics() *IndexerMetrics {
+	var im = &IndexerMetrics{
+		messages: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "search_service_indexer_messages_total",
+				Help: "Total number of Kafka messages handled by the indexer",
+			},
+			[]string{"result"},
+		),
+		retries: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "search_service_indexer_retries_total",
+				Help: "Total number of repeated attempts to index a batch",
+			},
+		),
+		batchSize: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "search_service_indexer_batch_size",
+				Help:    "Number of messages in a flushed batch",
+				Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+			},
+		),
+		flushDuration: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "search_service_indexer_flush_duration_seconds",
+				Help:    "Time to index a batch including retries",
+				Buckets: prometheus.DefBuckets,
+			},
+		),
+		lag: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "search_service_consumer_lag",
+				Help: "Messages between the last received message and the partition high water mark",
+			},
+			[]string{"partition"},
+		),
+		member: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "search_service_consumer_group_member",
+				Help: "Whether the indexer is a member of the Kafka consumer group (1) or not (0)",
+			},
+		),
+	}
+	prometheus.MustRegister(im.messages)
+	prometheus.MustRegister(im.retries)
+	prometheus.MustRegister(im.batchSize)
+	prometheus.MustRegister(im.flushDuration)
+	prometheus.MustRegister(im.lag)
+	prometheus.MustRegister(im.member)
+
+	return im
+}
+
+// Flush учитывает пачку: indexed сообщений применены к индексу, deadLettered ушли в DLQ
+func (im *IndexerMetrics) Flush(indexed, deadLettered int, duration time.Duration) {
+	im.messages.WithLabelValues("indexed").Add(float64(indexed))
+	im.messages.WithLabelValues("dead_lettered").Add(float64(deadLettered))
+	im.batchSize.Observe(float64(indexed + deadLettered))
+	im.flushDuration.Observe(duration.Seconds())
+}
+
+func (im *IndexerMetrics) Retry() {
+	im.retries.Inc()
+}
+
+// Lag - сколько сообщений партиции еще не прочитано
+func (im *IndexerMetrics) Lag(partition int32, lag int64) {
+	im.lag.WithLabelValues(strconv.Itoa(int(partition))).Set(float64(max(lag, 0)))
+}
+
+// ResetLag убирает партицию, которую консьюмер больше не читает
+func (im *IndexerMetrics) ResetLag(partition int32) {
+	im.lag.DeleteLabelValues(strconv.Itoa(int(partition)))
+}
+
+func (im *IndexerMetrics) Member(member bool) {
+	if member {
+		im.member.Set(1)
+	} else {
+		im.member.Set(0)
+	}
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidEvent - событие нельзя применить ни с какой попытки, его сразу отправляют в DLQ
+var ErrInvalidEvent = errors.New("invalid product event")
+
+// Типы событий топика products: upsert заменяет документ товара целиком, patch меняет
+// только переданные поля, delete убирает товар из поиска
+const (
+	EventUpsert = "upsert"
+	EventPatch  = "patch"
+	EventDelete = "delete"
+)
+
+// ProductEvent - сообщение топика products от products_service. Version растет с каждым
+// изменением товара, по ней индекс отбрасывает события, пришедшие не по порядку.
+type ProductEvent struct {
+	Type       string        `json:"type"`
+	ProductID  string        `json:"product_id"`
+	Version    int64         `json:"version"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Product    *Product      `json:"product,omitempty"`
+	Patch      *ProductPatch `json:"patch,omitempty"`
+}
+
+// UnmarshalJSON разбирает событие и переносит размеры из info товара в Product.Sizes
+func (e *ProductEvent) UnmarshalJSON(data []byte) error {
+	type event ProductEvent
+	if err := json.Unmarshal(data, (*event)(e)); err != nil {
+		return err
+	}
+	if e.Product == nil {
+		return nil
+	}
+
+	var raw struct {
+		Product struct {
+			Info json.RawMessage `json:"info"`
+		} `json:"product"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	e.Product.Sizes = sizesFromRawInfo(raw.Product.Info)
+	return nil
+}
+
+// ProductPatch - поля товара, которые меняет событие patch. Непереданные поля остаются как есть.
+type ProductPatch struct {
+	Name        *string   `json:"name,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	Seller      *string   `json:"seller,omitempty"`
+	Price       *int32    `json:"price,omitempty"`
+}
+
+// Apply переносит переданные поля в документ товара
+func (p *ProductPatch) Apply(product *Product) {
+	if p.Name != nil {
+		product.Name = *p.Name
+	}
+	if p.Description != nil {
+		product.Description = *p.Description
+	}
+	if p.Tags != nil {
+		product.Tags = *p.Tags
+	}
+	if p.Seller != nil {
+		product.Seller = *p.Seller
+	}
+	if p.Price != nil {
+		product.Price = *p.Price
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Типы событий обратной связи по результатам поиска
+const (
+	FeedbackImpression = "impression"
+	FeedbackClick      = "click"
+)
+
+// Варианты ранжирования для A/B сравнения
+const (
+	RankingBaseline = "baseline"
+	RankingBoosted  = "boosted"
+)
+
+var ErrInvalidFeedback = errors.New("invalid feedback")
+
+// FeedbackEvent - показ или клик по товару на позиции Position (с 1) в выдаче по запросу Query.
+// Ranking - вариант ранжирования из ответа /search, по нему сравниваются варианты.
+type FeedbackEvent struct {
+	Query      string    `json:"query"`
+	ProductID  string    `json:"product_id"`
+	Position   int       `json:"position"`
+	Type       string    `json:"type"`
+	Session    string    `json:"session,omitempty"`
+	Ranking    string    `json:"ranking,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// FeedbackKey - счетчики товара по запросу. Пустой Query - по всем запросам.
+type FeedbackKey struct {
+	Query     string
+	ProductID string
+}
+
+type FeedbackStat struct {
+	Impressions int64
+	Clicks      int64
+}
@@ -1,9 +1,61 @@
 package models
 
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Product - документ товара в поисковом индексе. Sizes - размеры из info товара, см. SizesFromInfo.
 type Product struct {
-	ProductID   string   `json:"product_id"`
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Tags        []string `json:"tags"`
-	Seller      string   `json:"seller"`
+	ProductID   string    `json:"product_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	Sizes       []string  `json:"sizes,omitempty"`
+	Seller      string    `json:"seller"`
+	Price       int32     `json:"price"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SizesFromInfo - размеры товара из его характеристик info: список sizes из строк или чисел.
+// Остальной info поиск не индексирует. info без sizes или с sizes другого вида - товар без размеров.
+func SizesFromInfo(info map[string]any) []string {
+	values, ok := info["sizes"].([]any)
+	if !ok {
+		return nil
+	}
+
+	sizes := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		var size string
+		switch v := value.(type) {
+		case string:
+			size = NormalizeSize(v)
+		case float64:
+			size = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		if size != "" && !seen[size] {
+			seen[size] = true
+			sizes = append(sizes, size)
+		}
+	}
+	return sizes
+}
+
+// NormalizeSize приводит размер к виду, в котором он лежит в индексе: "XL " и "xl" - один размер
+func NormalizeSize(size string) string {
+	return strings.ToLower(strings.TrimSpace(size))
+}
+
+// sizesFromRawInfo - SizesFromInfo для info в JSON, как его присылает products_service
+func sizesFromRawInfo(data json.RawMessage) []string {
+	var info map[string]any
+	if len(data) == 0 || json.Unmarshal(data, &info) != nil {
+		return nil
+	}
+	return SizesFromInfo(info)
 }
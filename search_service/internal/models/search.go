@@ -0,0 +1,66 @@
+package models
+
+// Порядок результатов поиска
+const (
+	SortRelevance = "relevance"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortNewest    = "newest"
+)
+
+// SearchParams - запрос поиска. Пустой Query - все товары, подходящие под фильтры.
+// Все Tags должны быть у товара одновременно, из Sizes (размеров товара, см. Product.Sizes)
+// достаточно одного. Size - размер страницы. SearchAfter - значения сортировки последнего
+// результата предыдущей страницы, с ним From не используется.
+type SearchParams struct {
+	Query string
+	// TranslitQuery - Query латиницей, переведенный в кириллицу. Ищется наравне с Query.
+	TranslitQuery string
+	PriceMin      *int
+	PriceMax      *int
+	Tags          []string
+	Sizes         []string
+	Seller        string
+	Sort          string
+	From          int
+	Size          int
+	SearchAfter   []any
+	// Session - идентификатор клиента, по нему выбирается вариант ранжирования
+	Session string
+}
+
+type SearchHit struct {
+	ProductID string              `json:"product_id"`
+	Score     float64             `json:"score"`
+	Highlight map[string][]string `json:"highlight,omitempty"`
+	// Sort - значения сортировки, по ним строится search_after следующей страницы
+	Sort []any `json:"-"`
+}
+
+type TagFacet struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// PriceFacet - число товаров с ценой в [From, To). Пустая граница - без ограничения.
+type PriceFacet struct {
+	From  *float64 `json:"from,omitempty"`
+	To    *float64 `json:"to,omitempty"`
+	Count int64    `json:"count"`
+}
+
+type Facets struct {
+	Tags  []TagFacet   `json:"tags"`
+	Price []PriceFacet `json:"price"`
+}
+
+type SearchResult struct {
+	Total  int64       `json:"total"`
+	Hits   []SearchHit `json:"hits"`
+	Facets Facets      `json:"facets"`
+	// Ranking - вариант ранжирования, которым упорядочена страница
+	Ranking string `json:"ranking"`
+	// LastSort - значения сортировки последнего результата в порядке индекса. После
+	// переранжирования страницы он может оказаться не последним в Hits.
+	LastSort []any `json:"-"`
+}
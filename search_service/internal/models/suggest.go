@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// MaxQuerySessions - сколько разных сессий запроса запоминается. Порогу подсказок больше не нужно.
+const MaxQuerySessions = 50
+
+// SuggestParams - подсказки к началу запроса Prefix. TranslitPrefix - Prefix латиницей,
+// переведенный в кириллицу, по нему тоже ищутся названия товаров и теги. Популярный запрос
+// подсказывается, только если его искали хотя бы MinSessions разных сессий.
+type SuggestParams struct {
+	Prefix         string
+	TranslitPrefix string
+	Size           int
+	MinSessions    int
+}
+
+type ProductSuggestion struct {
+	ProductID string `json:"product_id"`
+	Name      string `json:"name"`
+}
+
+// Suggestions - названия товаров, теги и популярные запросы, начинающиеся с префикса
+type Suggestions struct {
+	Products []ProductSuggestion `json:"products"`
+	Tags     []string            `json:"tags"`
+	Queries  []string            `json:"queries"`
+}
+
+// QueryStat - сколько раз искали запрос за период и сколько товаров нашлось в последний раз.
+// Sessions - хеши сессий, которые его искали, не больше MaxQuerySessions.
+type QueryStat struct {
+	Count    int64
+	Results  int64
+	LastSeen time.Time
+	Sessions []string
+}
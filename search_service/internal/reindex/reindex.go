@@ -0,0 +1,396 @@
+// Package reindex заливает товары в новую версию индекса и атомарно переключает на нее алиас.
+// Пока идет заливка, индексатор продолжает писать в текущую версию; события, пришедшие за это
+// время, новая версия догоняет из kafka уже после переключения. Внешние версии документов
+// делают повторную обработку событий безопасной.
+package reindex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"searchservice/internal/catalog"
+	"searchservice/internal/models"
+	"searchservice/internal/repository"
+	"searchservice/internal/service"
+
+	"github.com/IBM/sarama"
+)
+
+// Источники заливки
+const (
+	SourceKafka   = "kafka"
+	SourceCatalog = "catalog"
+)
+
+const (
+	maxAttempts      = 5
+	retryBackoff     = time.Second
+	progressInterval = 5 * time.Second
+)
+
+var (
+	ErrNoPreviousVersion = errors.New("no previous index version to roll back to")
+	// ErrTopicTruncated - kafka уже удалила начало топика по retention, заливка из него потеряла бы товары
+	ErrTopicTruncated = errors.New("topic does not start at offset 0, use -source=catalog")
+	// ErrTooFewDocuments - в новой версии заметно меньше товаров, чем в текущей
+	ErrTooFewDocuments = errors.New("new index has too few documents")
+)
+
+type Reindexer struct {
+	es        *repository.ElasticRepository
+	topic     *repository.TopicReader
+	catalog   *catalog.Client
+	batchSize int
+	minRatio  float64
+}
+
+// New - catalog нужен только для источника SourceCatalog. Алиас переключается, только если
+// в новой версии не меньше minRatio от числа товаров текущей; 0 - без проверки.
+func New(es *repository.ElasticRepository, topic *repository.TopicReader, catalog *catalog.Client, batchSize int, minRatio float64) *Reindexer {
+	return &Reindexer{es: es, topic: topic, catalog: catalog, batchSize: batchSize, minRatio: minRatio}
+}
+
+// Run создает следующую версию индекса, заливает ее из source, переключает алиас и догоняет
+// события, пришедшие во время заливки. Предыдущая версия остается для Rollback, если не deleteOld.
+// Индекс products без версии перед переключением копируется в repository.LegacyIndex.
+func (r *Reindexer) Run(ctx context.Context, source string, deleteOld bool) error {
+	current, err := r.es.AliasTarget()
+	if err != nil {
+		return err
+	}
+	versions, err := r.es.Versions()
+	if err != nil {
+		return err
+	}
+	next := repository.VersionedIndex(1)
+	if len(versions) > 0 {
+		next = repository.VersionedIndex(versions[len(versions)-1].Version + 1)
+	}
+	if current == "" {
+		// индекс products без версии, оставшийся от старых запусков
+		current = repository.AliasName
+	}
+
+	if source == SourceKafka {
+		if err := r.checkTopicComplete(); err != nil {
+			return err
+		}
+	}
+
+	// события после этих offsets заливка может не увидеть, их догонит catchUp
+	started, err := r.topic.NewestOffsets()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("reindex: creating %s from %s, current index %s", next, source, current)
+	if err := r.es.CreateIndex(next); err != nil {
+		return err
+	}
+	if err := r.es.SetBulkLoad(next, true); err != nil {
+		return err
+	}
+
+	target := service.NewProductService(r.es.WithIndex(next), nil, nil)
+	switch source {
+	case SourceKafka:
+		err = r.fromKafka(ctx, target, started)
+	case SourceCatalog:
+		err = r.fromCatalog(ctx, target)
+	default:
+		err = fmt.Errorf("unknown source %q", source)
+	}
+	if err != nil {
+		return fmt.Errorf("backfill of %s failed, index is kept for inspection: %w", next, err)
+	}
+
+	if err := r.es.SetBulkLoad(next, false); err != nil {
+		return err
+	}
+	if err := r.es.Refresh(next); err != nil {
+		return err
+	}
+	if err := r.checkCount(next, current); err != nil {
+		return err
+	}
+
+	retired, err := r.topic.NewestOffsets()
+	if err != nil {
+		return err
+	}
+	previous := current
+	if current == repository.AliasName {
+		// алиас не создать, пока есть индекс с тем же именем: сохраняем его копию для Rollback
+		if err := r.es.BlockWrites(current); err != nil {
+			return err
+		}
+		if err := r.es.CloneIndex(current, repository.LegacyIndex); err != nil {
+			return fmt.Errorf("%w; writes to %s stay blocked until it is switched or unblocked", err, current)
+		}
+		previous = repository.LegacyIndex
+		log.Printf("reindex: unversioned index %s kept as %s", current, previous)
+	}
+	if err := r.es.SwitchAlias(next, current); err != nil {
+		return err
+	}
+	log.Printf("reindex: alias %s switched from %s to %s", repository.AliasName, current, next)
+	if err := r.es.SaveRetiredOffsets(previous, retired); err != nil {
+		log.Printf("reindex: rollback to %s will not catch up events: %s", previous, err)
+	}
+
+	if err := r.catchUp(ctx, target, started); err != nil {
+		return fmt.Errorf("catch up of %s failed, run reindex again or roll back: %w", next, err)
+	}
+
+	if deleteOld {
+		if err := r.es.DeleteIndex(previous); err != nil {
+			return err
+		}
+		log.Printf("reindex: previous index %s deleted", previous)
+	}
+	return nil
+}
+
+// checkTopicComplete - заливка из kafka видит все товары, только если топик хранится с начала
+func (r *Reindexer) checkTopicComplete() error {
+	oldest, err := r.topic.OldestOffsets()
+	if err != nil {
+		return err
+	}
+	for partition, offset := range oldest {
+		if offset > 0 {
+			return fmt.Errorf("%w: partition %d starts at offset %d", ErrTopicTruncated, partition, offset)
+		}
+	}
+	return nil
+}
+
+// checkCount не дает переключить алиас на версию, в которой заметно меньше товаров, чем
+// в текущей: чаще всего это значит, что заливка прошла не полностью
+func (r *Reindexer) checkCount(next, current string) error {
+	count, err := r.es.Count(next)
+	if err != nil {
+		return err
+	}
+	currentCount, err := r.es.Count(current)
+	if err != nil {
+		return err
+	}
+	log.Printf("reindex: %s contains %d products, %s contains %d", next, count, current, currentCount)
+
+	if float64(count) < float64(currentCount)*r.minRatio {
+		return fmt.Errorf("%w: %s has %d documents, %s has %d, minimum ratio %.2f; index %s is kept for inspection",
+			ErrTooFewDocuments, next, count, current, currentCount, r.minRatio, next)
+	}
+	return nil
+}
+
+// Rollback возвращает алиас на предыдущую версию индекса и догоняет в ней события,
+// пропущенные с момента, когда она перестала быть текущей. Текущая версия остается.
+func (r *Reindexer) Rollback(ctx context.Context) error {
+	current, err := r.es.AliasTarget()
+	if err != nil {
+		return err
+	}
+	versions, err := r.es.Versions()
+	if err != nil {
+		return err
+	}
+
+	previous := ""
+	for _, v := range versions {
+		if v.Name == current {
+			break
+		}
+		previous = v.Name
+	}
+	if current == "" || previous == "" {
+		return ErrNoPreviousVersion
+	}
+
+	from, err := r.es.RetiredOffsets(previous)
+	if err != nil {
+		return err
+	}
+	retired, err := r.topic.NewestOffsets()
+	if err != nil {
+		return err
+	}
+	if err := r.es.SwitchAlias(previous, current); err != nil {
+		return err
+	}
+	log.Printf("reindex: alias %s rolled back from %s to %s", repository.AliasName, current, previous)
+	if err := r.es.SaveRetiredOffsets(current, retired); err != nil {
+		log.Printf("reindex: switching back to %s will not catch up events: %s", current, err)
+	}
+
+	return r.catchUp(ctx, service.NewProductService(r.es.WithIndex(previous), nil, nil), from)
+}
+
+func (r *Reindexer) fromKafka(ctx context.Context, target *service.ProductService, to map[int32]int64) error {
+	from, err := r.topic.OldestOffsets()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for partition, end := range to {
+		total += max(end-from[partition], 0)
+	}
+
+	p := newProgress("backfill", total)
+	defer p.stop()
+	return r.topic.Read(ctx, from, to, r.batchSize, func(messages []*sarama.ConsumerMessage) error {
+		defer p.add(len(messages))
+		return apply(ctx, target, decode(messages), p)
+	})
+}
+
+func (r *Reindexer) fromCatalog(ctx context.Context, target *service.ProductService) error {
+	page, err := r.catalog.Scan(ctx, "", r.batchSize)
+	if err != nil {
+		return err
+	}
+
+	p := newProgress("backfill", page.Total)
+	defer p.stop()
+	for {
+		if err := apply(ctx, target, page.Events, p); err != nil {
+			return err
+		}
+		p.add(len(page.Events))
+		if page.Next == "" {
+			return nil
+		}
+		if page, err = r.catalog.Scan(ctx, page.Next, r.batchSize); err != nil {
+			return err
+		}
+	}
+}
+
+// catchUp применяет события, записанные в топик после from
+func (r *Reindexer) catchUp(ctx context.Context, target *service.ProductService, from map[int32]int64) error {
+	to, err := r.topic.NewestOffsets()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for partition, end := range to {
+		total += max(end-from[partition], 0)
+	}
+
+	p := newProgress("catch up", total)
+	defer p.stop()
+	return r.topic.Read(ctx, from, to, r.batchSize, func(messages []*sarama.ConsumerMessage) error {
+		defer p.add(len(messages))
+		return apply(ctx, target, decode(messages), p)
+	})
+}
+
+// decode пропускает сообщения, которые не разбираются: они уже лежат в DLQ индексатора
+func decode(messages []*sarama.ConsumerMessage) []*models.ProductEvent {
+	events := make([]*models.ProductEvent, 0, len(messages))
+	for _, message := range messages {
+		var event models.ProductEvent
+		if err := json.Unmarshal(message.Value, &event); err != nil {
+			log.Printf("reindex: skipping message %s/%d/%d: %s", message.Topic, message.Partition, message.Offset, err)
+			continue
+		}
+		events = append(events, &event)
+	}
+	return events
+}
+
+// apply повторяет события с временными ошибками. События, которые индекс не примет никогда,
+// пропускаются и учитываются в прогрессе; временная ошибка после всех попыток прерывает заливку.
+func apply(ctx context.Context, target *service.ProductService, events []*models.ProductEvent, p *progress) error {
+	for attempt := 1; len(events) > 0; attempt++ {
+		errs := target.HandleProductEvents(events)
+
+		retry := events[:0:0]
+		var lastErr error
+		for i, err := range errs {
+			switch {
+			case err == nil:
+			case errors.Is(err, models.ErrInvalidEvent) || errors.Is(err, repository.ErrDocumentRejected):
+				log.Printf("reindex: skipping event: %s", err)
+				p.skip()
+			default:
+				retry = append(retry, events[i])
+				lastErr = err
+			}
+		}
+		if len(retry) == 0 {
+			return nil
+		}
+		if attempt >= maxAttempts {
+			return lastErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryBackoff * time.Duration(attempt)):
+		}
+		events = retry
+	}
+	return nil
+}
+
+// progress раз в progressInterval пишет в лог, сколько сообщений обработано и с какой скоростью
+type progress struct {
+	stage   string
+	total   int64
+	done    atomic.Int64
+	skipped atomic.Int64
+	started time.Time
+	ticker  *time.Ticker
+	quit    chan struct{}
+}
+
+func newProgress(stage string, total int64) *progress {
+	p := &progress{
+		stage:   stage,
+		total:   total,
+		started: time.Now(),
+		ticker:  time.NewTicker(progressInterval),
+		quit:    make(chan struct{}),
+	}
+	go func() {
+		for {
+			select {
+			case <-p.ticker.C:
+				p.report()
+			case <-p.quit:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+func (p *progress) add(n int) { p.done.Add(int64(n)) }
+func (p *progress) skip()     { p.skipped.Add(1) }
+
+func (p *progress) stop() {
+	p.ticker.Stop()
+	close(p.quit)
+	p.report()
+}
+
+func (p *progress) report() {
+	done := p.done.Load()
+	elapsed := time.Since(p.started)
+	percent := 100.0
+	if p.total > 0 {
+		percent = float64(done) * 100 / float64(p.total)
+	}
+	log.Printf("reindex: %s %d/%d (%.1f%%), %.0f/s, skipped %d, elapsed %s",
+		p.stage, done, p.total, percent, float64(done)/max(elapsed.Seconds(), 1), p.skipped.Load(), elapsed.Round(time.Second))
+}
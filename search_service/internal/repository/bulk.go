@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"searchservice/internal/models"
+)
+
+// Действия в запросе _bulk
+const (
+	BulkIndex  = "index"
+	BulkDelete = "delete"
+)
+
+// ErrDocumentRejected - elasticsearch отклонил документ (например, не подошел под маппинг),
+// повтор того же запроса не поможет
+var ErrDocumentRejected = errors.New("document rejected by elasticsearch")
+
+// BulkOperation - одна операция _bulk с внешней версией. Product нужен только для BulkIndex.
+type BulkOperation struct {
+	Action    string
+	ProductID string
+	Version   int64
+	Product   *models.Product
+}
+
+type bulkMeta struct {
+	Index       string `json:"_index"`
+	ID          string `json:"_id"`
+	Version     int64  `json:"version"`
+	VersionType string `json:"version_type"`
+}
+
+type bulkItem struct {
+	ID     string `json:"_id"`
+	Status int    `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error,omitempty"`
+}
+
+// Bulk отправляет операции одним запросом без принудительного refresh. Ошибка второго
+// результата означает, что не выполнена ни одна операция, иначе ошибки разложены по операциям
+// в том же порядке: ErrVersionConflict для устаревших версий, ErrDocumentRejected для
+// отклоненных документов, остальные ошибки временные. Удаление отсутствующего документа ошибкой
+// не считается.
+func (er *ElasticRepository) Bulk(ops []BulkOperation) ([]error, error) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, op := range ops {
+		meta := map[string]bulkMeta{op.Action: {
+			Index:       er.index,
+			ID:          op.ProductID,
+			Version:     op.Version,
+			VersionType: "external",
+		}}
+		if err := enc.Encode(meta); err != nil {
+			return nil, fmt.Errorf("error encoding bulk action for ID=%s: %w", op.ProductID, err)
+		}
+		if op.Action == BulkIndex {
+			if err := enc.Encode(op.Product); err != nil {
+				return nil, fmt.Errorf("error encoding product ID=%s: %w", op.ProductID, err)
+			}
+		}
+	}
+
+	res, err := er.client.Bulk(bytes.NewReader(body.Bytes()), er.client.Bulk.WithContext(context.Background()))
+	if err != nil {
+		return nil, fmt.Errorf("error sending bulk request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch bulk error: %s", res.String())
+	}
+
+	var resp struct {
+		Items []map[string]bulkItem `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("error parsing bulk response: %w", err)
+	}
+	if len(resp.Items) != len(ops) {
+		return nil, fmt.Errorf("bulk response has %d items for %d operations", len(resp.Items), len(ops))
+	}
+
+	errs := make([]error, len(ops))
+	for i, op := range ops {
+		errs[i] = bulkItemError(op, resp.Items[i][op.Action])
+	}
+	return errs, nil
+}
+
+func bulkItemError(op BulkOperation, item bulkItem) error {
+	switch {
+	case item.Status >= 200 && item.Status < 300:
+		return nil
+	case item.Status == 409:
+		return ErrVersionConflict
+	case item.Status == 404 && op.Action == BulkDelete:
+		// товара в поиске уже нет. Версию удаления elasticsearch помнит только index.gc_deletes
+		// (60 секунд), более поздний upsert со старой версией вернет товар в поиск. В топике события
+		// товара идут по порядку, поэтому такой upsert приходит только из DLQ, и ReplayDeadLetters
+		// сверяет его с каталогом.
+		return nil
+	}
+
+	reason := fmt.Sprintf("status %d", item.Status)
+	if item.Error != nil {
+		reason = fmt.Sprintf("%s: %s", item.Error.Type, item.Error.Reason)
+	}
+	if item.Status == 429 || item.Status >= 500 {
+		return fmt.Errorf("bulk %s ID=%s: %s", op.Action, op.ProductID, reason)
+	}
+	return fmt.Errorf("%w: bulk %s ID=%s: %s", ErrDocumentRejected, op.Action, op.ProductID, reason)
+}
@@ -0,0 +1,561 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"searchservice/internal/models"
+
+	"github.com/elastic/go-elasticsearch/v7"
+)
+
+// contractProducts - товары, на которых проверяется общее поведение реализаций SearchRepository.
+// Слова выбраны так, чтобы их не меняли морфология и синонимы: их MemoryRepository не знает.
+var contractProducts = []*models.Product{
+	{
+		ProductID: "p1", Name: "Кроссовки Nike Air", Description: "Легкие беговые кроссовки",
+		Tags: []string{"обувь", "спорт"}, Sizes: []string{"42", "43"}, Seller: "s1", Price: 7000,
+		CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		ProductID: "p2", Name: "Кроссовки Adidas Run", Description: "Кроссовки для бега",
+		Tags: []string{"обувь"}, Sizes: []string{"41"}, Seller: "s2", Price: 4500,
+		CreatedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		ProductID: "p3", Name: "Футболка Nike", Description: "Хлопковая футболка",
+		Tags: []string{"одежда", "спорт"}, Sizes: []string{"m", "l"}, Seller: "s1", Price: 1500,
+		CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		ProductID: "p4", Name: "Куртка зимняя", Description: "Теплая куртка",
+		Tags: []string{"одежда"}, Seller: "s2", Price: 12000,
+		CreatedAt: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		// удаляется после загрузки и не должен находиться
+		ProductID: "p5", Name: "Кроссовки Nike Zoom", Description: "Удаленный товар",
+		Tags: []string{"обувь"}, Seller: "s1", Price: 9000,
+		CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	},
+}
+
+type contractRepository struct {
+	name string
+	repo SearchRepository
+	// refresh делает записанное видимым для поиска: elasticsearch показывает документы
+	// после refresh индекса, MemoryRepository - сразу
+	refresh func(t *testing.T)
+}
+
+// contractRepositories - MemoryRepository и, если задан SEARCH_TEST_ES_URL, ElasticRepository
+// с товарами contractProducts. Для elasticsearch создается временный индекс, который удаляется
+// после теста. Узлу нужен файл синонимов analysis/products_synonyms.txt, как в docker-compose.
+func contractRepositories(t *testing.T) []contractRepository {
+	t.Helper()
+
+	repos := []contractRepository{{name: "memory", repo: NewMemoryRepository(), refresh: func(*testing.T) {}}}
+	loadContractProducts(t, repos[0].repo)
+
+	esURL := os.Getenv("SEARCH_TEST_ES_URL")
+	if esURL == "" {
+		t.Log("SEARCH_TEST_ES_URL is not set, checking MemoryRepository only")
+		return repos
+	}
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{esURL}})
+	if err != nil {
+		t.Fatalf("create elasticsearch client: %s", err)
+	}
+	index := fmt.Sprintf("%s_contract_%d", AliasName, time.Now().UnixNano())
+	es := &ElasticRepository{client: client, index: index}
+	if err := es.CreateIndex(index); err != nil {
+		t.Fatalf("create index: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := es.DeleteIndex(index); err != nil {
+			t.Errorf("delete index: %s", err)
+		}
+	})
+	if err := es.ensureQueryLog(); err != nil {
+		t.Fatalf("create query log: %s", err)
+	}
+	loadContractProducts(t, es)
+	refresh := func(t *testing.T) {
+		t.Helper()
+		if err := es.Refresh(index + "," + queryLogIndex); err != nil {
+			t.Fatalf("refresh index: %s", err)
+		}
+	}
+	refresh(t)
+
+	return append(repos, contractRepository{name: "elasticsearch", repo: es, refresh: refresh})
+}
+
+func loadContractProducts(t *testing.T, repo SearchRepository) {
+	t.Helper()
+
+	ops := make([]BulkOperation, 0, len(contractProducts))
+	for _, product := range contractProducts {
+		ops = append(ops, BulkOperation{Action: BulkIndex, ProductID: product.ProductID, Version: 1, Product: product})
+	}
+	for _, ops := range [][]BulkOperation{ops, {{Action: BulkDelete, ProductID: "p5", Version: 2}}} {
+		errs, err := repo.Bulk(ops)
+		if err != nil {
+			t.Fatalf("bulk: %s", err)
+		}
+		for i, err := range errs {
+			if err != nil {
+				t.Fatalf("bulk %s %s: %s", ops[i].Action, ops[i].ProductID, err)
+			}
+		}
+	}
+}
+
+func contractParams(params models.SearchParams) models.SearchParams {
+	if params.Sort == "" {
+		params.Sort = models.SortRelevance
+	}
+	if params.Size == 0 {
+		params.Size = 20
+	}
+	return params
+}
+
+func hitIDs(hits []models.SearchHit) []string {
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ProductID)
+	}
+	return ids
+}
+
+func TestSearchContract(t *testing.T) {
+	priceMin, priceMax := 2000, 8000
+
+	tests := []struct {
+		name   string
+		params models.SearchParams
+		want   []string
+		// ordered - порядок want проверяется. По релевантности реализации упорядочивают
+		// по-разному, там сравнивается только набор товаров.
+		ordered bool
+	}{
+		{name: "one word", params: models.SearchParams{Query: "кроссовки"}, want: []string{"p1", "p2"}},
+		{name: "all words must match", params: models.SearchParams{Query: "кроссовки nike"}, want: []string{"p1"}},
+		{name: "words must match in one field", params: models.SearchParams{Query: "футболка спорт"}, want: []string{}},
+		{name: "unknown word", params: models.SearchParams{Query: "кроссовки пальто"}, want: []string{}},
+		{name: "case and yo", params: models.SearchParams{Query: "ЛЁГКИЕ"}, want: []string{"p1"}},
+		{name: "typo", params: models.SearchParams{Query: "кросовки"}, want: []string{"p1", "p2"}},
+		{name: "typo in every word", params: models.SearchParams{Query: "кросовки nikr"}, want: []string{"p1"}},
+		{name: "no typo in short word", params: models.SearchParams{Query: "ar"}, want: []string{}},
+		{name: "unfinished last word of name", params: models.SearchParams{Query: "кроссовки ni"}, want: []string{"p1"}},
+		{
+			name:   "transliteration",
+			params: models.SearchParams{Query: "kurtka", TranslitQuery: "куртка"},
+			want:   []string{"p4"},
+		},
+		{
+			name:    "price range",
+			params:  models.SearchParams{PriceMin: &priceMin, PriceMax: &priceMax, Sort: models.SortPriceAsc},
+			want:    []string{"p2", "p1"},
+			ordered: true,
+		},
+		{name: "all tags", params: models.SearchParams{Tags: []string{"одежда", "спорт"}}, want: []string{"p3"}},
+		{name: "any size", params: models.SearchParams{Sizes: []string{"42", "41"}}, want: []string{"p1", "p2"}},
+		{
+			name:    "seller newest",
+			params:  models.SearchParams{Seller: "s1", Sort: models.SortNewest},
+			want:    []string{"p3", "p1"},
+			ordered: true,
+		},
+		{
+			name:    "deleted product is not found",
+			params:  models.SearchParams{Sort: models.SortPriceDesc},
+			want:    []string{"p4", "p1", "p2", "p3"},
+			ordered: true,
+		},
+		{
+			name:   "text and filters",
+			params: models.SearchParams{Query: "nike", Tags: []string{"обувь"}},
+			want:   []string{"p1"},
+		},
+	}
+
+	for _, r := range contractRepositories(t) {
+		for _, tt := range tests {
+			t.Run(r.name+"/"+tt.name, func(t *testing.T) {
+				result, err := r.repo.Search(contractParams(tt.params))
+				if err != nil {
+					t.Fatalf("Search: %s", err)
+				}
+
+				got := hitIDs(result.Hits)
+				want := tt.want
+				if !tt.ordered {
+					got, want = slices.Sorted(slices.Values(got)), slices.Sorted(slices.Values(want))
+				}
+				if !slices.Equal(got, want) {
+					t.Errorf("hits = %v, want %v", got, want)
+				}
+				if result.Total != int64(len(tt.want)) {
+					t.Errorf("total = %d, want %d", result.Total, len(tt.want))
+				}
+			})
+		}
+	}
+}
+
+func TestSearchContractFacets(t *testing.T) {
+	bound := func(v float64) *float64 { return &v }
+	want := models.Facets{
+		Tags: []models.TagFacet{{Value: "обувь", Count: 2}, {Value: "спорт", Count: 1}},
+		Price: []models.PriceFacet{
+			{To: bound(500), Count: 0},
+			{From: bound(500), To: bound(1000), Count: 0},
+			{From: bound(1000), To: bound(5000), Count: 1},
+			{From: bound(5000), To: bound(10000), Count: 1},
+			{From: bound(10000), Count: 0},
+		},
+	}
+
+	for _, r := range contractRepositories(t) {
+		t.Run(r.name, func(t *testing.T) {
+			// фасеты считаются по всем найденным товарам, а не по странице
+			result, err := r.repo.Search(contractParams(models.SearchParams{Query: "кроссовки", Size: 1}))
+			if err != nil {
+				t.Fatalf("Search: %s", err)
+			}
+			if !reflect.DeepEqual(result.Facets, want) {
+				t.Errorf("facets = %s, want %s", formatFacets(result.Facets), formatFacets(want))
+			}
+		})
+	}
+}
+
+func TestSearchContractSearchAfter(t *testing.T) {
+	for _, r := range contractRepositories(t) {
+		t.Run(r.name, func(t *testing.T) {
+			params := contractParams(models.SearchParams{Sort: models.SortPriceAsc, Size: 3})
+			first, err := r.repo.Search(params)
+			if err != nil {
+				t.Fatalf("Search: %s", err)
+			}
+			params.SearchAfter = first.Hits[len(first.Hits)-1].Sort
+			second, err := r.repo.Search(params)
+			if err != nil {
+				t.Fatalf("Search after: %s", err)
+			}
+
+			got := append(hitIDs(first.Hits), hitIDs(second.Hits)...)
+			if want := []string{"p3", "p2", "p1", "p4"}; !slices.Equal(got, want) {
+				t.Errorf("pages = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSearchContractHighlight(t *testing.T) {
+	for _, r := range contractRepositories(t) {
+		t.Run(r.name, func(t *testing.T) {
+			result, err := r.repo.Search(contractParams(models.SearchParams{Query: "кроссовки nike"}))
+			if err != nil {
+				t.Fatalf("Search: %s", err)
+			}
+			if len(result.Hits) != 1 {
+				t.Fatalf("hits = %v, want [p1]", hitIDs(result.Hits))
+			}
+
+			want := []string{"<em>Кроссовки</em> <em>Nike</em> Air"}
+			if got := result.Hits[0].Highlight["name"]; !slices.Equal(got, want) {
+				t.Errorf("name highlight = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSearchContractGetIndex(t *testing.T) {
+	for _, r := range contractRepositories(t) {
+		t.Run(r.name, func(t *testing.T) {
+			product, version, err := r.repo.GetProduct("p1")
+			if err != nil {
+				t.Fatalf("GetProduct(p1): %s", err)
+			}
+			if product.Name != "Кроссовки Nike Air" || version != 1 {
+				t.Errorf("GetProduct(p1) = %q version %d, want %q version 1", product.Name, version, "Кроссовки Nike Air")
+			}
+			for _, id := range []string{"p5", "unknown"} {
+				if _, _, err := r.repo.GetProduct(id); !errors.Is(err, ErrProductNotFound) {
+					t.Errorf("GetProduct(%s) error = %v, want ErrProductNotFound", id, err)
+				}
+			}
+
+			boots := &models.Product{
+				ProductID: "p6", Name: "Ботинки зимние", Tags: []string{"обувь"}, Seller: "s2", Price: 8000,
+				CreatedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			}
+			if err := r.repo.IndexProduct(boots, 1); err != nil {
+				t.Fatalf("IndexProduct(p6): %s", err)
+			}
+			product, version, err = r.repo.GetProduct("p6")
+			if err != nil {
+				t.Fatalf("GetProduct(p6): %s", err)
+			}
+			if product.Name != boots.Name || version != 1 {
+				t.Errorf("GetProduct(p6) = %q version %d, want %q version 1", product.Name, version, boots.Name)
+			}
+
+			r.refresh(t)
+			result, err := r.repo.Search(contractParams(models.SearchParams{Query: "ботинки"}))
+			if err != nil {
+				t.Fatalf("Search: %s", err)
+			}
+			if got := hitIDs(result.Hits); !slices.Equal(got, []string{"p6"}) {
+				t.Errorf("hits = %v, want [p6]", got)
+			}
+		})
+	}
+}
+
+func TestSearchContractVersions(t *testing.T) {
+	for _, r := range contractRepositories(t) {
+		t.Run(r.name, func(t *testing.T) {
+			stale := *contractProducts[0]
+			stale.Name = "Кроссовки устаревшие"
+			if err := r.repo.IndexProduct(&stale, 1); !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("IndexProduct with the same version error = %v, want ErrVersionConflict", err)
+			}
+			if product, version, err := r.repo.GetProduct("p1"); err != nil || product.Name != contractProducts[0].Name || version != 1 {
+				t.Errorf("after stale upsert GetProduct(p1) = %v version %d, %v, want the old document", product, version, err)
+			}
+
+			fresh := *contractProducts[0]
+			fresh.Name = "Кроссовки Nike Pegasus"
+			if err := r.repo.IndexProduct(&fresh, 3); err != nil {
+				t.Fatalf("IndexProduct with a newer version: %s", err)
+			}
+			if err := r.repo.IndexProduct(&stale, 2); !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("IndexProduct with an older version error = %v, want ErrVersionConflict", err)
+			}
+			if product, version, err := r.repo.GetProduct("p1"); err != nil || product.Name != fresh.Name || version != 3 {
+				t.Errorf("GetProduct(p1) = %v version %d, %v, want %q version 3", product, version, err, fresh.Name)
+			}
+
+			// p5 удален версией 2: пришедший позже upsert версии 1 не возвращает его в поиск
+			if err := r.repo.IndexProduct(contractProducts[4], 1); !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("IndexProduct of deleted p5 error = %v, want ErrVersionConflict", err)
+			}
+			errs, err := r.repo.Bulk([]BulkOperation{{Action: BulkIndex, ProductID: "p5", Version: 1, Product: contractProducts[4]}})
+			if err != nil {
+				t.Fatalf("Bulk: %s", err)
+			}
+			if !errors.Is(errs[0], ErrVersionConflict) {
+				t.Errorf("bulk index of deleted p5 error = %v, want ErrVersionConflict", errs[0])
+			}
+			if _, _, err := r.repo.GetProduct("p5"); !errors.Is(err, ErrProductNotFound) {
+				t.Errorf("GetProduct(p5) error = %v, want ErrProductNotFound", err)
+			}
+
+			r.refresh(t)
+			result, err := r.repo.Search(contractParams(models.SearchParams{Query: "zoom"}))
+			if err != nil {
+				t.Fatalf("Search: %s", err)
+			}
+			if len(result.Hits) != 0 {
+				t.Errorf("deleted product is found: %v", hitIDs(result.Hits))
+			}
+		})
+	}
+}
+
+func TestSearchContractBulkPartial(t *testing.T) {
+	boots := &models.Product{
+		ProductID: "p6", Name: "Ботинки зимние", Tags: []string{"обувь"}, Seller: "s2", Price: 8000,
+		CreatedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	renamed := *contractProducts[1]
+	renamed.Name = "Кроссовки Adidas Ultra"
+	stale := *contractProducts[0]
+	stale.Name = "Кроссовки устаревшие"
+
+	ops := []BulkOperation{
+		{Action: BulkIndex, ProductID: "p6", Version: 1, Product: boots},
+		{Action: BulkIndex, ProductID: "p1", Version: 1, Product: &stale},
+		{Action: BulkDelete, ProductID: "unknown", Version: 1},
+		{Action: BulkIndex, ProductID: "p2", Version: 3, Product: &renamed},
+		{Action: BulkDelete, ProductID: "p4", Version: 1},
+	}
+	// ошибки возвращаются на местах своих операций, остальные операции применяются
+	wantErrs := []error{nil, ErrVersionConflict, nil, nil, ErrVersionConflict}
+
+	for _, r := range contractRepositories(t) {
+		t.Run(r.name, func(t *testing.T) {
+			errs, err := r.repo.Bulk(ops)
+			if err != nil {
+				t.Fatalf("Bulk: %s", err)
+			}
+			if len(errs) != len(ops) {
+				t.Fatalf("Bulk returned %d errors for %d operations", len(errs), len(ops))
+			}
+			for i, want := range wantErrs {
+				if !errors.Is(errs[i], want) {
+					t.Errorf("op %d (%s %s) error = %v, want %v", i, ops[i].Action, ops[i].ProductID, errs[i], want)
+				}
+			}
+
+			wantDocs := []struct {
+				id      string
+				name    string
+				version int64
+			}{
+				{"p1", contractProducts[0].Name, 1},
+				{"p2", renamed.Name, 3},
+				{"p4", contractProducts[3].Name, 1},
+				{"p6", boots.Name, 1},
+			}
+			for _, want := range wantDocs {
+				product, version, err := r.repo.GetProduct(want.id)
+				if err != nil {
+					t.Errorf("GetProduct(%s): %s", want.id, err)
+					continue
+				}
+				if product.Name != want.name || version != want.version {
+					t.Errorf("GetProduct(%s) = %q version %d, want %q version %d", want.id, product.Name, version, want.name, want.version)
+				}
+			}
+			if _, _, err := r.repo.GetProduct("unknown"); !errors.Is(err, ErrProductNotFound) {
+				t.Errorf("GetProduct(unknown) error = %v, want ErrProductNotFound", err)
+			}
+		})
+	}
+}
+
+func TestSearchContractSuggest(t *testing.T) {
+	// search_queries в elasticsearch общий для всех индексов, поэтому запросы теста
+	// начинаются с уникального префикса
+	prefix := fmt.Sprintf("contract%d", time.Now().UnixNano())
+	now := time.Now()
+
+	productNames := func(products []models.ProductSuggestion) []string {
+		names := make([]string, 0, len(products))
+		for _, p := range products {
+			names = append(names, p.ProductID+":"+p.Name)
+		}
+		return slices.Sorted(slices.Values(names))
+	}
+
+	for _, r := range contractRepositories(t) {
+		t.Run(r.name, func(t *testing.T) {
+			tests := []struct {
+				name         string
+				params       models.SuggestParams
+				wantProducts []string
+				wantTags     []string
+			}{
+				{
+					name:         "name prefix",
+					params:       models.SuggestParams{Prefix: "крос", Size: 5},
+					wantProducts: []string{"p1:Кроссовки Nike Air", "p2:Кроссовки Adidas Run"},
+					wantTags:     []string{},
+				},
+				{
+					name:         "second word of name",
+					params:       models.SuggestParams{Prefix: "кроссовки ad", Size: 5},
+					wantProducts: []string{"p2:Кроссовки Adidas Run"},
+					wantTags:     []string{},
+				},
+				{
+					name:         "tag prefix",
+					params:       models.SuggestParams{Prefix: "об", Size: 5},
+					wantProducts: []string{},
+					wantTags:     []string{"обувь"},
+				},
+				{
+					name:         "transliteration",
+					params:       models.SuggestParams{Prefix: "kur", TranslitPrefix: "кур", Size: 5},
+					wantProducts: []string{"p4:Куртка зимняя"},
+					wantTags:     []string{},
+				},
+				{
+					name:         "size",
+					params:       models.SuggestParams{Prefix: "о", Size: 1},
+					wantProducts: []string{},
+					wantTags:     []string{"обувь"},
+				},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					got, err := r.repo.Suggest(tt.params)
+					if err != nil {
+						t.Fatalf("Suggest: %s", err)
+					}
+					if names := productNames(got.Products); !slices.Equal(names, tt.wantProducts) {
+						t.Errorf("products = %v, want %v", names, tt.wantProducts)
+					}
+					if !slices.Equal(got.Tags, tt.wantTags) {
+						t.Errorf("tags = %v, want %v", got.Tags, tt.wantTags)
+					}
+				})
+			}
+
+			t.Run("queries", func(t *testing.T) {
+				first := map[string]models.QueryStat{
+					prefix + " кроссовки": {Count: 5, Results: 2, LastSeen: now, Sessions: []string{"a", "b"}},
+					prefix + " кросовки":  {Count: 2, Results: 2, LastSeen: now, Sessions: []string{"a", "b", "c"}},
+					prefix + " куртка":    {Count: 9, Results: 1, LastSeen: now, Sessions: []string{"a"}},
+					prefix + " пальто":    {Count: 7, Results: 0, LastSeen: now, Sessions: []string{"a", "b"}},
+				}
+				// вторая порция добавляет к запросу "куртка" новую сессию и повтор старой
+				second := map[string]models.QueryStat{
+					prefix + " куртка": {Count: 1, Results: 1, LastSeen: now, Sessions: []string{"a", "b"}},
+				}
+				params := models.SuggestParams{Prefix: prefix, Size: 5, MinSessions: 2}
+
+				if err := r.repo.RecordQueries(first); err != nil {
+					t.Fatalf("RecordQueries: %s", err)
+				}
+				r.refresh(t)
+				got, err := r.repo.Suggest(params)
+				if err != nil {
+					t.Fatalf("Suggest: %s", err)
+				}
+				if want := []string{prefix + " кроссовки", prefix + " кросовки"}; !slices.Equal(got.Queries, want) {
+					t.Errorf("queries = %v, want %v", got.Queries, want)
+				}
+
+				if err := r.repo.RecordQueries(second); err != nil {
+					t.Fatalf("RecordQueries: %s", err)
+				}
+				r.refresh(t)
+				got, err = r.repo.Suggest(params)
+				if err != nil {
+					t.Fatalf("Suggest: %s", err)
+				}
+				want := []string{prefix + " куртка", prefix + " кроссовки", prefix + " кросовки"}
+				if !slices.Equal(got.Queries, want) {
+					t.Errorf("queries after merge = %v, want %v", got.Queries, want)
+				}
+			})
+		})
+	}
+}
+
+func formatFacets(facets models.Facets) string {
+	s := fmt.Sprintf("tags %v, price", facets.Tags)
+	for _, p := range facets.Price {
+		from, to := "-", "-"
+		if p.From != nil {
+			from = fmt.Sprint(*p.From)
+		}
+		if p.To != nil {
+			to = fmt.Sprint(*p.To)
+		}
+		s += fmt.Sprintf(" [%s,%s):%d", from, to, p.Count)
+	}
+	return s
+}
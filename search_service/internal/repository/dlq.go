@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"searchservice/internal/models"
+
+	"github.com/IBM/sarama"
+)
+
+// Заголовки сообщения DLQ. Значение сообщения - исходный payload без изменений.
+const (
+	headerError           = "dlq-error"
+	headerAttempts        = "dlq-attempts"
+	headerFailedAt        = "dlq-failed-at"
+	headerSourceTopic     = "dlq-source-topic"
+	headerSourcePartition = "dlq-source-partition"
+	headerSourceOffset    = "dlq-source-offset"
+	headerReplays         = "dlq-replays"
+)
+
+const replayTimeout = time.Minute
+
+var (
+	ErrReplayInProgress = errors.New("dlq replay already in progress")
+	ErrReplayNoCatalog  = errors.New("dlq replay needs products_service to check products against")
+)
+
+// Catalog - источник истины о товарах, с ним повтор DLQ сверяет версии товаров
+type Catalog interface {
+	Version(ctx context.Context, id string) (version int64, found bool, err error)
+}
+
+// DeadLetterQueue - топик сообщений, которые индексатор не смог обработать
+type DeadLetterQueue struct {
+	broker   string
+	topic    string
+	producer sarama.SyncProducer
+}
+
+func NewDeadLetterQueue(broker, topic string) (*DeadLetterQueue, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer([]string{broker}, config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating dlq producer: %w", err)
+	}
+
+	return &DeadLetterQueue{broker: broker, topic: topic, producer: producer}, nil
+}
+
+func (q *DeadLetterQueue) Close() error {
+	return q.producer.Close()
+}
+
+// Send кладет сообщение в DLQ с причиной ошибки. Для сообщения, которое уже пришло из DLQ,
+// сохраняется исходный топик и offset, а счетчик повторов увеличивается.
+func (q *DeadLetterQueue) Send(message *sarama.ConsumerMessage, cause error, attempts int) error {
+	headers := map[string]string{}
+	for _, h := range message.Headers {
+		headers[string(h.Key)] = string(h.Value)
+	}
+	if _, ok := headers[headerSourceTopic]; !ok {
+		headers[headerSourceTopic] = message.Topic
+		headers[headerSourcePartition] = strconv.Itoa(int(message.Partition))
+		headers[headerSourceOffset] = strconv.FormatInt(message.Offset, 10)
+	}
+	if message.Topic == q.topic {
+		replays, _ := strconv.Atoi(headers[headerReplays])
+		headers[headerReplays] = strconv.Itoa(replays + 1)
+	}
+	headers[headerError] = cause.Error()
+	headers[headerAttempts] = strconv.Itoa(attempts)
+	headers[headerFailedAt] = time.Now().UTC().Format(time.RFC3339)
+
+	msg := &sarama.ProducerMessage{
+		Topic: q.topic,
+		Value: sarama.ByteEncoder(message.Value),
+	}
+	if message.Key != nil {
+		msg.Key = sarama.ByteEncoder(message.Key)
+	}
+	for key, value := range headers {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+
+	if _, _, err := q.producer.SendMessage(msg); err != nil {
+		return fmt.Errorf("error sending message to dlq: %w", err)
+	}
+	return nil
+}
+
+// ReplayResult - итог повторной обработки DLQ. Skipped - устаревшие сообщения, которые
+// отброшены после сверки с каталогом.
+type ReplayResult struct {
+	Replayed int64 `json:"replayed"`
+	Failed   int64 `json:"failed"`
+	Skipped  int64 `json:"skipped"`
+}
+
+// ReplayDeadLetters обрабатывает не больше limit сообщений, которые были в DLQ на момент вызова.
+// Сообщения снова проходят повторы, а не обработанные возвращаются в конец DLQ. Upsert и patch
+// перед повтором сверяются с каталогом (см. stale), поэтому без каталога повтор не запускается.
+func (kc *KafkaConsumer) ReplayDeadLetters(ctx context.Context, limit int) (ReplayResult, error) {
+	if kc.catalog == nil {
+		return ReplayResult{}, ErrReplayNoCatalog
+	}
+	if !kc.replayMu.TryLock() {
+		return ReplayResult{}, ErrReplayInProgress
+	}
+	defer kc.replayMu.Unlock()
+
+	config := sarama.NewConfig()
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	consumerGroup, err := sarama.NewConsumerGroup([]string{kc.broker}, kc.groupID+"-dlq-replay", config)
+	if err != nil {
+		return ReplayResult{}, err
+	}
+	defer consumerGroup.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, replayTimeout)
+	defer cancel()
+
+	handler := &replayHandler{consumer: kc, remaining: int64(limit), cancel: cancel}
+	if err := consumerGroup.Consume(ctx, []string{kc.dlq.topic}, handler); err != nil && ctx.Err() == nil {
+		return handler.result(), err
+	}
+
+	result := handler.result()
+	log.Printf("DLQ replay finished: replayed=%d failed=%d skipped=%d", result.Replayed, result.Failed, result.Skipped)
+	return result, nil
+}
+
+// replayHandler завершает сессию, когда все партиции дочитаны до конца, известного при
+// старте, или исчерпан лимит
+type replayHandler struct {
+	consumer  *KafkaConsumer
+	remaining int64
+	replayed  atomic.Int64
+	failed    atomic.Int64
+	skipped   atomic.Int64
+	pending   atomic.Int32
+	cancel    context.CancelFunc
+}
+
+func (h *replayHandler) result() ReplayResult {
+	return ReplayResult{Replayed: h.replayed.Load(), Failed: h.failed.Load(), Skipped: h.skipped.Load()}
+}
+
+func (h *replayHandler) Setup(session sarama.ConsumerGroupSession) error {
+	claims := 0
+	for _, partitions := range session.Claims() {
+		claims += len(partitions)
+	}
+	h.pending.Store(int32(claims))
+	return nil
+}
+
+func (h *replayHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h *replayHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	defer func() {
+		if h.pending.Add(-1) == 0 {
+			h.cancel()
+		}
+	}()
+
+	end := claim.HighWaterMarkOffset()
+	if end <= 0 || claim.InitialOffset() >= end {
+		return nil
+	}
+
+	for message := range claim.Messages() {
+		if atomic.AddInt64(&h.remaining, -1) < 0 {
+			h.cancel()
+			return nil
+		}
+
+		stale, err := h.consumer.stale(session.Context(), message)
+		if err != nil {
+			return err
+		}
+		switch {
+		case stale:
+			h.skipped.Add(1)
+		default:
+			deadLettered, err := h.consumer.processBatch(session.Context(), []*sarama.ConsumerMessage{message})
+			if err != nil {
+				return err
+			}
+			if deadLettered > 0 {
+				h.failed.Add(1)
+			} else {
+				h.replayed.Add(1)
+			}
+		}
+		session.MarkMessage(message, "")
+
+		if message.Offset+1 >= end {
+			return nil
+		}
+	}
+	return nil
+}
+
+// stale - upsert или patch из DLQ, товар которого удален из каталога или изменен после
+// события. Пока сообщение лежало в DLQ, удаление товара могло пройти, а версию удаления
+// elasticsearch помнит только index.gc_deletes: повтор вернул бы удаленный товар в поиск.
+// Удаления и нечитаемые сообщения повторяются как есть.
+func (kc *KafkaConsumer) stale(ctx context.Context, message *sarama.ConsumerMessage) (bool, error) {
+	var event models.ProductEvent
+	if err := json.Unmarshal(message.Value, &event); err != nil || event.Type == models.EventDelete {
+		return false, nil
+	}
+
+	version, found, err := kc.catalog.Version(ctx, event.ProductID)
+	if err != nil {
+		return false, fmt.Errorf("error checking product %s in catalog: %w", event.ProductID, err)
+	}
+	if !found || version > event.Version {
+		log.Printf("Skipping stale DLQ message %s/%d/%d: product %s version %d, catalog version %d (found=%t)",
+			message.Topic, message.Partition, message.Offset, event.ProductID, event.Version, version, found)
+		return true, nil
+	}
+	return false, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -13,12 +14,17 @@ import (
 	"github.com/elastic/go-elasticsearch/v7/esapi"
 )
 
-const (
-	indexName = "products"
+var (
+	ErrProductNotFound = errors.New("product not found in index")
+	// ErrVersionConflict - в индексе уже есть версия товара не меньше переданной
+	ErrVersionConflict = errors.New("product version conflict")
 )
 
+// ElasticRepository пишет и ищет в индексе index. Сервис работает с алиасом AliasName,
+// команда переиндексации - с конкретной версией индекса (см. WithIndex).
 type ElasticRepository struct {
 	client *elasticsearch.Client
+	index  string
 }
 
 // NewElasticRepository создаёт новый экземпляр репозитория Elasticsearch
@@ -31,142 +37,87 @@ func NewElasticRepository(esURL string) (*ElasticRepository, error) {
 		return nil, fmt.Errorf("error creating Elasticsearch client: %w", err)
 	}
 
-	er := &ElasticRepository{client: cli}
+	er := &ElasticRepository{client: cli, index: AliasName}
 	if err := er.ensureIndex(); err != nil {
 		return nil, fmt.Errorf("failed to ensure index: %w", err)
 	}
-	return er, nil
-}
-
-func (er *ElasticRepository) ensureIndex() error {
-	existsRes, err := er.client.Indices.Exists([]string{indexName})
-	if err != nil {
-		return fmt.Errorf("error checking index existence: %w", err)
+	if err := er.ensureQueryLog(); err != nil {
+		return nil, fmt.Errorf("failed to ensure query log index: %w", err)
 	}
-	defer existsRes.Body.Close()
-
-	if existsRes.StatusCode == 200 {
-		log.Println("Index 'products' already exists")
-		return nil
-	}
-
-	mapping := `
-    {
-      "mappings": {
-        "properties": {
-          "name":        { "type": "text" },
-          "description": { "type": "text" },
-          "tags":        { "type": "text" },
-          "seller":      { "type": "keyword" }
-        }
-      }
-    }`
-
-	createRes, err := er.client.Indices.Create(
-		indexName,
-		er.client.Indices.Create.WithBody(strings.NewReader(mapping)),
-	)
-	if err != nil {
-		return fmt.Errorf("error creating index: %w", err)
-	}
-	defer createRes.Body.Close()
-
-	if createRes.IsError() {
-		return fmt.Errorf("elasticsearch error on index create: %s", createRes.String())
+	if err := er.ensureFeedback(); err != nil {
+		return nil, fmt.Errorf("failed to ensure feedback index: %w", err)
 	}
+	return er, nil
+}
 
-	log.Println("Index 'products' created")
-	return nil
+// WithIndex возвращает репозиторий того же клиента, который работает с индексом index
+func (er *ElasticRepository) WithIndex(index string) *ElasticRepository {
+	return &ElasticRepository{client: er.client, index: index}
 }
 
-func (er *ElasticRepository) IndexProduct(product *models.Product) error {
+// IndexProduct сохраняет документ товара с внешней версией version. Если в индексе уже
+// есть версия не меньше, документ не меняется и возвращается ErrVersionConflict.
+func (er *ElasticRepository) IndexProduct(product *models.Product, version int64) error {
 	data, err := json.Marshal(product)
 	if err != nil {
 		return fmt.Errorf("error marshaling product %#v: %w", product, err)
 	}
+	v := int(version)
 	req := esapi.IndexRequest{
-		Index:      indexName,
-		DocumentID: product.ProductID,
-		Body:       strings.NewReader(string(data)),
-		Refresh:    "true",
+		Index:       er.index,
+		DocumentID:  product.ProductID,
+		Body:        strings.NewReader(string(data)),
+		Version:     &v,
+		VersionType: "external",
 	}
 	res, err := req.Do(context.Background(), er.client)
 	if err != nil {
 		return fmt.Errorf("error indexing document ID=%s: %w", product.ProductID, err)
 	}
 	defer res.Body.Close()
+	if res.StatusCode == 409 {
+		return ErrVersionConflict
+	}
 	if res.IsError() {
 		return fmt.Errorf("elasticsearch error for ID=%s: %s", product.ProductID, res.String())
 	}
 
-	log.Printf("Indexed product ID=%s\n", product.ProductID)
+	log.Printf("Indexed product ID=%s version=%d\n", product.ProductID, version)
 	return nil
 }
 
-func (er *ElasticRepository) SearchProductIDs(q string) ([]string, error) {
-	query := map[string]interface{}{
-		"query": map[string]interface{}{
-			"bool": map[string]interface{}{
-				"should": []interface{}{
-					map[string]interface{}{
-						"match_phrase_prefix": map[string]interface{}{"name": q},
-					},
-					map[string]interface{}{
-						"match_phrase_prefix": map[string]interface{}{"description": q},
-					},
-					map[string]interface{}{
-						"match_phrase_prefix": map[string]interface{}{"tags": q},
-					},
-					map[string]interface{}{
-						"wildcard": map[string]interface{}{
-							"seller": fmt.Sprintf("*%s*", q),
-						},
-					},
-				},
-			},
-		},
-		"_source": false, // возвращаем только _id
-	}
-
-	var buf strings.Builder
-	if err := json.NewEncoder(&buf).Encode(query); err != nil {
-		return nil, fmt.Errorf("error encoding search query: %w", err)
-	}
-
-	res, err := er.client.Search(
-		er.client.Search.WithContext(context.Background()),
-		er.client.Search.WithIndex(indexName),
-		er.client.Search.WithBody(strings.NewReader(buf.String())),
-		er.client.Search.WithTrackTotalHits(true),
-		er.client.Search.WithSize(100),
-	)
+// GetProduct возвращает документ товара и его версию в индексе
+func (er *ElasticRepository) GetProduct(productID string) (*models.Product, int64, error) {
+	res, err := er.client.Get(er.index, productID, er.client.Get.WithContext(context.Background()))
 	if err != nil {
-		return nil, fmt.Errorf("error getting response from Elasticsearch: %w", err)
+		return nil, 0, fmt.Errorf("error getting document ID=%s: %w", productID, err)
 	}
 	defer res.Body.Close()
-
+	if res.StatusCode == 404 {
+		return nil, 0, ErrProductNotFound
+	}
 	if res.IsError() {
-		return nil, fmt.Errorf("elasticsearch returned error: %s", res.String())
+		return nil, 0, fmt.Errorf("elasticsearch error for ID=%s: %s", productID, res.String())
 	}
 
-	// Парсим ответ
-	type hit struct {
-		ID string `json:"_id"`
-	}
-	type hitsWrapper struct {
-		Hits []hit `json:"hits"`
+	var doc struct {
+		Version int64          `json:"_version"`
+		Source  models.Product `json:"_source"`
 	}
-	type respWrapper struct {
-		Hits hitsWrapper `json:"hits"`
-	}
-	var sr respWrapper
-	if err := json.NewDecoder(res.Body).Decode(&sr); err != nil {
-		return nil, fmt.Errorf("error parsing search response: %w", err)
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return nil, 0, fmt.Errorf("error parsing document ID=%s: %w", productID, err)
 	}
+	return &doc.Source, doc.Version, nil
+}
 
-	resultIDs := make([]string, 0, len(sr.Hits.Hits))
-	for _, h := range sr.Hits.Hits {
-		resultIDs = append(resultIDs, h.ID)
+func (er *ElasticRepository) Ping(ctx context.Context) error {
+	res, err := er.client.Ping(er.client.Ping.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("error pinging Elasticsearch: %w", err)
 	}
-	return resultIDs, nil
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch ping error: %s", res.String())
+	}
+	return nil
 }
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"searchservice/internal/models"
+)
+
+// feedbackIndex - показы и клики товаров по запросам. id документа - product_id:query,
+// счетчики товара по всем запросам лежат в документе с пустым query.
+const feedbackIndex = "search_feedback"
+
+const feedbackMapping = `
+{
+  "mappings": {
+    "properties": {
+      "query":       { "type": "keyword" },
+      "product_id":  { "type": "keyword" },
+      "impressions": { "type": "long" },
+      "clicks":      { "type": "long" }
+    }
+  }
+}`
+
+const feedbackScript = `ctx._source.impressions += params.impressions; ctx._source.clicks += params.clicks;`
+
+func feedbackID(key models.FeedbackKey) string {
+	return key.ProductID + ":" + key.Query
+}
+
+func (er *ElasticRepository) ensureFeedback() error {
+	res, err := er.client.Indices.Exists([]string{feedbackIndex})
+	if err != nil {
+		return fmt.Errorf("error checking index existence: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 200 {
+		return nil
+	}
+
+	createRes, err := er.client.Indices.Create(
+		feedbackIndex,
+		er.client.Indices.Create.WithContext(context.Background()),
+		er.client.Indices.Create.WithBody(strings.NewReader(feedbackMapping)),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating index %s: %w", feedbackIndex, err)
+	}
+	defer createRes.Body.Close()
+	if createRes.IsError() && !strings.Contains(createRes.String(), "resource_already_exists_exception") {
+		return fmt.Errorf("elasticsearch error on index %s create: %s", feedbackIndex, createRes.String())
+	}
+
+	log.Printf("Index '%s' created", feedbackIndex)
+	return nil
+}
+
+// RecordFeedback прибавляет накопленные счетчики одним запросом _bulk
+func (er *ElasticRepository) RecordFeedback(stats map[models.FeedbackKey]models.FeedbackStat) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for key, stat := range stats {
+		action := map[string]any{"update": map[string]any{
+			"_index":            feedbackIndex,
+			"_id":               feedbackID(key),
+			"retry_on_conflict": 3,
+		}}
+		doc := map[string]any{
+			"script": map[string]any{
+				"source": feedbackScript,
+				"params": map[string]any{"impressions": stat.Impressions, "clicks": stat.Clicks},
+			},
+			"upsert": map[string]any{
+				"query":       key.Query,
+				"product_id":  key.ProductID,
+				"impressions": stat.Impressions,
+				"clicks":      stat.Clicks,
+			},
+		}
+		if err := enc.Encode(action); err != nil {
+			return fmt.Errorf("error encoding feedback action: %w", err)
+		}
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("error encoding feedback update: %w", err)
+		}
+	}
+
+	res, err := er.client.Bulk(bytes.NewReader(body.Bytes()), er.client.Bulk.WithContext(context.Background()))
+	if err != nil {
+		return fmt.Errorf("error sending feedback: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error on feedback: %s", res.String())
+	}
+
+	var resp struct {
+		Errors bool `json:"errors"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return fmt.Errorf("error parsing feedback response: %w", err)
+	}
+	if resp.Errors {
+		return fmt.Errorf("elasticsearch rejected some feedback updates")
+	}
+	return nil
+}
+
+// FeedbackStats читает счетчики одним запросом _mget. Товаров без показов в ответе нет.
+func (er *ElasticRepository) FeedbackStats(query string, productIDs []string) (map[models.FeedbackKey]models.FeedbackStat, error) {
+	ids := make([]string, 0, 2*len(productIDs))
+	for _, id := range productIDs {
+		ids = append(ids,
+			feedbackID(models.FeedbackKey{Query: query, ProductID: id}),
+			feedbackID(models.FeedbackKey{ProductID: id}),
+		)
+	}
+	body, err := json.Marshal(map[string]any{"ids": ids})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding feedback ids: %w", err)
+	}
+
+	res, err := er.client.Mget(
+		bytes.NewReader(body),
+		er.client.Mget.WithContext(context.Background()),
+		er.client.Mget.WithIndex(feedbackIndex),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting feedback: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch error on getting feedback: %s", res.String())
+	}
+
+	var resp struct {
+		Docs []struct {
+			Found  bool `json:"found"`
+			Source struct {
+				Query       string `json:"query"`
+				ProductID   string `json:"product_id"`
+				Impressions int64  `json:"impressions"`
+				Clicks      int64  `json:"clicks"`
+			} `json:"_source"`
+		} `json:"docs"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("error parsing feedback: %w", err)
+	}
+
+	stats := make(map[models.FeedbackKey]models.FeedbackStat, len(resp.Docs))
+	for _, doc := range resp.Docs {
+		if doc.Found {
+			key := models.FeedbackKey{Query: doc.Source.Query, ProductID: doc.Source.ProductID}
+			stats[key] = models.FeedbackStat{Impressions: doc.Source.Impressions, Clicks: doc.Source.Clicks}
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"searchservice/internal/models"
+
+	"github.com/IBM/sarama"
+)
+
+// FeedbackTopic - топик сырых событий показов и кликов для аналитики
+type FeedbackTopic struct {
+	topic    string
+	producer sarama.SyncProducer
+}
+
+func NewFeedbackTopic(broker, topic string) (*FeedbackTopic, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForLocal
+	config.Producer.Retry.Max = 3
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer([]string{broker}, config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating feedback producer: %w", err)
+	}
+
+	return &FeedbackTopic{topic: topic, producer: producer}, nil
+}
+
+func (t *FeedbackTopic) Close() error {
+	return t.producer.Close()
+}
+
+// Send пишет события одним запросом. Ключ - сессия, чтобы события клиента шли по порядку.
+func (t *FeedbackTopic) Send(events []models.FeedbackEvent) error {
+	messages := make([]*sarama.ProducerMessage, 0, len(events))
+	for _, event := range events {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("error encoding feedback event: %w", err)
+		}
+		msg := &sarama.ProducerMessage{Topic: t.topic, Value: sarama.ByteEncoder(value)}
+		if event.Session != "" {
+			msg.Key = sarama.StringEncoder(event.Session)
+		}
+		messages = append(messages, msg)
+	}
+
+	if err := t.producer.SendMessages(messages); err != nil {
+		return fmt.Errorf("error sending feedback events: %w", err)
+	}
+	return nil
+}
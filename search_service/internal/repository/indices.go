@@ -0,0 +1,419 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// AliasName - алиас, через который сервис пишет и ищет товары. За ним стоит одна из
+// версий индекса products_v1, products_v2...; переключает версии команда reindex.
+const AliasName = "products"
+
+// reindexLogIndex хранит offsets топика на момент, когда версия индекса перестала быть
+// текущей, - с них rollback догоняет события, пропущенные этой версией
+const reindexLogIndex = "products_reindex_log"
+
+// productsMapping - маппинг новых версий индекса. Существующие версии его изменения
+// не получают: после правки маппинга нужен reindex.
+//
+// Текст разбирается анализатором ru_en: ё приводится к е, стемминг русского и английского
+// (стеммеры не трогают чужой алфавит). При поиске к нему добавляются синонимы из файла
+// analysis/products_synonyms.txt в каталоге конфигурации elasticsearch; после правки файла
+// их перечитывает POST /products/_reload_search_analyzers без переиндексации.
+// name.exact без стемминга поднимает точные совпадения. name.suggest и tags.suggest - для
+// подсказок по началу слова в /suggest.
+const productsMapping = `
+{
+  "settings": {
+    "analysis": {
+      "char_filter": {
+        "yo_to_e": { "type": "mapping", "mappings": ["ё => е", "Ё => Е"] }
+      },
+      "filter": {
+        "russian_stop":     { "type": "stop", "stopwords": "_russian_" },
+        "russian_stemmer":  { "type": "stemmer", "language": "russian" },
+        "english_stop":     { "type": "stop", "stopwords": "_english_" },
+        "english_stemmer":  { "type": "stemmer", "language": "english" },
+        "english_possessive_stemmer": { "type": "stemmer", "language": "possessive_english" },
+        "product_synonyms": {
+          "type": "synonym_graph",
+          "synonyms_path": "analysis/products_synonyms.txt",
+          "updateable": true
+        }
+      },
+      "analyzer": {
+        "ru_en": {
+          "tokenizer": "standard",
+          "char_filter": ["yo_to_e"],
+          "filter": ["lowercase", "english_possessive_stemmer", "russian_stop", "english_stop", "russian_stemmer", "english_stemmer"]
+        },
+        "ru_en_search": {
+          "tokenizer": "standard",
+          "char_filter": ["yo_to_e"],
+          "filter": ["lowercase", "english_possessive_stemmer", "russian_stop", "english_stop", "russian_stemmer", "english_stemmer", "product_synonyms"]
+        },
+        "exact": {
+          "tokenizer": "standard",
+          "char_filter": ["yo_to_e"],
+          "filter": ["lowercase"]
+        }
+      }
+    }
+  },
+  "mappings": {
+    "properties": {
+      "product_id":  { "type": "keyword" },
+      "name": {
+        "type": "text", "analyzer": "ru_en", "search_analyzer": "ru_en_search",
+        "fields": {
+          "exact":   { "type": "text", "analyzer": "exact" },
+          "suggest": { "type": "search_as_you_type", "analyzer": "exact" }
+        }
+      },
+      "description": { "type": "text", "analyzer": "ru_en", "search_analyzer": "ru_en_search" },
+      "tags": {
+        "type": "text", "analyzer": "ru_en", "search_analyzer": "ru_en_search",
+        "fields": {
+          "keyword": { "type": "keyword" },
+          "suggest": { "type": "completion", "analyzer": "exact" }
+        }
+      },
+      "sizes":       { "type": "keyword" },
+      "seller":      { "type": "keyword" },
+      "price":       { "type": "integer" },
+      "created_at":  { "type": "date" },
+      "updated_at":  { "type": "date" }
+    }
+  }
+}`
+
+var ErrNoRetiredOffsets = errors.New("no retired offsets recorded for index")
+
+// IndexVersion - версия индекса товаров
+type IndexVersion struct {
+	Name    string
+	Version int
+}
+
+// VersionedIndex - имя индекса версии version
+func VersionedIndex(version int) string {
+	return fmt.Sprintf("%s_v%d", AliasName, version)
+}
+
+// LegacyIndex - под этим именем сохраняется индекс products без версии, когда его место
+// занимает алиас. Версия 0 - самая ранняя, на нее переключает Rollback.
+var LegacyIndex = VersionedIndex(0)
+
+// ensureIndex при первом запуске создает products_v1 и алиас на него. Индекс products без
+// версии, оставшийся от старых запусков, используется как есть до первой переиндексации.
+func (er *ElasticRepository) ensureIndex() error {
+	target, err := er.AliasTarget()
+	if err != nil {
+		return err
+	}
+	if target != "" {
+		log.Printf("Alias '%s' points to index '%s'", AliasName, target)
+		return nil
+	}
+
+	existsRes, err := er.client.Indices.Exists([]string{AliasName})
+	if err != nil {
+		return fmt.Errorf("error checking index existence: %w", err)
+	}
+	defer existsRes.Body.Close()
+	if existsRes.StatusCode == 200 {
+		log.Printf("Index '%s' is not versioned, run reindex to move it behind an alias", AliasName)
+		return nil
+	}
+
+	first := VersionedIndex(1)
+	if err := er.CreateIndex(first); err != nil {
+		return err
+	}
+	if err := er.SwitchAlias(first, ""); err != nil {
+		return err
+	}
+
+	log.Printf("Index '%s' created behind alias '%s'", first, AliasName)
+	return nil
+}
+
+// AliasTarget - индекс, на который указывает алиас, или пустая строка, если алиаса нет
+func (er *ElasticRepository) AliasTarget() (string, error) {
+	res, err := er.client.Indices.GetAlias(
+		er.client.Indices.GetAlias.WithContext(context.Background()),
+		er.client.Indices.GetAlias.WithName(AliasName),
+	)
+	if err != nil {
+		return "", fmt.Errorf("error getting alias: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return "", nil
+	}
+	if res.IsError() {
+		return "", fmt.Errorf("elasticsearch error on get alias: %s", res.String())
+	}
+
+	var indices map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return "", fmt.Errorf("error parsing alias response: %w", err)
+	}
+	if len(indices) != 1 {
+		return "", fmt.Errorf("alias '%s' points to %d indices", AliasName, len(indices))
+	}
+	for index := range indices {
+		return index, nil
+	}
+	return "", nil
+}
+
+// Versions - все версии индекса товаров по возрастанию
+func (er *ElasticRepository) Versions() ([]IndexVersion, error) {
+	res, err := er.client.Indices.GetAlias(
+		er.client.Indices.GetAlias.WithContext(context.Background()),
+		er.client.Indices.GetAlias.WithIndex(AliasName+"_v*"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing indices: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch error on listing indices: %s", res.String())
+	}
+
+	var indices map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return nil, fmt.Errorf("error parsing indices response: %w", err)
+	}
+
+	versions := make([]IndexVersion, 0, len(indices))
+	for index := range indices {
+		version, err := strconv.Atoi(strings.TrimPrefix(index, AliasName+"_v"))
+		if err != nil {
+			continue
+		}
+		versions = append(versions, IndexVersion{Name: index, Version: version})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, nil
+}
+
+func (er *ElasticRepository) CreateIndex(index string) error {
+	res, err := er.client.Indices.Create(
+		index,
+		er.client.Indices.Create.WithContext(context.Background()),
+		er.client.Indices.Create.WithBody(strings.NewReader(productsMapping)),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating index %s: %w", index, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error on index %s create: %s", index, res.String())
+	}
+	return nil
+}
+
+func (er *ElasticRepository) DeleteIndex(index string) error {
+	res, err := er.client.Indices.Delete([]string{index}, er.client.Indices.Delete.WithContext(context.Background()))
+	if err != nil {
+		return fmt.Errorf("error deleting index %s: %w", index, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error on index %s delete: %s", index, res.String())
+	}
+	return nil
+}
+
+// SetBulkLoad отключает периодический refresh индекса на время заливки и возвращает его обратно
+func (er *ElasticRepository) SetBulkLoad(index string, loading bool) error {
+	interval := "1s"
+	if loading {
+		interval = "-1"
+	}
+	body := fmt.Sprintf(`{"index":{"refresh_interval":%q}}`, interval)
+
+	res, err := er.client.Indices.PutSettings(
+		strings.NewReader(body),
+		er.client.Indices.PutSettings.WithContext(context.Background()),
+		er.client.Indices.PutSettings.WithIndex(index),
+	)
+	if err != nil {
+		return fmt.Errorf("error updating settings of %s: %w", index, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error on settings of %s: %s", index, res.String())
+	}
+	return nil
+}
+
+func (er *ElasticRepository) Refresh(index string) error {
+	res, err := er.client.Indices.Refresh(
+		er.client.Indices.Refresh.WithContext(context.Background()),
+		er.client.Indices.Refresh.WithIndex(index),
+	)
+	if err != nil {
+		return fmt.Errorf("error refreshing %s: %w", index, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error on refresh of %s: %s", index, res.String())
+	}
+	return nil
+}
+
+func (er *ElasticRepository) Count(index string) (int64, error) {
+	res, err := er.client.Count(
+		er.client.Count.WithContext(context.Background()),
+		er.client.Count.WithIndex(index),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error counting %s: %w", index, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return 0, fmt.Errorf("elasticsearch error on count of %s: %s", index, res.String())
+	}
+
+	var resp struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return 0, fmt.Errorf("error parsing count response: %w", err)
+	}
+	return resp.Count, nil
+}
+
+// BlockWrites запрещает запись в индекс, это нужно для CloneIndex
+func (er *ElasticRepository) BlockWrites(index string) error {
+	res, err := er.client.Indices.PutSettings(
+		strings.NewReader(`{"index":{"blocks.write":true}}`),
+		er.client.Indices.PutSettings.WithContext(context.Background()),
+		er.client.Indices.PutSettings.WithIndex(index),
+	)
+	if err != nil {
+		return fmt.Errorf("error blocking writes to %s: %w", index, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error on blocking writes to %s: %s", index, res.String())
+	}
+	return nil
+}
+
+// CloneIndex копирует индекс с запретом записи (см. BlockWrites) в target. В копии запись
+// снова разрешена.
+func (er *ElasticRepository) CloneIndex(index, target string) error {
+	res, err := er.client.Indices.Clone(
+		index,
+		target,
+		er.client.Indices.Clone.WithContext(context.Background()),
+		er.client.Indices.Clone.WithBody(strings.NewReader(`{"settings":{"index.blocks.write":null}}`)),
+		er.client.Indices.Clone.WithWaitForActiveShards("1"),
+	)
+	if err != nil {
+		return fmt.Errorf("error cloning %s to %s: %w", index, target, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error on cloning %s to %s: %s", index, target, res.String())
+	}
+	return nil
+}
+
+// SwitchAlias одним атомарным запросом переводит алиас с from на to. Пустой from - алиаса
+// еще нет. Если from - индекс без версии с именем алиаса, он удаляется в том же запросе,
+// иначе алиас с тем же именем не создать; сначала его нужно сохранить в LegacyIndex.
+func (er *ElasticRepository) SwitchAlias(to, from string) error {
+	actions := []map[string]any{}
+	switch from {
+	case "":
+	case AliasName:
+		actions = append(actions, map[string]any{"remove_index": map[string]any{"index": from}})
+	default:
+		actions = append(actions, map[string]any{"remove": map[string]any{"index": from, "alias": AliasName}})
+	}
+	actions = append(actions, map[string]any{"add": map[string]any{"index": to, "alias": AliasName}})
+
+	body, err := json.Marshal(map[string]any{"actions": actions})
+	if err != nil {
+		return fmt.Errorf("error encoding alias actions: %w", err)
+	}
+
+	res, err := er.client.Indices.UpdateAliases(
+		bytes.NewReader(body),
+		er.client.Indices.UpdateAliases.WithContext(context.Background()),
+	)
+	if err != nil {
+		return fmt.Errorf("error switching alias to %s: %w", to, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error on switching alias to %s: %s", to, res.String())
+	}
+	return nil
+}
+
+// SaveRetiredOffsets запоминает offsets топика, с которых index перестал получать события
+func (er *ElasticRepository) SaveRetiredOffsets(index string, offsets map[int32]int64) error {
+	body, err := json.Marshal(map[string]any{"index": index, "offsets": offsets})
+	if err != nil {
+		return fmt.Errorf("error encoding retired offsets: %w", err)
+	}
+
+	req := esapi.IndexRequest{
+		Index:      reindexLogIndex,
+		DocumentID: index,
+		Body:       bytes.NewReader(body),
+		Refresh:    "true",
+	}
+	res, err := req.Do(context.Background(), er.client)
+	if err != nil {
+		return fmt.Errorf("error saving retired offsets of %s: %w", index, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error on saving retired offsets of %s: %s", index, res.String())
+	}
+	return nil
+}
+
+// RetiredOffsets - offsets, сохраненные SaveRetiredOffsets
+func (er *ElasticRepository) RetiredOffsets(index string) (map[int32]int64, error) {
+	res, err := er.client.Get(reindexLogIndex, index, er.client.Get.WithContext(context.Background()))
+	if err != nil {
+		return nil, fmt.Errorf("error getting retired offsets of %s: %w", index, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil, ErrNoRetiredOffsets
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch error on getting retired offsets of %s: %s", index, res.String())
+	}
+
+	var doc struct {
+		Source struct {
+			Offsets map[int32]int64 `json:"offsets"`
+		} `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error parsing retired offsets of %s: %w", index, err)
+	}
+	return doc.Source.Offsets, nil
+}
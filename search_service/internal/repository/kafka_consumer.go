@@ -3,33 +3,85 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"searchservice/internal/metrics"
 	"searchservice/internal/models"
 
 	"github.com/IBM/sarama"
 )
 
+// RetryPolicy - сколько раз обработать сообщение, прежде чем отправить его в DLQ.
+// Пауза между попытками удваивается, начиная с Backoff, но не превышает MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxBackoff)
+}
+
+// drainTimeout - сколько при остановке ждать, пока проиндексируется накопленная пачка
+const drainTimeout = 10 * time.Second
+
+// BatchConfig - пачка уходит в индекс, когда в ней Size сообщений или через FlushInterval
+// после первого сообщения
+type BatchConfig struct {
+	Size          int
+	FlushInterval time.Duration
+}
+
 type KafkaConsumer struct {
 	broker  string
 	topic   string
 	groupID string
-	handler func(*models.Product)
+	handler func([]*models.ProductEvent) []error
+	dlq     *DeadLetterQueue
+	catalog Catalog
+	retry   RetryPolicy
+	batch   BatchConfig
+	metrics *metrics.IndexerMetrics
+	// replayMu не дает запустить два повтора DLQ одновременно
+	replayMu sync.Mutex
+	// member - консьюмер состоит в группе и получил назначение партиций
+	member atomic.Bool
 }
 
-func NewKafkaConsumer(broker, topic, groupID string, handler func(*models.Product)) *KafkaConsumer {
+// NewKafkaConsumer - catalog нужен только для повтора DLQ, без него (nil) повтор выключен
+func NewKafkaConsumer(broker, topic, groupID string, handler func([]*models.ProductEvent) []error, dlq *DeadLetterQueue,
+	catalog Catalog, retry RetryPolicy, batch BatchConfig, metrics *metrics.IndexerMetrics) *KafkaConsumer {
 	return &KafkaConsumer{
 		broker:  broker,
 		topic:   topic,
 		groupID: groupID,
 		handler: handler,
+		dlq:     dlq,
+		catalog: catalog,
+		retry:   retry,
+		batch:   batch,
+		metrics: metrics,
 	}
 }
 
+// Start читает топик до отмены ctx. После отмены накопленные пачки индексируются, их offsets
+// фиксируются, и Start возвращается, только когда консьюмер вышел из группы.
 func (kc *KafkaConsumer) Start(ctx context.Context) error {
 	config := sarama.NewConfig()
 	config.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
 	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	// offset фиксируется вручную после того, как пачка проиндексирована или ушла в DLQ
+	config.Consumer.Offsets.AutoCommit.Enable = false
 
 	consumerGroup, err := sarama.NewConsumerGroup([]string{kc.broker}, kc.groupID, config)
 	if err != nil {
@@ -37,7 +89,7 @@ func (kc *KafkaConsumer) Start(ctx context.Context) error {
 	}
 	defer consumerGroup.Close()
 
-	loopHandler := &consumerGroupHandler{callback: kc.handler}
+	loopHandler := &consumerGroupHandler{consumer: kc}
 
 	for {
 		if err := consumerGroup.Consume(ctx, []string{kc.topic}, loopHandler); err != nil {
@@ -49,22 +101,156 @@ func (kc *KafkaConsumer) Start(ctx context.Context) error {
 	}
 }
 
+// processBatch индексирует пачку с повторами и отправляет в DLQ то, что проиндексировать
+// не удалось. Повторяются только сообщения с временными ошибками. Ошибка означает, что пачка
+// не обработана до конца и не сохранена в DLQ, фиксировать ее offset нельзя.
+func (kc *KafkaConsumer) processBatch(ctx context.Context, messages []*sarama.ConsumerMessage) (deadLettered int, err error) {
+	started := time.Now()
+
+	pending := make([]*sarama.ConsumerMessage, 0, len(messages))
+	events := make([]*models.ProductEvent, 0, len(messages))
+	for _, message := range messages {
+		var event models.ProductEvent
+		if err := json.Unmarshal(message.Value, &event); err != nil {
+			log.Printf("Error unmarshaling Kafka message %s/%d/%d: %s", message.Topic, message.Partition, message.Offset, err)
+			if err := kc.dlq.Send(message, fmt.Errorf("%w: %v", models.ErrInvalidEvent, err), 1); err != nil {
+				return deadLettered, err
+			}
+			deadLettered++
+			continue
+		}
+		pending = append(pending, message)
+		events = append(events, &event)
+	}
+
+	for attempt := 1; len(pending) > 0; attempt++ {
+		if attempt > 1 {
+			delay := kc.retry.delay(attempt - 1)
+			log.Printf("Retrying %d messages in %s (attempt %d)", len(pending), delay, attempt)
+			kc.metrics.Retry()
+			select {
+			case <-ctx.Done():
+				return deadLettered, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		errs := kc.handler(events)
+		retryMessages := pending[:0:0]
+		retryEvents := events[:0:0]
+		for i, err := range errs {
+			if err == nil {
+				continue
+			}
+			if !permanent(err) && attempt < kc.retry.MaxAttempts {
+				retryMessages = append(retryMessages, pending[i])
+				retryEvents = append(retryEvents, events[i])
+				continue
+			}
+
+			message := pending[i]
+			log.Printf("Sending message %s/%d/%d to DLQ after %d attempts: %s",
+				message.Topic, message.Partition, message.Offset, attempt, err)
+			if err := kc.dlq.Send(message, err, attempt); err != nil {
+				return deadLettered, err
+			}
+			deadLettered++
+		}
+		pending, events = retryMessages, retryEvents
+	}
+
+	kc.metrics.Flush(len(messages)-deadLettered, deadLettered, time.Since(started))
+	return deadLettered, nil
+}
+
+// permanent - ошибка, которую повтор не исправит
+func permanent(err error) bool {
+	return errors.Is(err, models.ErrInvalidEvent) || errors.Is(err, ErrDocumentRejected)
+}
+
 type consumerGroupHandler struct {
-	callback func(*models.Product)
+	consumer *KafkaConsumer
+}
+
+// Member - консьюмер сейчас состоит в группе. Во время перебалансировки - false.
+func (kc *KafkaConsumer) Member() bool {
+	return kc.member.Load()
+}
+
+func (h *consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
+	h.consumer.member.Store(true)
+	h.consumer.metrics.Member(true)
+	return nil
 }
 
-func (h *consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
-func (h *consumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+// Cleanup убирает отставание партиций сессии: после перебалансировки их может читать другой экземпляр
+func (h *consumerGroupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	kc := h.consumer
+	kc.member.Store(false)
+	kc.metrics.Member(false)
+	for _, partition := range session.Claims()[kc.topic] {
+		kc.metrics.ResetLag(partition)
+	}
+	return nil
+}
+
+// ConsumeClaim собирает сообщения партиции в пачки. Offset фиксируется только после того,
+// как пачка целиком проиндексирована или ушла в DLQ, незафиксированные сообщения прочитаются
+// снова после перезапуска сессии. Когда сессия заканчивается (остановка или перебалансировка),
+// накопленная пачка индексируется за drainTimeout.
 func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	for message := range claim.Messages() {
-		var product models.Product
-		if err := json.Unmarshal(message.Value, &product); err != nil {
-			log.Printf("Error unmarshaling Kafka message: %s", err)
-			session.MarkMessage(message, "")
-			continue
+	kc := h.consumer
+	batch := make([]*sarama.ConsumerMessage, 0, kc.batch.Size)
+	timer := time.NewTimer(kc.batch.FlushInterval)
+	timer.Stop()
+	defer timer.Stop()
+
+	flush := func(ctx context.Context) error {
+		timer.Stop()
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := kc.processBatch(ctx, batch); err != nil {
+			return err
+		}
+		session.MarkMessage(batch[len(batch)-1], "")
+		session.Commit()
+		batch = batch[:0]
+		return nil
+	}
+
+	drain := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer cancel()
+		if err := flush(ctx); err != nil {
+			log.Printf("Failed to flush %d messages of partition %d on session end: %s", len(batch), claim.Partition(), err)
+			return err
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return drain()
+			}
+			kc.metrics.Lag(claim.Partition(), claim.HighWaterMarkOffset()-message.Offset-1)
+			batch = append(batch, message)
+			if len(batch) == 1 {
+				timer.Reset(kc.batch.FlushInterval)
+			}
+			if len(batch) >= kc.batch.Size {
+				if err := flush(session.Context()); err != nil {
+					return err
+				}
+			}
+		case <-timer.C:
+			if err := flush(session.Context()); err != nil {
+				return err
+			}
+		case <-session.Context().Done():
+			return drain()
 		}
-		h.callback(&product)
-		session.MarkMessage(message, "")
 	}
-	return nil
 }
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"searchservice/internal/metrics"
+	"searchservice/internal/models"
+
+	"github.com/IBM/sarama"
+)
+
+// метрики регистрируются в общем реестре prometheus, поэтому создаются один раз на все тесты
+var testIndexerMetrics = metrics.NewIndexerMetrics()
+
+// fakeDLQProducer запоминает offsets сообщений, отправленных в DLQ, или отвечает ошибкой err
+type fakeDLQProducer struct {
+	sarama.SyncProducer
+	err     error
+	offsets []string
+}
+
+func (p *fakeDLQProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	if p.err != nil {
+		return 0, 0, p.err
+	}
+	for _, h := range msg.Headers {
+		if string(h.Key) == headerSourceOffset {
+			p.offsets = append(p.offsets, string(h.Value))
+		}
+	}
+	return 0, 0, nil
+}
+
+// fakeSession запоминает зафиксированные offsets
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx       context.Context
+	marked    []int64
+	committed []int64
+}
+
+func (s *fakeSession) Context() context.Context { return s.ctx }
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+func (s *fakeSession) Commit() {
+	if n := len(s.marked); n > 0 {
+		s.committed = append(s.committed, s.marked[n-1])
+	}
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+func (c *fakeClaim) Partition() int32                         { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return int64(cap(c.messages)) }
+
+// productMessages - сообщения топика с upsert товаров p0, p1, ... и offsets по порядку.
+// Значение из raw заменяет сообщение с этим offset.
+func productMessages(count int, raw map[int64]string) []*sarama.ConsumerMessage {
+	messages := make([]*sarama.ConsumerMessage, 0, count)
+	for i := 0; i < count; i++ {
+		value := fmt.Sprintf(`{"type":"upsert","product_id":"p%d","version":1,"product":{"name":"Товар %d"}}`, i, i)
+		if v, ok := raw[int64(i)]; ok {
+			value = v
+		}
+		messages = append(messages, &sarama.ConsumerMessage{Topic: "products", Offset: int64(i), Value: []byte(value)})
+	}
+	return messages
+}
+
+func TestConsumeClaimCommits(t *testing.T) {
+	errUnavailable := errors.New("elasticsearch is unavailable")
+
+	tests := []struct {
+		name string
+		raw  map[int64]string
+		// failures - сколько раз подряд handler вернет ошибку для товара, -1 - всегда
+		failures  map[string]int
+		cause     error
+		dlqErr    error
+		wantErr   bool
+		wantDLQ   []string
+		wantMarks []int64
+		wantCalls int
+	}{
+		{
+			name:      "all indexed",
+			wantMarks: []int64{1, 3, 4},
+			wantCalls: 3,
+		},
+		{
+			name:      "temporary failure is retried",
+			failures:  map[string]int{"p1": 1},
+			cause:     errUnavailable,
+			wantMarks: []int64{1, 3, 4},
+			wantCalls: 4,
+		},
+		{
+			name:      "retries exhausted go to DLQ",
+			failures:  map[string]int{"p1": -1},
+			cause:     errUnavailable,
+			wantDLQ:   []string{"1"},
+			wantMarks: []int64{1, 3, 4},
+			wantCalls: 5,
+		},
+		{
+			name:      "rejected document goes to DLQ at once",
+			failures:  map[string]int{"p2": -1},
+			cause:     fmt.Errorf("%w: mapper_parsing_exception", ErrDocumentRejected),
+			wantDLQ:   []string{"2"},
+			wantMarks: []int64{1, 3, 4},
+			wantCalls: 3,
+		},
+		{
+			name:      "undecodable message goes to DLQ",
+			raw:       map[int64]string{3: `{"type":`},
+			wantDLQ:   []string{"3"},
+			wantMarks: []int64{1, 3, 4},
+			wantCalls: 3,
+		},
+		{
+			// p3 индексируется, но пачка 2-3 не сохранена целиком: ее offset не фиксируется
+			name:      "DLQ unavailable stops at the failed batch",
+			failures:  map[string]int{"p2": -1},
+			cause:     fmt.Errorf("%w: mapper_parsing_exception", ErrDocumentRejected),
+			dlqErr:    errors.New("kafka: client has run out of available brokers"),
+			wantErr:   true,
+			wantMarks: []int64{1},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := make(map[string]int)
+			for id, n := range tt.failures {
+				failures[id] = n
+			}
+			calls := 0
+			handler := func(events []*models.ProductEvent) []error {
+				calls++
+				errs := make([]error, len(events))
+				for i, event := range events {
+					if n := failures[event.ProductID]; n != 0 {
+						failures[event.ProductID] = n - 1
+						errs[i] = tt.cause
+					}
+				}
+				return errs
+			}
+
+			producer := &fakeDLQProducer{err: tt.dlqErr}
+			consumer := NewKafkaConsumer("", "products", "search", handler,
+				&DeadLetterQueue{topic: "products-dlq", producer: producer}, nil,
+				RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond},
+				BatchConfig{Size: 2, FlushInterval: time.Hour}, testIndexerMetrics)
+
+			messages := productMessages(5, tt.raw)
+			claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, len(messages))}
+			for _, message := range messages {
+				claim.messages <- message
+			}
+			close(claim.messages)
+			session := &fakeSession{ctx: context.Background()}
+
+			err := (&consumerGroupHandler{consumer: consumer}).ConsumeClaim(session, claim)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConsumeClaim error = %v, want error %v", err, tt.wantErr)
+			}
+			if !slices.Equal(session.marked, tt.wantMarks) || !slices.Equal(session.committed, tt.wantMarks) {
+				t.Errorf("marked %v, committed %v, want %v", session.marked, session.committed, tt.wantMarks)
+			}
+			if !slices.Equal(producer.offsets, tt.wantDLQ) {
+				t.Errorf("dlq offsets = %v, want %v", producer.offsets, tt.wantDLQ)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestProcessBatchStopsOnSessionEnd(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := func(events []*models.ProductEvent) []error {
+		cancel()
+		return []error{errors.New("elasticsearch is unavailable")}
+	}
+	producer := &fakeDLQProducer{}
+	consumer := NewKafkaConsumer("", "products", "search", handler,
+		&DeadLetterQueue{topic: "products-dlq", producer: producer}, nil,
+		RetryPolicy{MaxAttempts: 3, Backoff: time.Hour, MaxBackoff: time.Hour},
+		BatchConfig{Size: 1, FlushInterval: time.Hour}, testIndexerMetrics)
+
+	// сессия закончилась во время паузы перед повтором: сообщение не уходит в DLQ,
+	// а offset не фиксируется, и сообщение прочитается снова
+	_, err := consumer.processBatch(ctx, productMessages(1, nil))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("processBatch error = %v, want context.Canceled", err)
+	}
+	if len(producer.offsets) != 0 {
+		t.Errorf("dlq offsets = %v, want none", producer.offsets)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, want := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		if got := policy.delay(attempt); got != want {
+			t.Errorf("delay(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+)
+
+// TopicReader читает заданный диапазон offsets топика без группы потребителей.
+// Нужен переиндексации: она проходит топик с начала, не трогая offsets индексатора.
+type TopicReader struct {
+	client   sarama.Client
+	consumer sarama.Consumer
+	topic    string
+}
+
+func NewTopicReader(broker, topic string) (*TopicReader, error) {
+	client, err := sarama.NewClient([]string{broker}, sarama.NewConfig())
+	if err != nil {
+		return nil, fmt.Errorf("error creating kafka client: %w", err)
+	}
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("error creating kafka consumer: %w", err)
+	}
+	return &TopicReader{client: client, consumer: consumer, topic: topic}, nil
+}
+
+func (r *TopicReader) Close() error {
+	r.consumer.Close()
+	return r.client.Close()
+}
+
+// OldestOffsets - первые доступные offsets каждой партиции
+func (r *TopicReader) OldestOffsets() (map[int32]int64, error) {
+	return r.offsets(sarama.OffsetOldest)
+}
+
+// NewestOffsets - offsets, которые получат следующие сообщения каждой партиции
+func (r *TopicReader) NewestOffsets() (map[int32]int64, error) {
+	return r.offsets(sarama.OffsetNewest)
+}
+
+func (r *TopicReader) offsets(at int64) (map[int32]int64, error) {
+	partitions, err := r.client.Partitions(r.topic)
+	if err != nil {
+		return nil, fmt.Errorf("error listing partitions of %s: %w", r.topic, err)
+	}
+
+	offsets := make(map[int32]int64, len(partitions))
+	for _, partition := range partitions {
+		offset, err := r.client.GetOffset(r.topic, partition, at)
+		if err != nil {
+			return nil, fmt.Errorf("error getting offset of %s/%d: %w", r.topic, partition, err)
+		}
+		offsets[partition] = offset
+	}
+	return offsets, nil
+}
+
+// Read передает handle пачки не больше batchSize сообщений из диапазона [from, to) каждой
+// партиции по очереди. Offset раньше первого доступного заменяется первым доступным.
+func (r *TopicReader) Read(ctx context.Context, from, to map[int32]int64, batchSize int, handle func([]*sarama.ConsumerMessage) error) error {
+	oldest, err := r.OldestOffsets()
+	if err != nil {
+		return err
+	}
+
+	for partition, end := range to {
+		start := max(from[partition], oldest[partition])
+		if start >= end {
+			continue
+		}
+		if err := r.readPartition(ctx, partition, start, end, batchSize, handle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TopicReader) readPartition(ctx context.Context, partition int32, start, end int64, batchSize int, handle func([]*sarama.ConsumerMessage) error) error {
+	pc, err := r.consumer.ConsumePartition(r.topic, partition, start)
+	if err != nil {
+		return fmt.Errorf("error consuming %s/%d from %d: %w", r.topic, partition, start, err)
+	}
+	defer pc.Close()
+
+	batch := make([]*sarama.ConsumerMessage, 0, batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case message, ok := <-pc.Messages():
+			if !ok {
+				return fmt.Errorf("partition consumer %s/%d closed at offset %d", r.topic, partition, start)
+			}
+			if message.Offset < end {
+				batch = append(batch, message)
+			}
+			last := message.Offset >= end-1
+			if len(batch) >= batchSize || (last && len(batch) > 0) {
+				if err := handle(batch); err != nil {
+					return err
+				}
+				batch = batch[:0]
+			}
+			if last {
+				return nil
+			}
+		}
+	}
+}
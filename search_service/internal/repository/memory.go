@@ -0,0 +1,530 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"searchservice/internal/models"
+)
+
+// memoryFieldBoosts - веса полей при поиске, как в searchBody
+var memoryFieldBoosts = map[string]float64{"name": 3, "tags": 2, "description": 1}
+
+type memoryDoc struct {
+	product *models.Product // nil - товар удален, версия удаления остается
+	version int64
+}
+
+// MemoryRepository - поисковый индекс в памяти процесса для разработки без elasticsearch.
+// Находит те же товары, что ElasticRepository, кроме найденных по морфологии и синонимам:
+// их нет, ё приводится к е. Фильтры, сортировки, пагинация, фасеты и подсказки работают
+// как в ElasticRepository, порядок по релевантности близкий, но не тот же. Общее поведение
+// проверяет contract_test.go. Индекс пропадает при остановке.
+type MemoryRepository struct {
+	mu   sync.RWMutex
+	docs map[string]*memoryDoc
+	live int
+	// postings - слово -> товар -> сумма весов полей, где слово встретилось
+	postings map[string]map[string]float64
+	queries  map[string]models.QueryStat
+	feedback map[models.FeedbackKey]models.FeedbackStat
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		docs:     make(map[string]*memoryDoc),
+		postings: make(map[string]map[string]float64),
+		queries:  make(map[string]models.QueryStat),
+		feedback: make(map[models.FeedbackKey]models.FeedbackStat),
+	}
+}
+
+func (mr *MemoryRepository) Bulk(ops []BulkOperation) ([]error, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	errs := make([]error, len(ops))
+	for i, op := range ops {
+		switch {
+		case op.Action == BulkIndex && op.Product != nil:
+			errs[i] = mr.apply(op.ProductID, op.Version, op.Product)
+		case op.Action == BulkDelete:
+			errs[i] = mr.apply(op.ProductID, op.Version, nil)
+		default:
+			errs[i] = fmt.Errorf("%w: bulk %s ID=%s without product", ErrDocumentRejected, op.Action, op.ProductID)
+		}
+	}
+	return errs, nil
+}
+
+func (mr *MemoryRepository) IndexProduct(product *models.Product, version int64) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	return mr.apply(product.ProductID, version, product)
+}
+
+// GetProduct возвращает копию документа: сервис меняет ее перед записью
+func (mr *MemoryRepository) GetProduct(productID string) (*models.Product, int64, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	doc, ok := mr.docs[productID]
+	if !ok || doc.product == nil {
+		return nil, 0, ErrProductNotFound
+	}
+	return copyProduct(doc.product), doc.version, nil
+}
+
+// apply заменяет документ версией version, product == nil удаляет его. Вызывается под mu.
+func (mr *MemoryRepository) apply(id string, version int64, product *models.Product) error {
+	doc, ok := mr.docs[id]
+	if ok && doc.version >= version {
+		return ErrVersionConflict
+	}
+	if ok && doc.product != nil {
+		mr.unindex(id, doc.product)
+		mr.live--
+	}
+
+	next := &memoryDoc{version: version}
+	if product != nil {
+		next.product = copyProduct(product)
+		next.product.ProductID = id
+		mr.index(id, next.product)
+		mr.live++
+	}
+	mr.docs[id] = next
+	return nil
+}
+
+func (mr *MemoryRepository) index(id string, product *models.Product) {
+	for term, weight := range productTerms(product) {
+		posting, ok := mr.postings[term]
+		if !ok {
+			posting = make(map[string]float64)
+			mr.postings[term] = posting
+		}
+		posting[id] = weight
+	}
+}
+
+func (mr *MemoryRepository) unindex(id string, product *models.Product) {
+	for term := range productTerms(product) {
+		delete(mr.postings[term], id)
+		if len(mr.postings[term]) == 0 {
+			delete(mr.postings, term)
+		}
+	}
+}
+
+type memoryHit struct {
+	product *models.Product
+	score   float64
+	key     float64
+	// matched - слова товара, совпавшие с запросом, их выделяет подсветка
+	matched map[string]bool
+}
+
+// Search находит товары так же, как ElasticRepository (см. matchText). Релевантность - сумма
+// весов полей с совпавшими словами, умноженных на idf, слово с опечаткой весит вдвое меньше.
+func (mr *MemoryRepository) Search(params models.SearchParams) (models.SearchResult, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	variants := [][]string{tokenize(params.Query)}
+	if params.TranslitQuery != "" {
+		variants = append(variants, tokenize(params.TranslitQuery))
+	}
+
+	scores := make(map[string]float64)
+	matched := make(map[string]map[string]bool)
+	for id, doc := range mr.docs {
+		if doc.product == nil {
+			continue
+		}
+		if params.Query == "" {
+			scores[id] = 0
+			continue
+		}
+		for _, words := range variants {
+			score, found, ok := mr.matchText(doc.product, words)
+			if ok && (matched[id] == nil || score > scores[id]) {
+				scores[id], matched[id] = score, found
+			}
+		}
+	}
+
+	hits := make([]memoryHit, 0, len(scores))
+	for id, score := range scores {
+		product := mr.docs[id].product
+		if matchesFilters(product, params) {
+			hits = append(hits, memoryHit{product: product, score: score, key: sortKey(product, score, params.Sort), matched: matched[id]})
+		}
+	}
+
+	desc := params.Sort != models.SortPriceAsc
+	less := func(a, b memoryHit) bool {
+		if a.key != b.key {
+			if desc {
+				return a.key > b.key
+			}
+			return a.key < b.key
+		}
+		return a.product.ProductID < b.product.ProductID
+	}
+	sort.Slice(hits, func(i, j int) bool { return less(hits[i], hits[j]) })
+
+	result := models.SearchResult{
+		Total:  int64(len(hits)),
+		Hits:   []models.SearchHit{},
+		Facets: memoryFacets(hits),
+	}
+
+	start := params.From
+	if len(params.SearchAfter) > 0 {
+		cursor, err := searchAfterHit(params.SearchAfter)
+		if err != nil {
+			return models.SearchResult{}, err
+		}
+		start = sort.Search(len(hits), func(i int) bool { return less(cursor, hits[i]) })
+	}
+	for i := start; i < len(hits) && i < start+params.Size; i++ {
+		hit := models.SearchHit{
+			ProductID: hits[i].product.ProductID,
+			Score:     hits[i].score,
+			Sort:      []any{hits[i].key, hits[i].product.ProductID},
+		}
+		if len(hits[i].matched) > 0 {
+			hit.Highlight = make(map[string][]string)
+			for field, text := range map[string]string{"name": hits[i].product.Name, "description": hits[i].product.Description} {
+				if marked, ok := highlight(text, hits[i].matched); ok {
+					hit.Highlight[field] = []string{marked}
+				}
+			}
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	return result, nil
+}
+
+// Suggest перебирает все товары: для индекса разработки это быстрее, чем отдельный индекс префиксов
+func (mr *MemoryRepository) Suggest(params models.SuggestParams) (models.Suggestions, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	prefixes := [][]string{tokenize(params.Prefix)}
+	if params.TranslitPrefix != "" {
+		prefixes = append(prefixes, tokenize(params.TranslitPrefix))
+	}
+	tagPrefixes := []string{normalizeToken(params.Prefix)}
+	if params.TranslitPrefix != "" {
+		tagPrefixes = append(tagPrefixes, normalizeToken(params.TranslitPrefix))
+	}
+
+	var products []models.ProductSuggestion
+	tags := make(map[string]bool)
+	for id, doc := range mr.docs {
+		if doc.product == nil {
+			continue
+		}
+		name := tokenize(doc.product.Name)
+		for _, prefix := range prefixes {
+			if len(prefix) > 0 && hasPrefixes(name, prefix) {
+				products = append(products, models.ProductSuggestion{ProductID: id, Name: doc.product.Name})
+				break
+			}
+		}
+		for _, tag := range doc.product.Tags {
+			for _, prefix := range tagPrefixes {
+				if strings.HasPrefix(normalizeToken(tag), prefix) {
+					tags[tag] = true
+				}
+			}
+		}
+	}
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].Name != products[j].Name {
+			return products[i].Name < products[j].Name
+		}
+		return products[i].ProductID < products[j].ProductID
+	})
+
+	var queries []string
+	for query, stat := range mr.queries {
+		if stat.Results > 0 && len(stat.Sessions) >= params.MinSessions && strings.HasPrefix(query, strings.ToLower(params.Prefix)) {
+			queries = append(queries, query)
+		}
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		a, b := mr.queries[queries[i]], mr.queries[queries[j]]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return queries[i] < queries[j]
+	})
+
+	tagList := sortedKeys(tags)
+	result := models.Suggestions{
+		Products: append([]models.ProductSuggestion{}, products[:min(len(products), params.Size)]...),
+		Tags:     tagList[:min(len(tagList), params.Size)],
+		Queries:  append([]string{}, queries[:min(len(queries), params.Size)]...),
+	}
+	return result, nil
+}
+
+func (mr *MemoryRepository) RecordQueries(stats map[string]models.QueryStat) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	for query, stat := range stats {
+		current := mr.queries[query]
+		current.Count += stat.Count
+		current.Results = stat.Results
+		current.LastSeen = stat.LastSeen
+		for _, session := range stat.Sessions {
+			if len(current.Sessions) < models.MaxQuerySessions && !slices.Contains(current.Sessions, session) {
+				current.Sessions = append(current.Sessions, session)
+			}
+		}
+		mr.queries[query] = current
+	}
+	return nil
+}
+
+func (mr *MemoryRepository) RecordFeedback(stats map[models.FeedbackKey]models.FeedbackStat) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	for key, stat := range stats {
+		current := mr.feedback[key]
+		current.Impressions += stat.Impressions
+		current.Clicks += stat.Clicks
+		mr.feedback[key] = current
+	}
+	return nil
+}
+
+func (mr *MemoryRepository) FeedbackStats(query string, productIDs []string) (map[models.FeedbackKey]models.FeedbackStat, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	stats := make(map[models.FeedbackKey]models.FeedbackStat)
+	for _, id := range productIDs {
+		for _, key := range []models.FeedbackKey{{Query: query, ProductID: id}, {ProductID: id}} {
+			if stat, ok := mr.feedback[key]; ok {
+				stats[key] = stat
+			}
+		}
+	}
+	return stats, nil
+}
+
+// Ping всегда успешен: индекс в памяти процесса
+func (mr *MemoryRepository) Ping(context.Context) error {
+	return nil
+}
+
+func matchesFilters(product *models.Product, params models.SearchParams) bool {
+	if params.PriceMin != nil && int(product.Price) < *params.PriceMin {
+		return false
+	}
+	if params.PriceMax != nil && int(product.Price) > *params.PriceMax {
+		return false
+	}
+	if params.Seller != "" && product.Seller != params.Seller {
+		return false
+	}
+	for _, tag := range params.Tags {
+		if !slices.Contains(product.Tags, tag) {
+			return false
+		}
+	}
+	if len(params.Sizes) > 0 && !slices.ContainsFunc(params.Sizes, func(size string) bool {
+		return slices.Contains(product.Sizes, size)
+	}) {
+		return false
+	}
+	return true
+}
+
+// sortKey - значение сортировки, как его возвращает elasticsearch: дата - в миллисекундах
+func sortKey(product *models.Product, score float64, order string) float64 {
+	switch order {
+	case models.SortPriceAsc, models.SortPriceDesc:
+		return float64(product.Price)
+	case models.SortNewest:
+		return float64(product.CreatedAt.UnixMilli())
+	}
+	return score
+}
+
+func searchAfterHit(values []any) (memoryHit, error) {
+	if len(values) != 2 {
+		return memoryHit{}, fmt.Errorf("search_after must have 2 values, got %d", len(values))
+	}
+	id, ok := values[1].(string)
+	if !ok {
+		return memoryHit{}, fmt.Errorf("search_after product ID must be a string")
+	}
+
+	var key float64
+	switch v := values[0].(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return memoryHit{}, fmt.Errorf("invalid search_after value: %w", err)
+		}
+		key = f
+	case float64:
+		key = v
+	default:
+		return memoryHit{}, fmt.Errorf("search_after sort value must be a number")
+	}
+	return memoryHit{product: &models.Product{ProductID: id}, key: key}, nil
+}
+
+func memoryFacets(hits []memoryHit) models.Facets {
+	tagCounts := make(map[string]int64)
+	for _, hit := range hits {
+		for _, tag := range hit.product.Tags {
+			tagCounts[tag]++
+		}
+	}
+	tags := make([]models.TagFacet, 0, len(tagCounts))
+	for tag, count := range tagCounts {
+		tags = append(tags, models.TagFacet{Value: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Value < tags[j].Value
+	})
+
+	facets := models.Facets{Tags: tags[:min(len(tags), tagFacetSize)], Price: make([]models.PriceFacet, 0, len(priceRanges))}
+	for _, r := range priceRanges {
+		facet := models.PriceFacet{}
+		if from, ok := r["from"].(int); ok {
+			f := float64(from)
+			facet.From = &f
+		}
+		if to, ok := r["to"].(int); ok {
+			t := float64(to)
+			facet.To = &t
+		}
+		for _, hit := range hits {
+			price := float64(hit.product.Price)
+			if (facet.From == nil || price >= *facet.From) && (facet.To == nil || price < *facet.To) {
+				facet.Count++
+			}
+		}
+		facets.Price = append(facets.Price, facet)
+	}
+	return facets
+}
+
+// productTerms - слова товара с суммой весов полей, в которых они встретились
+func productTerms(product *models.Product) map[string]float64 {
+	terms := make(map[string]float64)
+	fields := map[string]string{
+		"name":        product.Name,
+		"tags":        strings.Join(product.Tags, " "),
+		"description": product.Description,
+	}
+	for field, text := range fields {
+		seen := make(map[string]bool)
+		for _, term := range tokenize(text) {
+			if !seen[term] {
+				seen[term] = true
+				terms[term] += memoryFieldBoosts[field]
+			}
+		}
+	}
+	return terms
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(normalizeToken(text), func(r rune) bool { return !isWordRune(r) })
+}
+
+func normalizeToken(text string) string {
+	return strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// hasPrefixes - для каждого префикса в name есть слово, которое с него начинается
+func hasPrefixes(name, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		found := false
+		for _, word := range name {
+			if strings.HasPrefix(word, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// highlight выделяет совпавшие слова тегами <em>, как подсветка elasticsearch
+func highlight(text string, terms map[string]bool) (string, bool) {
+	var b strings.Builder
+	matched := false
+	start := -1
+	word := func(end int) {
+		w := text[start:end]
+		if terms[normalizeToken(w)] {
+			b.WriteString("<em>" + w + "</em>")
+			matched = true
+		} else {
+			b.WriteString(w)
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			word(i)
+		}
+		b.WriteRune(r)
+	}
+	if start >= 0 {
+		word(len(text))
+	}
+	return b.String(), matched
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func copyProduct(product *models.Product) *models.Product {
+	p := *product
+	p.Tags = append([]string(nil), product.Tags...)
+	p.Sizes = append([]string(nil), product.Sizes...)
+	return &p
+}
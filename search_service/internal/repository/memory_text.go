@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"math"
+	"strings"
+
+	"searchservice/internal/models"
+)
+
+// memoryFields - поля, по которым ищет текст, в порядке textQueries
+var memoryFields = []string{"name", "tags", "description"}
+
+// memoryTypoWeight - вес слова, найденного с опечаткой, относительно точного совпадения
+const memoryTypoWeight = 0.5
+
+// matchText повторяет textQueries: товар находится, если в одном из полей есть все слова
+// words (operator and), каждое точно или с опечаткой (fuzziness AUTO, первая буква без
+// опечатки), или если название содержит слова подряд, а последнее слово - недописанное
+// (match_phrase_prefix). found - слова товара, совпавшие со словами запроса.
+func (mr *MemoryRepository) matchText(product *models.Product, words []string) (score float64, found map[string]bool, ok bool) {
+	if len(words) == 0 {
+		return 0, nil, false
+	}
+
+	fields := map[string][]string{
+		"name":        tokenize(product.Name),
+		"tags":        tokenize(strings.Join(product.Tags, " ")),
+		"description": tokenize(product.Description),
+	}
+	found = make(map[string]bool)
+	for _, field := range memoryFields {
+		tokens := fields[field]
+		matches := make([]string, 0, len(words))
+		typos := 0
+		for _, word := range words {
+			match, typo := matchWord(word, tokens)
+			if match == "" {
+				break
+			}
+			matches = append(matches, match)
+			if typo {
+				typos++
+			}
+		}
+		if len(matches) < len(words) && field == "name" {
+			matches, typos = phrasePrefix(words, tokens), 0
+		}
+		if len(matches) < len(words) {
+			continue
+		}
+
+		ok = true
+		weight := memoryFieldBoosts[field]
+		if typos > 0 {
+			weight *= memoryTypoWeight
+		}
+		for _, match := range matches {
+			found[match] = true
+			score += weight * mr.idf(match)
+		}
+	}
+	return score, found, ok
+}
+
+// matchWord - слово tokens, совпадающее с word точно, а если такого нет - с опечаткой
+func matchWord(word string, tokens []string) (match string, typo bool) {
+	for _, token := range tokens {
+		if token == word {
+			return token, false
+		}
+	}
+
+	maxEdits := fuzziness(word)
+	if maxEdits == 0 {
+		return "", false
+	}
+	for _, token := range tokens {
+		if firstRune(token) == firstRune(word) && editDistance(word, token) <= maxEdits {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+// phrasePrefix - слова name, совпавшие с words, если они идут подряд и последнее слово
+// name начинается с последнего слова words. nil - фраза не найдена.
+func phrasePrefix(words, name []string) []string {
+	last := len(words) - 1
+	for start := 0; start+last < len(name); start++ {
+		matched := true
+		for i, word := range words[:last] {
+			if name[start+i] != word {
+				matched = false
+				break
+			}
+		}
+		if matched && strings.HasPrefix(name[start+last], words[last]) {
+			return name[start : start+last+1]
+		}
+	}
+	return nil
+}
+
+// idf - редкость слова в индексе. Вызывается под mu.
+func (mr *MemoryRepository) idf(term string) float64 {
+	return math.Log(1 + float64(mr.live)/float64(len(mr.postings[term])+1))
+}
+
+// fuzziness - сколько опечаток допускает fuzziness AUTO elasticsearch: до трех букв - ни
+// одной, до шести - одну, дальше две
+func fuzziness(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	}
+	return 2
+}
+
+func firstRune(word string) rune {
+	for _, r := range word {
+		return r
+	}
+	return 0
+}
+
+// editDistance - число вставок, удалений, замен и перестановок соседних букв, которые
+// превращают a в b (перестановку elasticsearch тоже считает одной опечаткой)
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"searchservice/internal/models"
+)
+
+// queryLogIndex - счетчики поисковых запросов для подсказок. Документ - нормализованный
+// текст запроса, его id - сам текст. Индекс не версионируется: reindex его не трогает.
+const queryLogIndex = "search_queries"
+
+const queryLogMapping = `
+{
+  "mappings": {
+    "properties": {
+      "query":         { "type": "keyword" },
+      "count":         { "type": "long" },
+      "results":       { "type": "long" },
+      "last_seen":     { "type": "date" },
+      "sessions":      { "type": "keyword", "index": false },
+      "session_count": { "type": "integer" }
+    }
+  }
+}`
+
+// querySessionsMapping добавляет поля сессий в индекс, созданный до их появления
+const querySessionsMapping = `
+{
+  "properties": {
+    "sessions":      { "type": "keyword", "index": false },
+    "session_count": { "type": "integer" }
+  }
+}`
+
+// queryLogScript прибавляет запросы к счетчику, запоминает последнее число найденных товаров
+// и добавляет новые сессии, пока их меньше max_sessions
+const queryLogScript = `ctx._source.count += params.count; ctx._source.results = params.results; ` +
+	`ctx._source.last_seen = params.last_seen; ` +
+	`if (ctx._source.sessions == null) { ctx._source.sessions = []; } ` +
+	`for (s in params.sessions) { if (ctx._source.sessions.size() >= params.max_sessions) { break; } ` +
+	`if (!ctx._source.sessions.contains(s)) { ctx._source.sessions.add(s); } } ` +
+	`ctx._source.session_count = ctx._source.sessions.size();`
+
+func (er *ElasticRepository) ensureQueryLog() error {
+	res, err := er.client.Indices.Exists([]string{queryLogIndex})
+	if err != nil {
+		return fmt.Errorf("error checking index existence: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 200 {
+		return er.updateQueryLogMapping()
+	}
+
+	createRes, err := er.client.Indices.Create(
+		queryLogIndex,
+		er.client.Indices.Create.WithContext(context.Background()),
+		er.client.Indices.Create.WithBody(strings.NewReader(queryLogMapping)),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating index %s: %w", queryLogIndex, err)
+	}
+	defer createRes.Body.Close()
+	// индекс мог создать другой экземпляр сервиса
+	if createRes.IsError() && !strings.Contains(createRes.String(), "resource_already_exists_exception") {
+		return fmt.Errorf("elasticsearch error on index %s create: %s", queryLogIndex, createRes.String())
+	}
+
+	log.Printf("Index '%s' created", queryLogIndex)
+	return nil
+}
+
+func (er *ElasticRepository) updateQueryLogMapping() error {
+	res, err := er.client.Indices.PutMapping(
+		strings.NewReader(querySessionsMapping),
+		er.client.Indices.PutMapping.WithIndex(queryLogIndex),
+		er.client.Indices.PutMapping.WithContext(context.Background()),
+	)
+	if err != nil {
+		return fmt.Errorf("error updating index %s mapping: %w", queryLogIndex, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error on index %s mapping update: %s", queryLogIndex, res.String())
+	}
+	return nil
+}
+
+// RecordQueries прибавляет накопленные счетчики запросов одним запросом _bulk
+func (er *ElasticRepository) RecordQueries(stats map[string]models.QueryStat) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for query, stat := range stats {
+		lastSeen := stat.LastSeen.UTC().Format(time.RFC3339)
+		sessions := stat.Sessions
+		if sessions == nil {
+			sessions = []string{}
+		}
+		action := map[string]any{"update": map[string]any{
+			"_index":            queryLogIndex,
+			"_id":               query,
+			"retry_on_conflict": 3,
+		}}
+		doc := map[string]any{
+			"script": map[string]any{
+				"source": queryLogScript,
+				"params": map[string]any{
+					"count":        stat.Count,
+					"results":      stat.Results,
+					"last_seen":    lastSeen,
+					"sessions":     sessions,
+					"max_sessions": models.MaxQuerySessions,
+				},
+			},
+			"upsert": map[string]any{
+				"query":         query,
+				"count":         stat.Count,
+				"results":       stat.Results,
+				"last_seen":     lastSeen,
+				"sessions":      sessions,
+				"session_count": len(sessions),
+			},
+		}
+		if err := enc.Encode(action); err != nil {
+			return fmt.Errorf("error encoding query log action: %w", err)
+		}
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("error encoding query log update: %w", err)
+		}
+	}
+
+	res, err := er.client.Bulk(bytes.NewReader(body.Bytes()), er.client.Bulk.WithContext(context.Background()))
+	if err != nil {
+		return fmt.Errorf("error sending query log: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error on query log: %s", res.String())
+	}
+
+	var resp struct {
+		Errors bool `json:"errors"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return fmt.Errorf("error parsing query log response: %w", err)
+	}
+	if resp.Errors {
+		return fmt.Errorf("elasticsearch rejected some query log updates")
+	}
+	return nil
+}
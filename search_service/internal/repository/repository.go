@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"searchservice/internal/models"
+)
+
+// SearchRepository - поисковый индекс товаров. Реализации: ElasticRepository и
+// MemoryRepository для локальной разработки без elasticsearch.
+//
+// Версии внешние: запись с версией не больше уже сохраненной возвращает ErrVersionConflict,
+// удаление тоже запоминает версию.
+type SearchRepository interface {
+	Bulk(ops []BulkOperation) ([]error, error)
+	IndexProduct(product *models.Product, version int64) error
+	GetProduct(productID string) (*models.Product, int64, error)
+	Search(params models.SearchParams) (models.SearchResult, error)
+	Suggest(params models.SuggestParams) (models.Suggestions, error)
+	RecordQueries(stats map[string]models.QueryStat) error
+	// Ping проверяет, что индекс доступен
+	Ping(ctx context.Context) error
+}
+
+// FeedbackRepository - счетчики показов и кликов для ранжирования
+type FeedbackRepository interface {
+	RecordFeedback(stats map[models.FeedbackKey]models.FeedbackStat) error
+	// FeedbackStats - счетчики товаров productIDs по запросу query и по всем запросам
+	FeedbackStats(query string, productIDs []string) (map[models.FeedbackKey]models.FeedbackStat, error)
+}
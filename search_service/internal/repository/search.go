@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"searchservice/internal/models"
+)
+
+const tagFacetSize = 20
+
+// priceRanges - границы корзин фасета цены
+var priceRanges = []map[string]any{
+	{"to": 500},
+	{"from": 500, "to": 1000},
+	{"from": 1000, "to": 5000},
+	{"from": 5000, "to": 10000},
+	{"from": 10000},
+}
+
+// sortOrders - сортировки поиска. product_id в конце делает порядок однозначным для search_after.
+var sortOrders = map[string][]any{
+	models.SortRelevance: {map[string]any{"_score": "desc"}, map[string]any{"product_id": "asc"}},
+	models.SortPriceAsc:  {map[string]any{"price": "asc"}, map[string]any{"product_id": "asc"}},
+	models.SortPriceDesc: {map[string]any{"price": "desc"}, map[string]any{"product_id": "asc"}},
+	models.SortNewest:    {map[string]any{"created_at": "desc"}, map[string]any{"product_id": "asc"}},
+}
+
+// Search ищет товары по тексту и фильтрам и считает фасеты по найденным товарам
+func (er *ElasticRepository) Search(params models.SearchParams) (models.SearchResult, error) {
+	var buf strings.Builder
+	if err := json.NewEncoder(&buf).Encode(searchBody(params)); err != nil {
+		return models.SearchResult{}, fmt.Errorf("error encoding search query: %w", err)
+	}
+
+	res, err := er.client.Search(
+		er.client.Search.WithContext(context.Background()),
+		er.client.Search.WithIndex(er.index),
+		er.client.Search.WithBody(strings.NewReader(buf.String())),
+		er.client.Search.WithTrackTotalHits(true),
+	)
+	if err != nil {
+		return models.SearchResult{}, fmt.Errorf("error getting response from Elasticsearch: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return models.SearchResult{}, fmt.Errorf("elasticsearch returned error: %s", res.String())
+	}
+
+	var sr struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID        string              `json:"_id"`
+				Score     *float64            `json:"_score"`
+				Highlight map[string][]string `json:"highlight"`
+				Sort      []any               `json:"sort"`
+			} `json:"hits"`
+		} `json:"hits"`
+		Aggregations struct {
+			Tags struct {
+				Buckets []struct {
+					Key      string `json:"key"`
+					DocCount int64  `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"tags"`
+			Price struct {
+				Buckets []struct {
+					From     *float64 `json:"from"`
+					To       *float64 `json:"to"`
+					DocCount int64    `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"price"`
+		} `json:"aggregations"`
+	}
+	decoder := json.NewDecoder(res.Body)
+	// значения сортировки возвращаются в search_after как есть, большие числа не должны терять точность
+	decoder.UseNumber()
+	if err := decoder.Decode(&sr); err != nil {
+		return models.SearchResult{}, fmt.Errorf("error parsing search response: %w", err)
+	}
+
+	result := models.SearchResult{
+		Total: sr.Hits.Total.Value,
+		Hits:  make([]models.SearchHit, 0, len(sr.Hits.Hits)),
+		Facets: models.Facets{
+			Tags:  make([]models.TagFacet, 0, len(sr.Aggregations.Tags.Buckets)),
+			Price: make([]models.PriceFacet, 0, len(sr.Aggregations.Price.Buckets)),
+		},
+	}
+	for _, h := range sr.Hits.Hits {
+		hit := models.SearchHit{ProductID: h.ID, Highlight: h.Highlight, Sort: h.Sort}
+		if h.Score != nil {
+			hit.Score = *h.Score
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	for _, b := range sr.Aggregations.Tags.Buckets {
+		result.Facets.Tags = append(result.Facets.Tags, models.TagFacet{Value: b.Key, Count: b.DocCount})
+	}
+	for _, b := range sr.Aggregations.Price.Buckets {
+		result.Facets.Price = append(result.Facets.Price, models.PriceFacet{From: b.From, To: b.To, Count: b.DocCount})
+	}
+	return result, nil
+}
+
+// textQueries - варианты совпадения текста в порядке убывания веса: точное слово в названии,
+// слово с учетом морфологии и синонимов, начало названия при наборе, слово с опечаткой.
+// Во всех вариантах в поле должны найтись все слова запроса. Название весит больше тегов,
+// теги - больше описания. MemoryRepository повторяет эти правила в matchText.
+func textQueries(text string) []any {
+	return []any{
+		map[string]any{
+			"match": map[string]any{"name.exact": map[string]any{"query": text, "operator": "and", "boost": 4}},
+		},
+		map[string]any{
+			"multi_match": map[string]any{
+				"query":    text,
+				"fields":   []string{"name^3", "tags^2", "description"},
+				"type":     "best_fields",
+				"operator": "and",
+				"boost":    2,
+			},
+		},
+		map[string]any{
+			"match_phrase_prefix": map[string]any{"name": map[string]any{"query": text, "boost": 2}},
+		},
+		map[string]any{
+			// синонимы с опечатками не сочетаются, поэтому нечеткий поиск идет анализатором без них
+			"multi_match": map[string]any{
+				"query":          text,
+				"fields":         []string{"name^3", "tags^2", "description"},
+				"fuzziness":      "AUTO",
+				"prefix_length":  1,
+				"max_expansions": 50,
+				"operator":       "and",
+				"analyzer":       "ru_en",
+			},
+		},
+	}
+}
+
+func searchBody(params models.SearchParams) map[string]any {
+	query := map[string]any{}
+
+	if params.Query != "" {
+		should := textQueries(params.Query)
+		if params.TranslitQuery != "" {
+			should = append(should, textQueries(params.TranslitQuery)...)
+		}
+		query["must"] = []any{
+			map[string]any{"bool": map[string]any{"should": should, "minimum_should_match": 1}},
+		}
+	}
+
+	filters := []any{}
+	if params.PriceMin != nil || params.PriceMax != nil {
+		price := map[string]any{}
+		if params.PriceMin != nil {
+			price["gte"] = *params.PriceMin
+		}
+		if params.PriceMax != nil {
+			price["lte"] = *params.PriceMax
+		}
+		filters = append(filters, map[string]any{"range": map[string]any{"price": price}})
+	}
+	for _, tag := range params.Tags {
+		filters = append(filters, map[string]any{"term": map[string]any{"tags.keyword": tag}})
+	}
+	if len(params.Sizes) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"sizes": params.Sizes}})
+	}
+	if params.Seller != "" {
+		filters = append(filters, map[string]any{"term": map[string]any{"seller": params.Seller}})
+	}
+	if len(filters) > 0 {
+		query["filter"] = filters
+	}
+
+	body := map[string]any{
+		"query":        map[string]any{"bool": query},
+		"size":         params.Size,
+		"sort":         sortOrders[params.Sort],
+		"track_scores": true,
+		"_source":      false, // возвращаем только _id
+		"highlight": map[string]any{
+			"fields": map[string]any{
+				"name":        map[string]any{"number_of_fragments": 0},
+				"description": map[string]any{"fragment_size": 150, "number_of_fragments": 1},
+			},
+		},
+		"aggs": map[string]any{
+			"tags":  map[string]any{"terms": map[string]any{"field": "tags.keyword", "size": tagFacetSize}},
+			"price": map[string]any{"range": map[string]any{"field": "price", "ranges": priceRanges}},
+		},
+	}
+	if len(params.SearchAfter) > 0 {
+		body["search_after"] = params.SearchAfter
+	} else {
+		body["from"] = params.From
+	}
+	return body
+}
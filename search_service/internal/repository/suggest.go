@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"searchservice/internal/models"
+)
+
+// Suggest ищет названия товаров и теги по началу слова и популярные запросы с тем же началом.
+// Оба поиска уходят одним запросом _msearch.
+func (er *ElasticRepository) Suggest(params models.SuggestParams) (models.Suggestions, error) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, part := range []any{
+		map[string]any{"index": er.index},
+		productSuggestBody(params),
+		map[string]any{"index": queryLogIndex},
+		querySuggestBody(params),
+	} {
+		if err := enc.Encode(part); err != nil {
+			return models.Suggestions{}, fmt.Errorf("error encoding suggest query: %w", err)
+		}
+	}
+
+	res, err := er.client.Msearch(bytes.NewReader(body.Bytes()), er.client.Msearch.WithContext(context.Background()))
+	if err != nil {
+		return models.Suggestions{}, fmt.Errorf("error getting response from Elasticsearch: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return models.Suggestions{}, fmt.Errorf("elasticsearch returned error: %s", res.String())
+	}
+
+	var sr struct {
+		Responses []struct {
+			Error json.RawMessage `json:"error"`
+			Hits  struct {
+				Hits []struct {
+					ID     string `json:"_id"`
+					Source struct {
+						Name  string `json:"name"`
+						Query string `json:"query"`
+					} `json:"_source"`
+				} `json:"hits"`
+			} `json:"hits"`
+			Suggest map[string][]struct {
+				Options []struct {
+					Text string `json:"text"`
+				} `json:"options"`
+			} `json:"suggest"`
+		} `json:"responses"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&sr); err != nil {
+		return models.Suggestions{}, fmt.Errorf("error parsing suggest response: %w", err)
+	}
+	if len(sr.Responses) != 2 {
+		return models.Suggestions{}, fmt.Errorf("msearch returned %d responses for 2 searches", len(sr.Responses))
+	}
+	for _, r := range sr.Responses {
+		if r.Error != nil {
+			return models.Suggestions{}, fmt.Errorf("elasticsearch returned error: %s", r.Error)
+		}
+	}
+
+	products, queries := sr.Responses[0], sr.Responses[1]
+	result := models.Suggestions{
+		Products: make([]models.ProductSuggestion, 0, len(products.Hits.Hits)),
+		Tags:     []string{},
+		Queries:  make([]string, 0, len(queries.Hits.Hits)),
+	}
+	for _, h := range products.Hits.Hits {
+		result.Products = append(result.Products, models.ProductSuggestion{ProductID: h.ID, Name: h.Source.Name})
+	}
+	// теги по префиксу и по его транслитерации, без повторов
+	for _, name := range []string{"tags", "tags_translit"} {
+		for _, s := range products.Suggest[name] {
+			for _, o := range s.Options {
+				if len(result.Tags) < params.Size && !slices.Contains(result.Tags, o.Text) {
+					result.Tags = append(result.Tags, o.Text)
+				}
+			}
+		}
+	}
+	for _, h := range queries.Hits.Hits {
+		result.Queries = append(result.Queries, h.Source.Query)
+	}
+	return result, nil
+}
+
+// productSuggestBody - товары, в названии которых есть слова, начинающиеся с префикса
+// (последнее слово может быть недописанным), и теги с этим началом
+func productSuggestBody(params models.SuggestParams) map[string]any {
+	should := []any{prefixQuery(params.Prefix)}
+	suggest := map[string]any{"tags": tagCompletion(params.Prefix, params.Size)}
+	if params.TranslitPrefix != "" {
+		should = append(should, prefixQuery(params.TranslitPrefix))
+		suggest["tags_translit"] = tagCompletion(params.TranslitPrefix, params.Size)
+	}
+
+	return map[string]any{
+		"query":   map[string]any{"bool": map[string]any{"should": should, "minimum_should_match": 1}},
+		"size":    params.Size,
+		"_source": []string{"name"},
+		"suggest": suggest,
+	}
+}
+
+func tagCompletion(prefix string, size int) map[string]any {
+	return map[string]any{
+		"prefix": prefix,
+		"completion": map[string]any{
+			"field":           "tags.suggest",
+			"size":            size,
+			"skip_duplicates": true,
+		},
+	}
+}
+
+func prefixQuery(prefix string) map[string]any {
+	return map[string]any{
+		"multi_match": map[string]any{
+			"query":    prefix,
+			"type":     "bool_prefix",
+			"operator": "and",
+			"fields":   []string{"name.suggest", "name.suggest._2gram", "name.suggest._3gram"},
+		},
+	}
+}
+
+// querySuggestBody - самые частые запросы с этим началом. Запросы, по которым ничего
+// не нашлось или которые искали меньше MinSessions разных сессий, не подсказываются.
+func querySuggestBody(params models.SuggestParams) map[string]any {
+	filters := []any{
+		map[string]any{"prefix": map[string]any{"query": strings.ToLower(params.Prefix)}},
+		map[string]any{"range": map[string]any{"results": map[string]any{"gt": 0}}},
+	}
+	if params.MinSessions > 0 {
+		filters = append(filters, map[string]any{"range": map[string]any{"session_count": map[string]any{"gte": params.MinSessions}}})
+	}
+
+	return map[string]any{
+		"query":   map[string]any{"bool": map[string]any{"filter": filters}},
+		"size":    params.Size,
+		"sort":    []any{map[string]any{"count": "desc"}},
+		"_source": []string{"query"},
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Blocklist - слова, с которыми запрос не попадает в журнал и не подсказывается.
+// Слово сравнивается с каждым словом запроса и его транслитерации.
+type Blocklist struct {
+	words map[string]bool
+}
+
+// LoadBlocklist читает слова из файла path, по одному в строке. Пустые строки и строки,
+// начинающиеся с #, пропускаются. Пустой path - пустой список.
+func LoadBlocklist(path string) (*Blocklist, error) {
+	blocklist := &Blocklist{words: make(map[string]bool)}
+	if path == "" {
+		return blocklist, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening suggest blocklist: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := normalizeQuery(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, word := range strings.Fields(line) {
+			blocklist.words[word] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading suggest blocklist: %w", err)
+	}
+	return blocklist, nil
+}
+
+// Blocked - в нормализованном запросе query есть слово из списка
+func (b *Blocklist) Blocked(query string) bool {
+	if b == nil || len(b.words) == 0 {
+		return false
+	}
+	for _, text := range []string{query, transliterate(query)} {
+		for _, word := range strings.Fields(text) {
+			if b.words[word] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"searchservice/internal/models"
+	"searchservice/internal/repository"
+)
+
+const (
+	feedbackBuffer = 4096
+	maxSessionLen  = 128
+)
+
+// Feedback принимает показы и клики по результатам поиска. Сырые события уходят в топик
+// аналитики, счетчики для ранжирования копятся в памяти и раз в flushInterval пишутся в индекс.
+// Повторы и события сверх limits не попадают ни в топик, ни в счетчики. Лимиты считаются
+// в памяти экземпляра сервиса.
+type Feedback struct {
+	repo          repository.FeedbackRepository
+	topic         *repository.FeedbackTopic
+	events        chan models.FeedbackEvent
+	flushInterval time.Duration
+	guard         *feedbackGuard
+}
+
+func NewFeedback(repo repository.FeedbackRepository, topic *repository.FeedbackTopic, flushInterval time.Duration, limits FeedbackLimits) *Feedback {
+	return &Feedback{
+		repo:          repo,
+		topic:         topic,
+		events:        make(chan models.FeedbackEvent, feedbackBuffer),
+		flushInterval: flushInterval,
+		guard:         newFeedbackGuard(limits),
+	}
+}
+
+// Record проверяет события и пишет в топик те, что нужно учесть. Ошибка, обернутая
+// в models.ErrInvalidFeedback, - ошибка клиента, остальные - недоступность топика.
+func (f *Feedback) Record(events []models.FeedbackEvent) error {
+	now := time.Now().UTC()
+	for i := range events {
+		event := &events[i]
+		event.Query = normalizeQuery(event.Query)
+		if err := validateFeedback(*event); err != nil {
+			return fmt.Errorf("%w: event %d: %s", models.ErrInvalidFeedback, i, err)
+		}
+		event.OccurredAt = now
+	}
+
+	events = f.guard.accept(events, now)
+	if len(events) == 0 {
+		return nil
+	}
+	if err := f.topic.Send(events); err != nil {
+		f.guard.release(events)
+		return err
+	}
+
+	for _, event := range events {
+		select {
+		case f.events <- event:
+		default:
+			// счетчики ранжирования приблизительные, полные данные - в топике
+		}
+	}
+	return nil
+}
+
+func validateFeedback(event models.FeedbackEvent) error {
+	if event.Query == "" || utf8.RuneCountInString(event.Query) > maxLoggedQueryLen {
+		return fmt.Errorf("query must be between 1 and %d characters", maxLoggedQueryLen)
+	}
+	if event.ProductID == "" {
+		return fmt.Errorf("product_id is required")
+	}
+	// без сессии повторы и лимиты не посчитать
+	if event.Session == "" || utf8.RuneCountInString(event.Session) > maxSessionLen {
+		return fmt.Errorf("session must be between 1 and %d characters", maxSessionLen)
+	}
+	if event.Position < 1 {
+		return fmt.Errorf("position must be positive")
+	}
+	if event.Type != models.FeedbackImpression && event.Type != models.FeedbackClick {
+		return fmt.Errorf("type must be impression or click")
+	}
+	if event.Ranking != "" && event.Ranking != models.RankingBaseline && event.Ranking != models.RankingBoosted {
+		return fmt.Errorf("ranking must be baseline or boosted")
+	}
+	return nil
+}
+
+// Run записывает накопленные счетчики до отмены ctx, последнюю пачку - после нее
+func (f *Feedback) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.flushInterval)
+	defer ticker.Stop()
+
+	stats := make(map[models.FeedbackKey]models.FeedbackStat)
+	for {
+		select {
+		case event := <-f.events:
+			for _, key := range []models.FeedbackKey{
+				{Query: event.Query, ProductID: event.ProductID},
+				{ProductID: event.ProductID},
+			} {
+				stat := stats[key]
+				if event.Type == models.FeedbackClick {
+					stat.Clicks++
+				} else {
+					stat.Impressions++
+				}
+				stats[key] = stat
+			}
+		case <-ticker.C:
+			stats = f.flush(stats)
+			f.guard.expire(time.Now().UTC())
+		case <-ctx.Done():
+			f.flush(stats)
+			return
+		}
+	}
+}
+
+// flush возвращает счетчики, которые нужно копить дальше, как QueryLog.flush
+func (f *Feedback) flush(stats map[models.FeedbackKey]models.FeedbackStat) map[models.FeedbackKey]models.FeedbackStat {
+	if len(stats) == 0 {
+		return stats
+	}
+	if err := f.repo.RecordFeedback(stats); err != nil {
+		log.Printf("Failed to write feedback counters: %s", err)
+		if len(stats) < feedbackBuffer {
+			return stats
+		}
+	}
+	return make(map[models.FeedbackKey]models.FeedbackStat)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"searchservice/internal/models"
+)
+
+// maxFeedbackGuardEntries - сколько пар сессия-товар помнит feedbackGuard. Когда их больше,
+// новые события не учитываются до очистки: память не растет от потока случайных сессий.
+const maxFeedbackGuardEntries = 200000
+
+// FeedbackLimits - сколько событий сессии учитывается за Window. Показ и клик товара
+// по запросу учитываются один раз за Window, всего за Window сессия дает не больше
+// SessionClicks кликов и SessionImpressions показов.
+type FeedbackLimits struct {
+	Window             time.Duration
+	SessionClicks      int
+	SessionImpressions int
+}
+
+type feedbackSeenKey struct {
+	session   string
+	query     string
+	productID string
+	eventType string
+}
+
+type sessionFeedback struct {
+	start       time.Time
+	clicks      int
+	impressions int
+}
+
+// feedbackGuard отбирает события, которые попадают в топик и счетчики. Лишние события
+// отбрасываются молча: клиент не отличает их от принятых.
+type feedbackGuard struct {
+	mu       sync.Mutex
+	limits   FeedbackLimits
+	seen     map[feedbackSeenKey]time.Time
+	sessions map[string]*sessionFeedback
+}
+
+func newFeedbackGuard(limits FeedbackLimits) *feedbackGuard {
+	return &feedbackGuard{
+		limits:   limits,
+		seen:     make(map[feedbackSeenKey]time.Time),
+		sessions: make(map[string]*sessionFeedback),
+	}
+}
+
+// accept возвращает события, которые нужно учесть, в том же порядке
+func (g *feedbackGuard) accept(events []models.FeedbackEvent, now time.Time) []models.FeedbackEvent {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	accepted := events[:0:0]
+	for _, event := range events {
+		key := feedbackSeenKey{session: event.Session, query: event.Query, productID: event.ProductID, eventType: event.Type}
+		if at, ok := g.seen[key]; ok && now.Sub(at) < g.limits.Window {
+			continue
+		}
+		if len(g.seen) >= maxFeedbackGuardEntries {
+			continue
+		}
+
+		session, ok := g.sessions[event.Session]
+		if !ok || now.Sub(session.start) >= g.limits.Window {
+			session = &sessionFeedback{start: now}
+			g.sessions[event.Session] = session
+		}
+		if event.Type == models.FeedbackClick {
+			if session.clicks >= g.limits.SessionClicks {
+				continue
+			}
+			session.clicks++
+		} else {
+			if session.impressions >= g.limits.SessionImpressions {
+				continue
+			}
+			session.impressions++
+		}
+
+		g.seen[key] = now
+		accepted = append(accepted, event)
+	}
+	return accepted
+}
+
+// release возвращает лимиты событий, которые accept принял, но записать не удалось:
+// повтор запроса клиентом не должен считаться повтором события
+func (g *feedbackGuard) release(events []models.FeedbackEvent) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, event := range events {
+		delete(g.seen, feedbackSeenKey{session: event.Session, query: event.Query, productID: event.ProductID, eventType: event.Type})
+		session, ok := g.sessions[event.Session]
+		if !ok {
+			continue
+		}
+		if event.Type == models.FeedbackClick {
+			session.clicks = max(session.clicks-1, 0)
+		} else {
+			session.impressions = max(session.impressions-1, 0)
+		}
+	}
+}
+
+// expire забывает события и сессии старше окна
+func (g *feedbackGuard) expire(now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for key, at := range g.seen {
+		if now.Sub(at) >= g.limits.Window {
+			delete(g.seen, key)
+		}
+	}
+	for id, session := range g.sessions {
+		if now.Sub(session.start) >= g.limits.Window {
+			delete(g.sessions, id)
+		}
+	}
+}
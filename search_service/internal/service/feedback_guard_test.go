@@ -0,0 +1,138 @@
+package service
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"searchservice/internal/models"
+)
+
+var testFeedbackLimits = FeedbackLimits{Window: time.Hour, SessionClicks: 2, SessionImpressions: 3}
+
+func click(session, query, productID string) models.FeedbackEvent {
+	return models.FeedbackEvent{Session: session, Query: query, ProductID: productID, Type: models.FeedbackClick}
+}
+
+func impression(session, query, productID string) models.FeedbackEvent {
+	return models.FeedbackEvent{Session: session, Query: query, ProductID: productID, Type: models.FeedbackImpression}
+}
+
+func eventKeys(events []models.FeedbackEvent) []string {
+	keys := make([]string, 0, len(events))
+	for _, event := range events {
+		keys = append(keys, event.Type+":"+event.Session+":"+event.Query+":"+event.ProductID)
+	}
+	return keys
+}
+
+func TestFeedbackGuardAccept(t *testing.T) {
+	type batch struct {
+		at     time.Duration
+		events []models.FeedbackEvent
+		want   []string
+	}
+	tests := []struct {
+		name    string
+		batches []batch
+	}{
+		{"repeat in one request", []batch{
+			{0, []models.FeedbackEvent{click("s1", "shoes", "p1"), click("s1", "shoes", "p1")}, []string{"click:s1:shoes:p1"}},
+		}},
+		{"repeat in the window", []batch{
+			{0, []models.FeedbackEvent{click("s1", "shoes", "p1")}, []string{"click:s1:shoes:p1"}},
+			{30 * time.Minute, []models.FeedbackEvent{click("s1", "shoes", "p1")}, []string{}},
+		}},
+		{"repeat after the window", []batch{
+			{0, []models.FeedbackEvent{click("s1", "shoes", "p1")}, []string{"click:s1:shoes:p1"}},
+			{time.Hour, []models.FeedbackEvent{click("s1", "shoes", "p1")}, []string{"click:s1:shoes:p1"}},
+		}},
+		{"click and impression of one product", []batch{
+			{0, []models.FeedbackEvent{impression("s1", "shoes", "p1"), click("s1", "shoes", "p1")},
+				[]string{"impression:s1:shoes:p1", "click:s1:shoes:p1"}},
+		}},
+		{"same product by another query or session", []batch{
+			{0, []models.FeedbackEvent{click("s1", "shoes", "p1"), click("s1", "boots", "p1"), click("s2", "shoes", "p1")},
+				[]string{"click:s1:shoes:p1", "click:s1:boots:p1", "click:s2:shoes:p1"}},
+		}},
+		{"session click limit", []batch{
+			{0, []models.FeedbackEvent{click("s1", "shoes", "p1"), click("s1", "shoes", "p2")},
+				[]string{"click:s1:shoes:p1", "click:s1:shoes:p2"}},
+			{time.Minute, []models.FeedbackEvent{click("s1", "shoes", "p3"), click("s2", "shoes", "p3")},
+				[]string{"click:s2:shoes:p3"}},
+			{time.Hour, []models.FeedbackEvent{click("s1", "shoes", "p3")}, []string{"click:s1:shoes:p3"}},
+		}},
+		{"session impression limit does not touch clicks", []batch{
+			{0, []models.FeedbackEvent{
+				impression("s1", "shoes", "p1"), impression("s1", "shoes", "p2"),
+				impression("s1", "shoes", "p3"), impression("s1", "shoes", "p4"),
+				click("s1", "shoes", "p4"),
+			}, []string{"impression:s1:shoes:p1", "impression:s1:shoes:p2", "impression:s1:shoes:p3", "click:s1:shoes:p4"}},
+		}},
+	}
+
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := newFeedbackGuard(testFeedbackLimits)
+			for i, b := range tt.batches {
+				got := eventKeys(guard.accept(b.events, start.Add(b.at)))
+				if !slices.Equal(got, b.want) {
+					t.Fatalf("batch %d: accept() = %v, want %v", i, got, b.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFeedbackGuardRelease(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	guard := newFeedbackGuard(testFeedbackLimits)
+
+	events := []models.FeedbackEvent{click("s1", "shoes", "p1"), click("s1", "shoes", "p2")}
+	accepted := guard.accept(events, now)
+	if len(accepted) != 2 {
+		t.Fatalf("accepted %d events, want 2", len(accepted))
+	}
+
+	// запись в топик не удалась: повтор запроса клиентом должен пройти целиком
+	guard.release(accepted)
+	if got := guard.accept(events, now.Add(time.Second)); len(got) != 2 {
+		t.Errorf("accepted %d events after release, want 2", len(got))
+	}
+	if got := guard.accept([]models.FeedbackEvent{click("s1", "shoes", "p3")}, now.Add(time.Second)); len(got) != 0 {
+		t.Error("release gave back more clicks than were taken")
+	}
+}
+
+func TestFeedbackGuardExpire(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	guard := newFeedbackGuard(testFeedbackLimits)
+	guard.accept([]models.FeedbackEvent{click("s1", "shoes", "p1")}, now)
+	guard.accept([]models.FeedbackEvent{click("s2", "shoes", "p1")}, now.Add(30*time.Minute))
+
+	guard.expire(now.Add(time.Hour))
+	if len(guard.seen) != 1 || len(guard.sessions) != 1 {
+		t.Fatalf("after expire seen = %d, sessions = %d, want 1 and 1", len(guard.seen), len(guard.sessions))
+	}
+	if _, ok := guard.sessions["s2"]; !ok {
+		t.Error("live session was expired")
+	}
+}
+
+func TestFeedbackGuardEntriesLimit(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	guard := newFeedbackGuard(testFeedbackLimits)
+	for i := 0; i < maxFeedbackGuardEntries; i++ {
+		guard.seen[feedbackSeenKey{session: "s", productID: strconv.Itoa(i)}] = now
+	}
+
+	if got := guard.accept([]models.FeedbackEvent{click("s1", "shoes", "p1")}, now); len(got) != 0 {
+		t.Error("event accepted over the entries limit")
+	}
+	guard.expire(now.Add(time.Hour))
+	if got := guard.accept([]models.FeedbackEvent{click("s1", "shoes", "p1")}, now.Add(time.Hour)); len(got) != 1 {
+		t.Error("event is not accepted after expire")
+	}
+}
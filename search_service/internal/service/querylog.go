@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"hash/fnv"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"searchservice/internal/models"
+	"searchservice/internal/repository"
+)
+
+const (
+	// maxLoggedQueryLen - более длинные запросы не подсказываются, их не пишем
+	maxLoggedQueryLen = 100
+	queryLogBuffer    = 1024
+)
+
+type queryRecord struct {
+	query   string
+	results int64
+	session string
+	at      time.Time
+}
+
+// QueryLog собирает запросы /search для подсказок популярных запросов. Запросы копятся
+// в памяти и раз в flushInterval записываются одним _bulk, так что поиск не ждет записи.
+// Подсказывается запрос, который искали хотя бы minSessions разных сессий: запрос одного
+// клиента, сколько бы раз он его ни повторил, в подсказки не попадает. Запросы со словами
+// из blocklist не записываются и не подсказываются.
+type QueryLog struct {
+	repo          repository.SearchRepository
+	records       chan queryRecord
+	flushInterval time.Duration
+	minSessions   int
+	blocklist     *Blocklist
+}
+
+func NewQueryLog(repo repository.SearchRepository, flushInterval time.Duration, minSessions int, blocklist *Blocklist) *QueryLog {
+	return &QueryLog{
+		repo:          repo,
+		records:       make(chan queryRecord, queryLogBuffer),
+		flushInterval: flushInterval,
+		minSessions:   minSessions,
+		blocklist:     blocklist,
+	}
+}
+
+// Record учитывает запрос сессии session, по которому нашлось results товаров. Запрос без
+// сессии считается, но к порогу сессий не приближает. Если очередь переполнена, запрос
+// теряется: счетчики популярности приблизительные.
+func (ql *QueryLog) Record(query, session string, results int64) {
+	query = normalizeQuery(query)
+	if query == "" || utf8.RuneCountInString(query) > maxLoggedQueryLen || ql.blocklist.Blocked(query) {
+		return
+	}
+
+	select {
+	case ql.records <- queryRecord{query: query, results: results, session: hashSession(session), at: time.Now()}:
+	default:
+	}
+}
+
+// Allowed - запрос можно подсказать. Запросы, записанные до того, как слово попало
+// в blocklist, отсекаются здесь.
+func (ql *QueryLog) Allowed(query string) bool {
+	return !ql.blocklist.Blocked(normalizeQuery(query))
+}
+
+// hashSession - в журнале хранится не идентификатор клиента, а его хеш
+func hashSession(session string) string {
+	if session == "" {
+		return ""
+	}
+	h := fnv.New64a()
+	h.Write([]byte(session))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Run записывает накопленные запросы до отмены ctx, последнюю пачку - после нее
+func (ql *QueryLog) Run(ctx context.Context) {
+	ticker := time.NewTicker(ql.flushInterval)
+	defer ticker.Stop()
+
+	stats := make(map[string]models.QueryStat)
+	for {
+		select {
+		case r := <-ql.records:
+			stat := stats[r.query]
+			stat.Count++
+			stat.Results = r.results
+			stat.LastSeen = r.at
+			if r.session != "" && len(stat.Sessions) < models.MaxQuerySessions && !slices.Contains(stat.Sessions, r.session) {
+				stat.Sessions = append(stat.Sessions, r.session)
+			}
+			stats[r.query] = stat
+		case <-ticker.C:
+			stats = ql.flush(stats)
+		case <-ctx.Done():
+			ql.flush(stats)
+			return
+		}
+	}
+}
+
+// flush возвращает счетчики, которые нужно копить дальше: при ошибке записи они не теряются,
+// пока их не больше буфера
+func (ql *QueryLog) flush(stats map[string]models.QueryStat) map[string]models.QueryStat {
+	if len(stats) == 0 {
+		return stats
+	}
+	if err := ql.repo.RecordQueries(stats); err != nil {
+		log.Printf("Failed to write query log: %s", err)
+		if len(stats) < queryLogBuffer {
+			return stats
+		}
+	}
+	return make(map[string]models.QueryStat)
+}
+
+// normalizeQuery приводит запрос к виду, в котором он считается и подсказывается
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
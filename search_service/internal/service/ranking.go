@@ -0,0 +1,78 @@
+package service
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+
+	"searchservice/internal/models"
+	"searchservice/internal/repository"
+)
+
+// Априорный CTR: товар без кликов считается как 1 клик на 20 показов, чтобы пара
+// случайных кликов не поднимала его наверх
+const (
+	priorClicks      = 1.0
+	priorImpressions = 20.0
+	// ctrWeight - степень, в которой CTR товара по запросу относительно априорного умножает релевантность
+	ctrWeight = 1.0
+	// popularityWeight - вес логарифма кликов товара по всем запросам
+	popularityWeight = 0.1
+)
+
+// Ranker переупорядочивает страницу выдачи по релевантности elasticsearch, CTR товара
+// по запросу и его популярности. Меняется только порядок внутри страницы, поэтому
+// пагинация остается согласованной.
+type Ranker struct {
+	repo repository.FeedbackRepository
+	// boostShare - процент сессий, которые получают переранжированную выдачу
+	boostShare int
+}
+
+func NewRanker(repo repository.FeedbackRepository, boostShare int) *Ranker {
+	return &Ranker{repo: repo, boostShare: boostShare}
+}
+
+// Variant - вариант ранжирования сессии. Сессия всегда попадает в один и тот же вариант,
+// запросы без сессии получают базовый.
+func (r *Ranker) Variant(session string) string {
+	if r.boostShare <= 0 || (session == "" && r.boostShare < 100) {
+		return models.RankingBaseline
+	}
+	if r.boostShare >= 100 {
+		return models.RankingBoosted
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(session))
+	if int(h.Sum32()%100) < r.boostShare {
+		return models.RankingBoosted
+	}
+	return models.RankingBaseline
+}
+
+// Rerank сортирует hits по смешанной оценке. При ошибке порядок не меняется.
+func (r *Ranker) Rerank(query string, hits []models.SearchHit) error {
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ProductID)
+	}
+	stats, err := r.repo.FeedbackStats(query, ids)
+	if err != nil {
+		return err
+	}
+
+	scores := make(map[string]float64, len(hits))
+	for _, hit := range hits {
+		byQuery := stats[models.FeedbackKey{Query: query, ProductID: hit.ProductID}]
+		total := stats[models.FeedbackKey{ProductID: hit.ProductID}]
+
+		ctr := (float64(byQuery.Clicks) + priorClicks) / (float64(byQuery.Impressions) + priorImpressions)
+		lift := ctr / (priorClicks / priorImpressions)
+		popularity := 1 + popularityWeight*math.Log1p(float64(total.Clicks))
+		scores[hit.ProductID] = hit.Score * math.Pow(lift, ctrWeight) * popularity
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return scores[hits[i].ProductID] > scores[hits[j].ProductID] })
+	return nil
+}
@@ -1,30 +1,173 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"slices"
 
 	"searchservice/internal/models"
 	"searchservice/internal/repository"
 )
 
 type ProductService struct {
-	repo *repository.ElasticRepository
+	repo    repository.SearchRepository
+	queries *QueryLog
+	ranker  *Ranker
 }
 
-func NewProductService(repo *repository.ElasticRepository) *ProductService {
-	return &ProductService{repo: repo}
+// NewProductService - сервис товаров. С queries == nil запросы /search не записываются,
+// с ranker == nil выдача не переранжируется.
+func NewProductService(repo repository.SearchRepository, queries *QueryLog, ranker *Ranker) *ProductService {
+	return &ProductService{repo: repo, queries: queries, ranker: ranker}
 }
 
-func (ps *ProductService) HandleNewProduct(prod *models.Product) {
-	if prod.ProductID == "" {
-		log.Printf("Skipping product with empty ID: %#v\n", prod)
-		return
+// HandleProductEvents применяет пачку событий топика products к индексу и возвращает ошибки
+// в порядке событий. upsert и delete уходят одним запросом _bulk, patch читает текущий документ
+// и применяется после него. События с версией не больше уже проиндексированной пропускаются.
+// Ошибка, обернутая в models.ErrInvalidEvent или repository.ErrDocumentRejected, не исправится
+// повтором, остальные ошибки индекса временные.
+func (ps *ProductService) HandleProductEvents(events []*models.ProductEvent) []error {
+	errs := make([]error, len(events))
+	ops := make([]repository.BulkOperation, 0, len(events))
+	opEvents := make([]int, 0, len(events))
+	var patches []int
+
+	for i, event := range events {
+		if event.ProductID == "" || event.Version <= 0 {
+			errs[i] = fmt.Errorf("%w: event without product ID or version", models.ErrInvalidEvent)
+			continue
+		}
+
+		switch event.Type {
+		case models.EventUpsert:
+			if event.Product == nil {
+				errs[i] = fmt.Errorf("%w: upsert without product", models.ErrInvalidEvent)
+				continue
+			}
+			product := *event.Product
+			product.ProductID = event.ProductID
+			if product.UpdatedAt.IsZero() {
+				product.UpdatedAt = event.OccurredAt
+			}
+			ops = append(ops, repository.BulkOperation{
+				Action:    repository.BulkIndex,
+				ProductID: event.ProductID,
+				Version:   event.Version,
+				Product:   &product,
+			})
+			opEvents = append(opEvents, i)
+		case models.EventDelete:
+			ops = append(ops, repository.BulkOperation{
+				Action:    repository.BulkDelete,
+				ProductID: event.ProductID,
+				Version:   event.Version,
+			})
+			opEvents = append(opEvents, i)
+		case models.EventPatch:
+			patches = append(patches, i)
+		default:
+			errs[i] = fmt.Errorf("%w: unknown event type %q", models.ErrInvalidEvent, event.Type)
+		}
+	}
+
+	if len(ops) > 0 {
+		results, err := ps.repo.Bulk(ops)
+		for j, i := range opEvents {
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			errs[i] = eventResult(events[i], results[j])
+		}
 	}
-	if err := ps.repo.IndexProduct(prod); err != nil {
-		log.Printf("Failed to index product ID=%s: %s", prod.ProductID, err)
+
+	for _, i := range patches {
+		errs[i] = eventResult(events[i], ps.patch(events[i]))
 	}
+
+	return errs
 }
 
-func (ps *ProductService) Search(q string) ([]string, error) {
-	return ps.repo.SearchProductIDs(q)
+func eventResult(event *models.ProductEvent, err error) error {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		log.Printf("Skipping stale %s event for product ID=%s version=%d\n", event.Type, event.ProductID, event.Version)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("apply %s event for product ID=%s version=%d: %w", event.Type, event.ProductID, event.Version, err)
+	}
+	return nil
+}
+
+// patch меняет поля документа, который уже есть в индексе. Патч к товару, которого
+// в индексе нет, пропускается: документ целиком придет со следующим upsert.
+func (ps *ProductService) patch(event *models.ProductEvent) error {
+	if event.Patch == nil {
+		return fmt.Errorf("%w: patch without fields", models.ErrInvalidEvent)
+	}
+
+	product, version, err := ps.repo.GetProduct(event.ProductID)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		log.Printf("Skipping patch for product ID=%s missing in index\n", event.ProductID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if version >= event.Version {
+		return repository.ErrVersionConflict
+	}
+
+	event.Patch.Apply(product)
+	product.UpdatedAt = event.OccurredAt
+	// между чтением и записью документ мог обновиться, тогда запись вернет ErrVersionConflict
+	return ps.repo.IndexProduct(product, event.Version)
+}
+
+// Search ищет товары. Запрос латиницей дополнительно ищется в кириллической транслитерации:
+// английские названия находятся по исходному тексту, "krossovki" - по "кроссовки".
+func (ps *ProductService) Search(params models.SearchParams) (models.SearchResult, error) {
+	params.TranslitQuery = transliterate(params.Query)
+	result, err := ps.repo.Search(params)
+	if err != nil {
+		return result, err
+	}
+
+	result.Ranking = models.RankingBaseline
+	if n := len(result.Hits); n > 0 {
+		result.LastSort = result.Hits[n-1].Sort
+	}
+	if ps.ranker != nil && params.Query != "" && params.Sort == models.SortRelevance &&
+		ps.ranker.Variant(params.Session) == models.RankingBoosted {
+		if err := ps.ranker.Rerank(normalizeQuery(params.Query), result.Hits); err != nil {
+			log.Printf("Failed to rerank search results, keeping baseline order: %s", err)
+		} else {
+			result.Ranking = models.RankingBoosted
+		}
+	}
+
+	// популярность считаем по первым страницам: листание не повторяет запрос
+	if ps.queries != nil && params.Query != "" && params.From == 0 && len(params.SearchAfter) == 0 {
+		ps.queries.Record(params.Query, params.Session, result.Total)
+	}
+	return result, nil
+}
+
+// Suggest - подсказки к началу запроса: названия товаров, теги и популярные запросы
+func (ps *ProductService) Suggest(params models.SuggestParams) (models.Suggestions, error) {
+	params.TranslitPrefix = transliterate(params.Prefix)
+	if ps.queries == nil {
+		return ps.repo.Suggest(params)
+	}
+
+	params.MinSessions = ps.queries.minSessions
+	suggestions, err := ps.repo.Suggest(params)
+	if err != nil {
+		return suggestions, err
+	}
+	suggestions.Queries = slices.DeleteFunc(suggestions.Queries, func(query string) bool {
+		return !ps.queries.Allowed(query)
+	})
+	return suggestions, nil
 }
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"searchservice/internal/models"
+	"searchservice/internal/repository"
+)
+
+// decodeEvents разбирает сообщения топика products так же, как KafkaConsumer
+func decodeEvents(t *testing.T, messages ...string) []*models.ProductEvent {
+	t.Helper()
+
+	events := make([]*models.ProductEvent, 0, len(messages))
+	for _, message := range messages {
+		var event models.ProductEvent
+		if err := json.Unmarshal([]byte(message), &event); err != nil {
+			t.Fatalf("unmarshal %s: %s", message, err)
+		}
+		events = append(events, &event)
+	}
+	return events
+}
+
+// handle применяет пачку и проверяет, что ошибки стоят на местах своих событий:
+// wantInvalid - номера событий, которые должны вернуть models.ErrInvalidEvent
+func handle(t *testing.T, ps *ProductService, events []*models.ProductEvent, wantInvalid ...int) {
+	t.Helper()
+
+	errs := ps.HandleProductEvents(events)
+	if len(errs) != len(events) {
+		t.Fatalf("HandleProductEvents returned %d errors for %d events", len(errs), len(events))
+	}
+	for i, err := range errs {
+		if slices.Contains(wantInvalid, i) {
+			if !errors.Is(err, models.ErrInvalidEvent) {
+				t.Errorf("event %d error = %v, want ErrInvalidEvent", i, err)
+			}
+		} else if err != nil {
+			t.Errorf("event %d error = %v, want nil", i, err)
+		}
+	}
+}
+
+func getProduct(t *testing.T, repo repository.SearchRepository, id string) (*models.Product, int64) {
+	t.Helper()
+
+	product, version, err := repo.GetProduct(id)
+	if err != nil {
+		t.Fatalf("GetProduct(%s): %s", id, err)
+	}
+	return product, version
+}
+
+func TestHandleProductEventsEnvelope(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	ps := NewProductService(repo, nil, nil)
+
+	// сообщение в том виде, в каком его пишет products_service (events.Marshal)
+	handle(t, ps, decodeEvents(t, `{
+		"type": "upsert", "product_id": "p1", "version": 1, "occurred_at": "2025-05-01T12:00:00Z",
+		"product": {
+			"product_id": "p1", "name": "Кроссовки Nike Air", "description": "Беговые", "tags": ["обувь"],
+			"seller": "s1", "price": 7000, "image_url": "https://img/p1.jpg",
+			"info": {"sizes": ["XL ", 42, "xl"], "color": "white"},
+			"created_at": "2025-04-01T00:00:00Z", "updated_at": "2025-05-01T11:59:00Z"
+		}
+	}`))
+
+	product, version := getProduct(t, repo, "p1")
+	if version != 1 {
+		t.Errorf("version = %d, want 1", version)
+	}
+	if product.Name != "Кроссовки Nike Air" || product.Seller != "s1" || product.Price != 7000 {
+		t.Errorf("product = %+v", product)
+	}
+	if want := []string{"xl", "42"}; !slices.Equal(product.Sizes, want) {
+		t.Errorf("sizes = %v, want %v", product.Sizes, want)
+	}
+	if want := time.Date(2025, 5, 1, 11, 59, 0, 0, time.UTC); !product.UpdatedAt.Equal(want) {
+		t.Errorf("updated_at = %s, want %s", product.UpdatedAt, want)
+	}
+
+	// без updated_at товара время изменения берется из события
+	handle(t, ps, decodeEvents(t, `{"type": "upsert", "product_id": "p2", "version": 1,
+		"occurred_at": "2025-05-02T00:00:00Z", "product": {"name": "Футболка"}}`))
+	product, _ = getProduct(t, repo, "p2")
+	if want := time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC); product.ProductID != "p2" || !product.UpdatedAt.Equal(want) {
+		t.Errorf("p2 = %q updated at %s, want p2 updated at %s", product.ProductID, product.UpdatedAt, want)
+	}
+}
+
+func TestHandleProductEventsVersions(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	ps := NewProductService(repo, nil, nil)
+
+	// в одной пачке более новая версия пришла раньше: старая пропускается без ошибки
+	handle(t, ps, decodeEvents(t,
+		`{"type": "upsert", "product_id": "p1", "version": 2, "product": {"name": "Вторая"}}`,
+		`{"type": "upsert", "product_id": "p1", "version": 1, "product": {"name": "Первая"}}`,
+	))
+	if product, version := getProduct(t, repo, "p1"); product.Name != "Вторая" || version != 2 {
+		t.Errorf("p1 = %q version %d, want %q version 2", product.Name, version, "Вторая")
+	}
+
+	// повтор той же версии (например, после перезапуска консьюмера) ничего не меняет
+	handle(t, ps, decodeEvents(t, `{"type": "upsert", "product_id": "p1", "version": 2, "product": {"name": "Повтор"}}`))
+	if product, _ := getProduct(t, repo, "p1"); product.Name != "Вторая" {
+		t.Errorf("p1 name = %q after repeated version, want %q", product.Name, "Вторая")
+	}
+
+	// patch применяется к текущему документу, устаревший patch пропускается
+	handle(t, ps, decodeEvents(t,
+		`{"type": "patch", "product_id": "p1", "version": 3, "occurred_at": "2025-05-03T00:00:00Z", "patch": {"price": 500}}`,
+		`{"type": "patch", "product_id": "p1", "version": 3, "patch": {"name": "Устаревшая"}}`,
+		`{"type": "patch", "product_id": "missing", "version": 5, "patch": {"price": 1}}`,
+	))
+	product, version := getProduct(t, repo, "p1")
+	if product.Name != "Вторая" || product.Price != 500 || version != 3 {
+		t.Errorf("p1 = %q price %d version %d, want %q price 500 version 3", product.Name, product.Price, version, "Вторая")
+	}
+	if want := time.Date(2025, 5, 3, 0, 0, 0, 0, time.UTC); !product.UpdatedAt.Equal(want) {
+		t.Errorf("p1 updated_at = %s, want %s", product.UpdatedAt, want)
+	}
+	if _, _, err := repo.GetProduct("missing"); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("patch created a missing product: %v", err)
+	}
+
+	// удаление, за которым пришел upsert с более старой версией: товар не возвращается
+	handle(t, ps, decodeEvents(t, `{"type": "delete", "product_id": "p1", "version": 5}`))
+	handle(t, ps, decodeEvents(t,
+		`{"type": "upsert", "product_id": "p1", "version": 4, "product": {"name": "Воскресшая"}}`,
+		`{"type": "patch", "product_id": "p1", "version": 4, "patch": {"price": 1}}`,
+	))
+	if _, _, err := repo.GetProduct("p1"); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("deleted p1 is back in the index: %v", err)
+	}
+
+	// удаление товара, которого нет в индексе, - не ошибка
+	handle(t, ps, decodeEvents(t, `{"type": "delete", "product_id": "unknown", "version": 1}`))
+}
+
+func TestHandleProductEventsInvalid(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	ps := NewProductService(repo, nil, nil)
+
+	handle(t, ps, decodeEvents(t,
+		`{"type": "upsert", "product_id": "p1", "product": {"name": "Без версии"}}`,
+		`{"type": "upsert", "version": 1, "product": {"name": "Без id"}}`,
+		`{"type": "upsert", "product_id": "p2", "version": 1}`,
+		`{"type": "rename", "product_id": "p3", "version": 1}`,
+		`{"type": "patch", "product_id": "p4", "version": 2}`,
+		`{"type": "upsert", "product_id": "p5", "version": 1, "product": {"name": "Правильный"}}`,
+	), 0, 1, 2, 3, 4)
+
+	// ошибочные события не мешают остальным событиям пачки
+	if product, _ := getProduct(t, repo, "p5"); product.Name != "Правильный" {
+		t.Errorf("p5 name = %q", product.Name)
+	}
+}
+
+// failingBulkRepository - индекс, который не принимает _bulk целиком
+type failingBulkRepository struct {
+	*repository.MemoryRepository
+	err error
+}
+
+func (r *failingBulkRepository) Bulk([]repository.BulkOperation) ([]error, error) {
+	return nil, r.err
+}
+
+func TestHandleProductEventsBulkError(t *testing.T) {
+	repo := &failingBulkRepository{MemoryRepository: repository.NewMemoryRepository(), err: errors.New("elasticsearch is unavailable")}
+	if err := repo.IndexProduct(&models.Product{ProductID: "p3", Name: "Куртка"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	ps := NewProductService(repo, nil, nil)
+
+	errs := ps.HandleProductEvents(decodeEvents(t,
+		`{"type": "upsert", "product_id": "p1", "version": 1, "product": {"name": "Кроссовки"}}`,
+		`{"type": "delete", "product_id": "p2", "version": 2}`,
+		`{"type": "patch", "product_id": "p3", "version": 2, "patch": {"price": 900}}`,
+	))
+
+	// события _bulk получают временную ошибку и повторяются, patch от _bulk не зависит
+	for i := 0; i < 2; i++ {
+		if !errors.Is(errs[i], repo.err) || errors.Is(errs[i], models.ErrInvalidEvent) {
+			t.Errorf("event %d error = %v, want %v", i, errs[i], repo.err)
+		}
+	}
+	if errs[2] != nil {
+		t.Errorf("patch error = %v, want nil", errs[2])
+	}
+	if product, _ := getProduct(t, repo, "p3"); product.Price != 900 {
+		t.Errorf("p3 price = %d, want 900", product.Price)
+	}
+}
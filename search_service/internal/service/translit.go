@@ -0,0 +1,40 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+// translitPairs - латиница в кириллицу по распространенной транслитерации. Длинные сочетания
+// идут первыми, чтобы "shch" не разобралось как "s" + "h" + ...
+var translitPairs = []string{
+	"shch", "щ", "sch", "щ",
+	"zh", "ж", "kh", "х", "ts", "ц", "ch", "ч", "sh", "ш",
+	"yu", "ю", "ju", "ю", "ya", "я", "ja", "я", "yo", "е", "jo", "е", "ye", "е",
+	"a", "а", "b", "б", "v", "в", "w", "в", "g", "г", "d", "д", "e", "е",
+	"z", "з", "i", "и", "y", "ы", "j", "й", "k", "к", "l", "л", "m", "м",
+	"n", "н", "o", "о", "p", "п", "r", "р", "s", "с", "t", "т", "u", "у",
+	"f", "ф", "h", "х", "c", "ц", "q", "к", "x", "кс",
+}
+
+var translitReplacer = strings.NewReplacer(translitPairs...)
+
+// transliterate переводит запрос, набранный транслитом, в кириллицу: "krossovki" -> "кроссовки".
+// Возвращает пустую строку, если в запросе нет латиницы или уже есть кириллица - такой запрос
+// ищется как есть.
+func transliterate(query string) string {
+	hasLatin := false
+	for _, r := range query {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return ""
+		}
+		if r < unicode.MaxASCII && unicode.IsLetter(r) {
+			hasLatin = true
+		}
+	}
+	if !hasLatin {
+		return ""
+	}
+
+	return translitReplacer.Replace(strings.ToLower(query))
+}
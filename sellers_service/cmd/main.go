@@ -1,12 +1,35 @@
 package main
 
 import (
+	"grpcsec/sellerauth"
 	"log"
 	"sellers_service/internal/api"
+	"sellers_service/internal/config"
+	"sellers_service/internal/identity"
+	"sellers_service/internal/repository"
 )
 
 func main() {
-	if err := api.RunSellersServer(":50052"); err != nil {
+	cfg := config.New()
+
+	verifier, err := sellerauth.New(cfg.AccessTokenSecret)
+	if err != nil {
+		log.Fatalf("Failed to init seller token verification: %v", err)
+	}
+
+	repo, err := repository.New(cfg.DB)
+	if err != nil {
+		log.Fatalf("Failed to init sellers storage: %v", err)
+	}
+	defer repo.Close()
+
+	identityClient, err := identity.New(cfg.AuthAddr)
+	if err != nil {
+		log.Fatalf("Failed to create auth_service client: %v", err)
+	}
+	defer identityClient.Close()
+
+	if err := api.RunSellersServer(cfg.GRPCAddr, api.NewServer(repo, identityClient, verifier, cfg.SyncTTL)); err != nil {
 		log.Fatalf("Failed to start SellersService: %v", err)
 	}
 }
@@ -3,14 +3,25 @@ module sellers_service
 go 1.24.2
 
 require (
-	github.com/artemSorokin1/sellers-grpc-api v0.0.1
+	github.com/artemSorokin1/sellers-grpc-api v0.1.0
+	github.com/golang-migrate/migrate v3.5.4+incompatible
+	github.com/google/uuid v1.6.0
+	github.com/jmoiron/sqlx v1.4.0
+	github.com/lib/pq v1.10.9
 	google.golang.org/grpc v1.72.2
 	google.golang.org/protobuf v1.36.6
+	grpcsec v0.0.0
 )
 
 require (
+	github.com/golang-jwt/jwt/v5 v5.2.2 // indirect
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
 )
+
+replace grpcsec => ../grpcsec
+
+// api генерируется в репозитории из sellers_service/api/seller.proto, см. proto/generate.sh
+replace github.com/artemSorokin1/sellers-grpc-api => ../proto/sellers-grpc-api
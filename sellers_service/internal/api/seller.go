@@ -2,53 +2,363 @@ package api
 
 import (
 	"context"
+	"errors"
+	"grpcsec"
+	"grpcsec/sellerauth"
 	"log"
 	"net"
+	"sellers_service/internal/identity"
+	"sellers_service/internal/models"
+	"sellers_service/internal/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	// импорт сгенерированного SellersService
 	pb "github.com/artemSorokin1/sellers-grpc-api/gen/go/seller"
 )
 
-// server реализует sгенерированный интерфейс SellersServiceServer.
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+	maxBatchSize     = 100
+	maxFieldLen      = 255
+)
+
+// server реализует сгенерированный интерфейс SellersServiceServer.
+//
+// Контакты продавца хранит этот сервис, а существует ли аккаунт продавца, решает auth_service:
+// карточка, которую не сверяли с ним дольше syncTTL, перед выдачей сверяется заново.
 type server struct {
 	pb.UnimplementedSellersServiceServer
-	// сюда можно положить соединение с БД
+	repo     *repository.Repository
+	identity *identity.Client
+	sellers  *sellerauth.Verifier
+	syncTTL  time.Duration
 }
 
 // NewServer возвращает экземпляр сервера Sellers.
-func NewServer() *server {
-	return &server{}
+func NewServer(repo *repository.Repository, identity *identity.Client, sellers *sellerauth.Verifier, syncTTL time.Duration) *server {
+	return &server{repo: repo, identity: identity, sellers: sellers, syncTTL: syncTTL}
 }
 
 // GetSeller — реализация RPC GetSeller(GetSellerRequest) → (GetSellerResponse)
 func (s *server) GetSeller(ctx context.Context, req *pb.GetSellerRequest) (*pb.GetSellerResponse, error) {
-	log.Printf("SellersService.GetSeller: запрос id=%s", req.Id)
+	log.Printf("SellersService.GetSeller: запрос id=%s", req.GetId())
+
+	if _, err := uuid.Parse(req.GetId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid seller id")
+	}
+
+	seller, err := s.getOrImport(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetSellerResponse{Seller: toProto(seller)}, nil
+}
+
+// CreateSeller заводит карточку продавцу, от имени которого вызывает шлюз. Аккаунт продавца
+// должен быть в auth_service.
+func (s *server) CreateSeller(ctx context.Context, req *pb.CreateSellerRequest) (*pb.CreateSellerResponse, error) {
+	log.Printf("SellersService.CreateSeller: запрос id=%s", req.GetId())
+
+	sellerId, err := s.callerSeller(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	seller := models.Seller{
+		ID:       sellerId,
+		Phone:    strings.TrimSpace(req.GetPhone()),
+		Email:    strings.TrimSpace(req.GetEmail()),
+		FullName: strings.TrimSpace(req.GetFullname()),
+		City:     strings.TrimSpace(req.GetCity()),
+	}
+	if err := validate(seller); err != nil {
+		return nil, err
+	}
+
+	account, err := s.identity.GetSeller(ctx, seller.ID)
+	if err != nil {
+		return nil, identityError(seller.ID, err)
+	}
+	seller.ID = account.ID
+
+	created, err := s.repo.Create(ctx, seller)
+	if err != nil {
+		if errors.Is(err, repository.ErrSellerExists) {
+			return nil, status.Error(codes.AlreadyExists, "seller already exists")
+		}
+		log.Printf("SellersService.CreateSeller: ошибка сохранения: %v", err)
+		return nil, status.Error(codes.Internal, "unable to create seller")
+	}
+
+	return &pb.CreateSellerResponse{Seller: toProto(created)}, nil
+}
+
+// UpdateSeller заменяет контакты продавца, от имени которого вызывает шлюз. Продавец,
+// которого еще нет в базе, сначала переносится из auth_service.
+func (s *server) UpdateSeller(ctx context.Context, req *pb.UpdateSellerRequest) (*pb.UpdateSellerResponse, error) {
+	log.Printf("SellersService.UpdateSeller: запрос id=%s", req.GetId())
+
+	sellerId, err := s.callerSeller(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	seller := models.Seller{
+		ID:       sellerId,
+		Phone:    strings.TrimSpace(req.GetPhone()),
+		Email:    strings.TrimSpace(req.GetEmail()),
+		FullName: strings.TrimSpace(req.GetFullname()),
+		City:     strings.TrimSpace(req.GetCity()),
+	}
+	if err := validate(seller); err != nil {
+		return nil, err
+	}
+
+	current, err := s.getOrImport(ctx, seller.ID)
+	if err != nil {
+		return nil, err
+	}
+	seller.ID = current.ID
 
-	// Здесь вместо заглушки должен быть запрос к БД по req.Id
-	seller := &pb.Seller{
-		Id:        req.Id,
-		Phone:     "+7 123 456-78-90",
-		Email:     "seller@example.com",
-		Fullname:  "Иван Петров",
-		CreatedAt: timestamppb.Now(),
+	updated, err := s.repo.Update(ctx, seller)
+	if err != nil {
+		if errors.Is(err, repository.ErrSellerNotFound) {
+			return nil, status.Error(codes.NotFound, "seller not found")
+		}
+		log.Printf("SellersService.UpdateSeller: ошибка сохранения: %v", err)
+		return nil, status.Error(codes.Internal, "unable to update seller")
 	}
 
-	return &pb.GetSellerResponse{
-		Seller: seller,
-	}, nil
+	return &pb.UpdateSellerResponse{Seller: toProto(updated)}, nil
+}
+
+func (s *server) ListSellers(ctx context.Context, req *pb.ListSellersRequest) (*pb.ListSellersResponse, error) {
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit < 0 || limit > maxListLimit {
+		return nil, status.Error(codes.InvalidArgument, "limit must be between 1 and 100")
+	}
+	if req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+	}
+
+	sellers, total, err := s.repo.List(ctx, strings.TrimSpace(req.GetCity()), limit, int(req.GetOffset()))
+	if err != nil {
+		log.Printf("SellersService.ListSellers: ошибка чтения: %v", err)
+		return nil, status.Error(codes.Internal, "unable to list sellers")
+	}
+
+	resp := &pb.ListSellersResponse{
+		Sellers: make([]*pb.Seller, 0, len(sellers)),
+		Total:   total,
+	}
+	for _, seller := range sellers {
+		resp.Sellers = append(resp.Sellers, toProto(seller))
+	}
+
+	return resp, nil
+}
+
+// BatchGetSellers - карточки продавцов для списка товаров одним запросом.
+// Продавцы, которых нет в базе, подтягиваются из auth_service.
+func (s *server) BatchGetSellers(ctx context.Context, req *pb.BatchGetSellersRequest) (*pb.BatchGetSellersResponse, error) {
+	ids := make([]string, 0, len(req.GetIds()))
+	seen := make(map[string]bool, len(req.GetIds()))
+	for _, id := range req.GetIds() {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid seller id %q", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > maxBatchSize {
+		return nil, status.Error(codes.InvalidArgument, "too many seller ids, max 100")
+	}
+
+	sellers, err := s.repo.BatchGet(ctx, ids)
+	if err != nil {
+		log.Printf("SellersService.BatchGetSellers: ошибка чтения: %v", err)
+		return nil, status.Error(codes.Internal, "unable to get sellers")
+	}
+
+	found := make(map[string]models.Seller, len(sellers))
+	for _, seller := range sellers {
+		found[seller.ID] = seller
+	}
+
+	resp := &pb.BatchGetSellersResponse{Sellers: make([]*pb.Seller, 0, len(ids))}
+	for _, id := range ids {
+		seller, ok := found[id]
+		if ok {
+			seller, err = s.sync(ctx, seller)
+		} else {
+			seller, err = s.getOrImport(ctx, id)
+		}
+		if status.Code(err) == codes.NotFound {
+			resp.MissingIds = append(resp.MissingIds, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp.Sellers = append(resp.Sellers, toProto(seller))
+	}
+
+	return resp, nil
+}
+
+// getOrImport ищет продавца в базе, а если его там нет - в auth_service, и сохраняет найденного.
+// Ошибки уже переведены в grpc статусы.
+func (s *server) getOrImport(ctx context.Context, id string) (models.Seller, error) {
+	seller, err := s.repo.Get(ctx, id)
+	if err == nil {
+		return s.sync(ctx, seller)
+	}
+	if !errors.Is(err, repository.ErrSellerNotFound) {
+		log.Printf("SellersService: ошибка чтения продавца %s: %v", id, err)
+		return models.Seller{}, status.Error(codes.Internal, "unable to get seller")
+	}
+
+	account, err := s.identity.GetSeller(ctx, id)
+	if err != nil {
+		return models.Seller{}, identityError(id, err)
+	}
+
+	seller, err = s.repo.Import(ctx, account)
+	if err != nil {
+		log.Printf("SellersService: ошибка переноса продавца %s: %v", id, err)
+		return models.Seller{}, status.Error(codes.Internal, "unable to get seller")
+	}
+
+	log.Printf("SellersService: продавец %s перенесен из auth_service", seller.ID)
+	return seller, nil
+}
+
+// sync сверяет с auth_service продавца, которого не сверяли дольше syncTTL. Карточку удаленного
+// аккаунта сервис удаляет. Если auth_service недоступен, отдается карточка из базы: контакты
+// хранит этот сервис, а сверка повторится при следующем запросе.
+func (s *server) sync(ctx context.Context, seller models.Seller) (models.Seller, error) {
+	if time.Since(seller.SyncedAt) < s.syncTTL {
+		return seller, nil
+	}
+
+	_, err := s.identity.GetSeller(ctx, seller.ID)
+	if errors.Is(err, identity.ErrSellerNotFound) {
+		if err := s.repo.Delete(ctx, seller.ID); err != nil {
+			log.Printf("SellersService: ошибка удаления продавца %s: %v", seller.ID, err)
+			return models.Seller{}, status.Error(codes.Internal, "unable to get seller")
+		}
+		log.Printf("SellersService: аккаунта продавца %s нет в auth_service, карточка удалена", seller.ID)
+		return models.Seller{}, status.Error(codes.NotFound, "seller not found")
+	}
+	if err != nil {
+		log.Printf("SellersService: не удалось сверить продавца %s с auth_service: %v", seller.ID, err)
+		return seller, nil
+	}
+
+	if err := s.repo.MarkSynced(ctx, seller.ID); err != nil {
+		log.Printf("SellersService: ошибка сохранения сверки продавца %s: %v", seller.ID, err)
+	}
+
+	return seller, nil
+}
+
+// callerSeller возвращает продавца из access токена вызова. id из запроса необязателен,
+// но если указан, должен совпадать с продавцом из токена.
+func (s *server) callerSeller(ctx context.Context, requestId string) (string, error) {
+	sellerId, err := s.sellers.SellerID(ctx)
+	switch {
+	case errors.Is(err, sellerauth.ErrNotSeller):
+		return "", status.Error(codes.PermissionDenied, "only sellers can change seller cards")
+	case err != nil:
+		return "", status.Error(codes.Unauthenticated, "seller access token is required")
+	}
+
+	if requestId != "" {
+		if parsed, err := uuid.Parse(requestId); err != nil || parsed.String() != sellerId {
+			return "", status.Error(codes.PermissionDenied, "seller can change only own card")
+		}
+	}
+
+	return sellerId, nil
+}
+
+func identityError(id string, err error) error {
+	if errors.Is(err, identity.ErrSellerNotFound) {
+		return status.Error(codes.NotFound, "seller not found")
+	}
+
+	log.Printf("SellersService: ошибка запроса продавца %s в auth_service: %v", id, err)
+	return status.Error(codes.Unavailable, "unable to get seller from auth_service")
+}
+
+func validate(seller models.Seller) error {
+	if _, err := uuid.Parse(seller.ID); err != nil {
+		return status.Error(codes.InvalidArgument, "invalid seller id")
+	}
+	if seller.FullName == "" {
+		return status.Error(codes.InvalidArgument, "fullname is required")
+	}
+	if seller.Email != "" && !strings.Contains(seller.Email, "@") {
+		return status.Error(codes.InvalidArgument, "invalid email")
+	}
+
+	for _, field := range []string{seller.Phone, seller.Email, seller.FullName, seller.City} {
+		if utf8.RuneCountInString(field) > maxFieldLen {
+			return status.Error(codes.InvalidArgument, "field is too long, max 255 characters")
+		}
+	}
+
+	return nil
+}
+
+func toProto(seller models.Seller) *pb.Seller {
+	return &pb.Seller{
+		Id:        seller.ID,
+		Phone:     seller.Phone,
+		Email:     seller.Email,
+		Fullname:  seller.FullName,
+		City:      seller.City,
+		CreatedAt: timestamppb.New(seller.CreatedAt),
+		UpdatedAt: timestamppb.New(seller.UpdatedAt),
+	}
+}
+
+// callersPolicy - карточки продавцов читают content_service и products_service. Менять их может
+// только шлюз delivery_service от имени продавца, продавца определяет его access токен (см. sellerauth).
+var callersPolicy = grpcsec.Policy{
+	"/" + pb.SellersService_ServiceDesc.ServiceName + "/*": {"content_service", "products_service"},
+	pb.SellersService_CreateSeller_FullMethodName:          {"delivery_service"},
+	pb.SellersService_UpdateSeller_FullMethodName:          {"delivery_service"},
 }
 
 // Run запускает gRPC-сервер Sellers на указанном адресе (например, ":50052").
-func RunSellersServer(listenAddr string) error {
+func RunSellersServer(listenAddr string, srv *server) error {
 	lis, err := net.Listen("tcp", listenAddr)
 	if err != nil {
 		return err
 	}
-	grpcServer := grpc.NewServer()
-	pb.RegisterSellersServiceServer(grpcServer, NewServer())
+	opts, err := grpcsec.ServerOptions(context.Background(), grpcsec.ConfigFromEnv(), callersPolicy)
+	if err != nil {
+		return err
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterSellersServiceServer(grpcServer, srv)
 
 	log.Printf("SellersService gRPC запущен на %s", listenAddr)
 	return grpcServer.Serve(lis)
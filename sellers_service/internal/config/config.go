@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// Config собирается из переменных окружения, как и у остальных сервисов без yaml конфига
+type Config struct {
+	GRPCAddr string
+	// AuthAddr - адрес auth_service, из него подтягиваются продавцы, которых еще нет в базе
+	AuthAddr string
+	// SyncTTL - как долго карточка продавца отдается без повторной сверки аккаунта с auth_service
+	SyncTTL time.Duration
+	// AccessTokenSecret - ключ access токенов auth_service, по нему проверяется продавец,
+	// от имени которого шлюз меняет карточку
+	AccessTokenSecret string
+	DB                DBConfig
+}
+
+type DBConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+}
+
+func New() Config {
+	return Config{
+		GRPCAddr:          getenv("GRPC_ADDR", ":50052"),
+		AuthAddr:          getenv("GRPC_AUTH_ADDRESS", "auth_service:50052"),
+		SyncTTL:           getduration("SELLER_SYNC_TTL", time.Hour),
+		AccessTokenSecret: os.Getenv("ACCESS_TOKEN_SECRET"),
+		DB: DBConfig{
+			Host:     getenv("DB_HOST", "sellers_db"),
+			Port:     getenv("DB_PORT", "5432"),
+			User:     os.Getenv("DB_USER"),
+			Password: os.Getenv("DB_PASSWORD"),
+			Name:     getenv("DB_NAME", "sellers"),
+		},
+	}
+}
+
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+		c.Host, c.User, c.Password, c.Name, c.Port)
+}
+
+func getenv(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return def
+}
+
+func getduration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("config: некорректное значение %s=%q, используется %s", key, value, def)
+		return def
+	}
+
+	return d
+}
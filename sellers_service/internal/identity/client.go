@@ -0,0 +1,73 @@
+// Package identity читает аккаунты продавцов из auth_service. Из него sellers_service
+// подтягивает карточки продавцов, зарегистрированных до появления собственной базы.
+package identity
+
+import (
+	"context"
+	"errors"
+	"grpcsec"
+	"sellers_service/internal/models"
+	"time"
+
+	pb "github.com/artemSorokin1/sellers-grpc-api/gen/go/seller"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const requestTimeout = 5 * time.Second
+
+var ErrSellerNotFound = errors.New("seller not found in auth_service")
+
+type Client struct {
+	conn   *grpc.ClientConn
+	client pb.SellersServiceClient
+}
+
+func New(addr string) (*Client, error) {
+	transport, err := grpcsec.DialOption(context.Background(), grpcsec.ConfigFromEnv(), "auth_service")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(addr, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{conn: conn, client: pb.NewSellersServiceClient(conn)}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// GetSeller возвращает продавца с каноническим uuid: по старому id сервиса auth придет
+// карточка с текущим id аккаунта
+func (c *Client) GetSeller(ctx context.Context, id string) (models.Seller, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	resp, err := c.client.GetSeller(ctx, &pb.GetSellerRequest{Id: id})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return models.Seller{}, ErrSellerNotFound
+		}
+		return models.Seller{}, err
+	}
+
+	seller := resp.GetSeller()
+	createdAt := time.Now()
+	if seller.GetCreatedAt() != nil {
+		createdAt = seller.GetCreatedAt().AsTime()
+	}
+
+	return models.Seller{
+		ID:        seller.GetId(),
+		Phone:     seller.GetPhone(),
+		Email:     seller.GetEmail(),
+		FullName:  seller.GetFullname(),
+		City:      seller.GetCity(),
+		CreatedAt: createdAt,
+	}, nil
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Seller - карточка продавца. ID совпадает с uuid аккаунта продавца в auth_service.
+type Seller struct {
+	ID        string    `db:"id"`
+	Phone     string    `db:"phone"`
+	Email     string    `db:"email"`
+	FullName  string    `db:"fullname"`
+	City      string    `db:"city"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	// SyncedAt - когда аккаунт продавца последний раз сверялся с auth_service
+	SyncedAt time.Time `db:"synced_at"`
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sellers_service/internal/config"
+	"sellers_service/internal/models"
+
+	"github.com/golang-migrate/migrate"
+	"github.com/golang-migrate/migrate/database/postgres"
+	_ "github.com/golang-migrate/migrate/source/file"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const uniqueViolation = "23505"
+
+var (
+	ErrSellerNotFound = errors.New("seller not found")
+	ErrSellerExists   = errors.New("seller already exists")
+)
+
+type Repository struct {
+	db *sqlx.DB
+}
+
+// New подключается к базе и применяет миграции из каталога migrations
+func New(cfg config.DBConfig) (*Repository, error) {
+	db, err := sqlx.Connect("postgres", cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to db: %w", err)
+	}
+
+	if _, err := db.Conn(context.Background()); err != nil {
+		return nil, fmt.Errorf("unable to connect to db: %w", err)
+	}
+
+	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("error creating postgres driver: %w", err)
+	}
+
+	m, err := migrate.NewWithDatabaseInstance("file://migrations", "postgres", driver)
+	if err != nil {
+		return nil, fmt.Errorf("error creating migration instance: %w", err)
+	}
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return nil, fmt.Errorf("error running migrations: %w", err)
+	}
+
+	return &Repository{db: db}, nil
+}
+
+func (r *Repository) Close() error {
+	return r.db.Close()
+}
+
+func (r *Repository) Create(ctx context.Context, seller models.Seller) (models.Seller, error) {
+	var created models.Seller
+	err := r.db.GetContext(ctx, &created, `
+    INSERT INTO sellers (id, phone, email, fullname, city)
+    VALUES ($1, $2, $3, $4, $5)
+    RETURNING *`,
+		seller.ID, seller.Phone, seller.Email, seller.FullName, seller.City,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return models.Seller{}, ErrSellerExists
+		}
+		return models.Seller{}, err
+	}
+
+	return created, nil
+}
+
+// Import сохраняет карточку, полученную из auth_service. Если карточка уже есть, она не меняется:
+// после переноса источником контактов продавца служит этот сервис, а auth_service - источником
+// того, что аккаунт продавца существует (см. MarkSynced и Delete).
+func (r *Repository) Import(ctx context.Context, seller models.Seller) (models.Seller, error) {
+	_, err := r.db.ExecContext(ctx, `
+    INSERT INTO sellers (id, phone, email, fullname, city, created_at)
+    VALUES ($1, $2, $3, $4, $5, $6)
+    ON CONFLICT (id) DO NOTHING`,
+		seller.ID, seller.Phone, seller.Email, seller.FullName, seller.City, seller.CreatedAt,
+	)
+	if err != nil {
+		return models.Seller{}, err
+	}
+
+	return r.Get(ctx, seller.ID)
+}
+
+func (r *Repository) Get(ctx context.Context, id string) (models.Seller, error) {
+	var seller models.Seller
+	err := r.db.GetContext(ctx, &seller, "SELECT * FROM sellers WHERE id = $1", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Seller{}, ErrSellerNotFound
+		}
+		return models.Seller{}, err
+	}
+
+	return seller, nil
+}
+
+func (r *Repository) Update(ctx context.Context, seller models.Seller) (models.Seller, error) {
+	var updated models.Seller
+	err := r.db.GetContext(ctx, &updated, `
+    UPDATE sellers SET
+        phone = $2,
+        email = $3,
+        fullname = $4,
+        city = $5,
+        updated_at = CURRENT_TIMESTAMP
+    WHERE id = $1
+    RETURNING *`,
+		seller.ID, seller.Phone, seller.Email, seller.FullName, seller.City,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Seller{}, ErrSellerNotFound
+		}
+		return models.Seller{}, err
+	}
+
+	return updated, nil
+}
+
+// MarkSynced отмечает, что аккаунт продавца только что подтвердил auth_service
+func (r *Repository) MarkSynced(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE sellers SET synced_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+	return err
+}
+
+// Delete удаляет карточку продавца, аккаунта которого больше нет в auth_service
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM sellers WHERE id = $1", id)
+	return err
+}
+
+// List - страница продавцов, сначала новые, и общее число продавцов. Пустой city - любой город.
+func (r *Repository) List(ctx context.Context, city string, limit, offset int) ([]models.Seller, int64, error) {
+	var total int64
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM sellers WHERE $1 = '' OR city = $1", city)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sellers := []models.Seller{}
+	err = r.db.SelectContext(ctx, &sellers, `
+    SELECT * FROM sellers
+    WHERE $1 = '' OR city = $1
+    ORDER BY created_at DESC, id
+    LIMIT $2 OFFSET $3`,
+		city, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return sellers, total, nil
+}
+
+// BatchGet возвращает найденных продавцов в произвольном порядке
+func (r *Repository) BatchGet(ctx context.Context, ids []string) ([]models.Seller, error) {
+	sellers := []models.Seller{}
+	err := r.db.SelectContext(ctx, &sellers, "SELECT * FROM sellers WHERE id = ANY($1::uuid[])", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	return sellers, nil
+}
@@ -0,0 +1,13 @@
+-- карточки продавцов: контакты, которые видят покупатели. id - uuid аккаунта продавца в auth_service
+CREATE TABLE IF NOT EXISTS sellers (
+    id UUID PRIMARY KEY,
+    phone TEXT DEFAULT '' NOT NULL,
+    email TEXT DEFAULT '' NOT NULL,
+    fullname TEXT DEFAULT '' NOT NULL,
+    city TEXT DEFAULT '' NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sellers_created_at_idx ON sellers (created_at DESC, id);
+CREATE INDEX IF NOT EXISTS sellers_city_idx ON sellers (city, created_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE sellers DROP COLUMN IF EXISTS synced_at;
@@ -0,0 +1,2 @@
+-- synced_at - когда аккаунт продавца последний раз сверялся с auth_service
+ALTER TABLE sellers ADD COLUMN IF NOT EXISTS synced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL;